@@ -2,43 +2,40 @@ package actions
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/danielkrainas/gobag/util/slugify"
 
 	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/blobs"
+	"github.com/danielkrainas/tinkersnest/blobs/driver"
 	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/diff"
 	"github.com/danielkrainas/tinkersnest/queries"
 	"github.com/danielkrainas/tinkersnest/storage"
 )
 
-func FindClaim(ctx context.Context, q *queries.FindClaim, claims storage.ClaimStore) (*v1.Claim, error) {
-	return claims.Find(q.Code)
-}
+func DeleteUser(ctx context.Context, c *commands.DeleteUser, users storage.UserStore, sessions storage.SessionStore, apiKeys storage.APIKeyStore) error {
+	if err := users.Delete(c.Name); err != nil {
+		return err
+	}
 
-func RedeemClaim(ctx context.Context, c *commands.RedeemClaim, claims storage.ClaimStore) error {
-	claim, err := claims.Find(c.Code)
+	keys, err := apiKeys.FindMany(&storage.APIKeyFilters{User: c.Name})
 	if err != nil {
 		return err
 	}
 
-	claim.Redeemed = time.Now().Unix()
-	return claims.Store(claim, false)
-}
-
-func CreateClaim(ctx context.Context, c *commands.CreateClaim, claims storage.ClaimStore) error {
-	claim := &v1.Claim{
-		Code:         c.Code,
-		ResourceType: c.ResourceType,
-		Created:      time.Now().Unix(),
-		Redeemed:     0,
+	for _, k := range keys {
+		if err := apiKeys.Delete(k.ID); err != nil && err != storage.ErrNotFound {
+			return err
+		}
 	}
 
-	return claims.Store(claim, true)
-}
-
-func DeleteUser(ctx context.Context, c *commands.DeleteUser, users storage.UserStore) error {
-	return users.Delete(c.Name)
+	return DeleteUserSessions(ctx, &commands.DeleteUserSessions{User: c.Name}, sessions)
 }
 
 func StoreUser(ctx context.Context, c *commands.StoreUser, users storage.UserStore) error {
@@ -47,7 +44,28 @@ func StoreUser(ctx context.Context, c *commands.StoreUser, users storage.UserSto
 		u.Name = slugify.Marshal(u.FullName)
 	}
 
-	return users.Store(u, c.New)
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	if c.New {
+		if u.Name == "" {
+			return v1.InvalidField(v1.ErrorCodeFieldRequired, "name", "name or full_name is required")
+		} else if err := v1.ValidateName(u.Name); err != nil {
+			return err
+		} else if u.Password == "" {
+			return v1.InvalidField(v1.ErrorCodeFieldRequired, "password", "password is required")
+		}
+	}
+
+	// only the hash of the password is kept
+	u.Password = ""
+	err := users.Store(u, c.New)
+	if err == storage.ErrConflict && c.New {
+		return v1.InvalidField(v1.ErrorCodeNameTaken, "name", "user %q already exists", u.Name)
+	}
+
+	return err
 }
 
 func FindUser(ctx context.Context, q *queries.FindUser, users storage.UserStore) (*v1.User, error) {
@@ -62,7 +80,67 @@ func SearchUsers(ctx context.Context, q *queries.SearchUsers, users storage.User
 	return users.FindMany(&storage.UserFilters{})
 }
 
-func StorePost(ctx context.Context, c *commands.StorePost, posts storage.PostStore) error {
+func StoreSession(ctx context.Context, c *commands.StoreSession, sessions storage.SessionStore) error {
+	return sessions.Store(c.Session, c.New)
+}
+
+func DeleteSession(ctx context.Context, c *commands.DeleteSession, sessions storage.SessionStore) error {
+	return sessions.Delete(c.ID)
+}
+
+func DeleteUserSessions(ctx context.Context, c *commands.DeleteUserSessions, sessions storage.SessionStore) error {
+	found, err := sessions.FindMany(&storage.SessionFilters{User: c.User})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range found {
+		if err := sessions.Delete(s.ID); err != nil && err != storage.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func FindSession(ctx context.Context, q *queries.FindSession, sessions storage.SessionStore) (*v1.Session, error) {
+	return sessions.Find(q.ID)
+}
+
+// SearchSessions returns the user's sessions that haven't expired yet.
+func SearchSessions(ctx context.Context, q *queries.SearchSessions, sessions storage.SessionStore) ([]*v1.Session, error) {
+	found, err := sessions.FindMany(&storage.SessionFilters{User: q.User})
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]*v1.Session, 0, len(found))
+	for _, s := range found {
+		if !s.Expired() {
+			active = append(active, s)
+		}
+	}
+
+	return active, nil
+}
+
+func StoreAPIKey(ctx context.Context, c *commands.StoreAPIKey, apiKeys storage.APIKeyStore) error {
+	return apiKeys.Store(c.Key, c.New)
+}
+
+func DeleteAPIKey(ctx context.Context, c *commands.DeleteAPIKey, apiKeys storage.APIKeyStore) error {
+	return apiKeys.Delete(c.ID)
+}
+
+func FindAPIKey(ctx context.Context, q *queries.FindAPIKey, apiKeys storage.APIKeyStore) (*v1.APIKey, error) {
+	return apiKeys.Find(q.ID)
+}
+
+func SearchAPIKeys(ctx context.Context, q *queries.SearchAPIKeys, apiKeys storage.APIKeyStore) ([]*v1.APIKey, error) {
+	return apiKeys.FindMany(&storage.APIKeyFilters{User: q.User})
+}
+
+func StorePost(ctx context.Context, c *commands.StorePost, posts storage.PostStore, revisions storage.RevisionStore, blobStore driver.Driver) error {
 	p := c.Post
 	if c.New {
 		p.Created = time.Now().Unix()
@@ -72,17 +150,381 @@ func StorePost(ctx context.Context, c *commands.StorePost, posts storage.PostSto
 		p.Name = slugify.Marshal(p.Title)
 	}
 
-	return posts.Store(p, c.New)
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if c.New {
+		if err := v1.ValidateName(p.Name); err != nil {
+			return err
+		}
+	}
+
+	if err := checkBlobs(p.Content, blobStore); err != nil {
+		return err
+	}
+
+	storage.TransitionPost(p, p.ScheduledState(time.Now().Unix()))
+	wasPublished := false
+	if !c.New {
+		// the stored post may be the same one that was changed, so its last
+		// revision is the only reliable record of how it was before
+		if prev, err := revisions.Latest(p.Name); err == nil {
+			wasPublished = prev.Post.Publish
+		} else if err != storage.ErrNotFound {
+			return err
+		}
+	}
+
+	if err := posts.Store(p, c.New); err == storage.ErrConflict && c.New {
+		return v1.InvalidField(v1.ErrorCodeNameTaken, "name", "post %q already exists", p.Name)
+	} else if err != nil {
+		return err
+	}
+
+	c.Published = p.Publish && !wasPublished
+	return revisions.Append(&v1.Revision{
+		User:         c.User,
+		Created:      time.Now().Unix(),
+		RestoredFrom: c.RestoredFrom,
+		Post:         snapshotPost(p),
+	})
 }
 
-func DeletePost(ctx context.Context, c *commands.DeletePost, posts storage.PostStore) error {
-	return posts.Delete(c.Name)
+// checkBlobs makes sure every blob the content refers to has been uploaded.
+func checkBlobs(content []*v1.Content, blobStore driver.Driver) error {
+	for i, c := range content {
+		if c.Blob == "" {
+			continue
+		}
+
+		if _, err := blobStore.Inspect(c.Blob); err != nil {
+			if err == blobs.ErrUnknown {
+				return v1.InvalidField(v1.ErrorCodeFieldInvalid, fmt.Sprintf("content[%d].blob", i), "unknown blob %q", c.Blob)
+			}
+
+			return err
+		}
+	}
+
+	return nil
 }
 
-func SearchPosts(ctx context.Context, q *queries.SearchPosts, posts storage.PostStore) ([]*v1.Post, error) {
-	return posts.FindMany(&storage.PostFilters{})
+// snapshotPost copies a post deep enough that later changes to it can't
+// alter the revision.
+func snapshotPost(p *v1.Post) *v1.Post {
+	cp := *p
+	if p.Author != nil {
+		author := *p.Author
+		cp.Author = &author
+	}
+
+	if p.Tags != nil {
+		cp.Tags = append([]string{}, p.Tags...)
+	}
+
+	if p.Categories != nil {
+		cp.Categories = append([]string{}, p.Categories...)
+	}
+
+	if p.Content != nil {
+		cp.Content = make([]*v1.Content, len(p.Content))
+		for i, content := range p.Content {
+			c := *content
+			c.Data = append([]byte(nil), content.Data...)
+			cp.Content[i] = &c
+		}
+	}
+
+	return &cp
+}
+
+func DeletePost(ctx context.Context, c *commands.DeletePost, posts storage.PostStore, revisions storage.RevisionStore, comments storage.CommentStore) error {
+	if err := posts.Delete(c.Name); err != nil {
+		return err
+	}
+
+	if err := revisions.DeleteAll(c.Name); err != nil {
+		return err
+	}
+
+	return comments.DeleteAll(c.Name)
+}
+
+// TransitionPost records the scheduler's state change as a revision without a
+// user. Only the instance whose transition went through writes one.
+func TransitionPost(ctx context.Context, c *commands.TransitionPost, posts storage.PostStore, revisions storage.RevisionStore) error {
+	if err := posts.Transition(c.Name, c.Version, c.To); err != nil {
+		return err
+	}
+
+	p, err := posts.Find(c.Name)
+	if err != nil {
+		return err
+	} else if p == nil {
+		return storage.ErrNotFound
+	}
+
+	return revisions.Append(&v1.Revision{
+		Created: time.Now().Unix(),
+		Post:    snapshotPost(p),
+	})
+}
+
+func SearchRevisions(ctx context.Context, q *queries.SearchRevisions, revisions storage.RevisionStore) ([]*v1.Revision, error) {
+	return revisions.FindMany(q.Post)
+}
+
+// FindRevision returns a copy of the revision that is safe to modify.
+func FindRevision(ctx context.Context, q *queries.FindRevision, revisions storage.RevisionStore) (*v1.Revision, error) {
+	var r *v1.Revision
+	var err error
+	if q.Number == 0 {
+		r, err = revisions.Latest(q.Post)
+	} else {
+		r, err = revisions.Find(q.Post, q.Number)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	cp := *r
+	cp.Post = snapshotPost(r.Post)
+	return &cp, nil
+}
+
+func DiffRevisions(ctx context.Context, q *queries.DiffRevisions, revisions storage.RevisionStore) (*v1.RevisionDiff, error) {
+	all, err := revisions.FindMany(q.Post)
+	if err != nil {
+		return nil, err
+	}
+
+	to := q.To
+	if to == 0 {
+		to = len(all)
+	}
+
+	from := q.From
+	if from == 0 {
+		from = to - 1
+	}
+
+	find := func(number int) (*v1.Revision, error) {
+		for _, r := range all {
+			if r.Number == number {
+				return r, nil
+			}
+		}
+
+		return nil, storage.ErrNotFound
+	}
+
+	toRev, err := find(to)
+	if err != nil {
+		return nil, err
+	}
+
+	// revision 0 is the empty post, so diffing the first revision shows all of it
+	var fromLines []string
+	if from != 0 {
+		fromRev, err := find(from)
+		if err != nil {
+			return nil, err
+		}
+
+		fromLines = revisionLines(fromRev)
+	}
+
+	return &v1.RevisionDiff{
+		Post: q.Post,
+		From: from,
+		To:   to,
+		Diff: diff.Unified(fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to), fromLines, revisionLines(toRev), 3),
+	}, nil
+}
+
+// revisionLines renders a revision as text for diffing. Metadata comes first,
+// then each content block under a header naming its type.
+func revisionLines(r *v1.Revision) []string {
+	p := r.Post
+	lines := []string{
+		"title: " + p.Title,
+		"publish: " + strconv.FormatBool(p.Publish),
+		"tags: " + strings.Join(p.Tags, ", "),
+	}
+
+	if p.Author != nil {
+		author := p.Author.User
+		if p.Author.Name != "" {
+			author = fmt.Sprintf("%s (%s)", p.Author.Name, p.Author.User)
+		}
+
+		lines = append(lines, "author: "+author)
+	}
+
+	for i, c := range p.Content {
+		lines = append(lines, "")
+		if c.Blob != "" {
+			lines = append(lines, fmt.Sprintf("[content %d: %s, blob %s]", i+1, c.Type, c.Blob))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("[content %d: %s]", i+1, c.Type))
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(c.Data), "\n"), "\n")...)
+	}
+
+	return lines
+}
+
+func SearchPosts(ctx context.Context, q *queries.SearchPosts, posts storage.PostStore, categories storage.CategoryStore) (*storage.PostPage, error) {
+	f := &storage.PostFilters{
+		Tags:          q.Tags,
+		Published:     q.Published,
+		CreatedAfter:  q.CreatedAfter,
+		CreatedBefore: q.CreatedBefore,
+		Sort:          storage.PostSort(q.Sort),
+		Text:          strings.TrimSpace(q.Text),
+	}
+
+	if q.Author != nil {
+		f.Author = q.Author.User
+	}
+
+	if q.Category != "" {
+		all, err := categories.FindAll()
+		if err != nil {
+			return nil, err
+		}
+
+		f.Categories = storage.Subcategories(all, q.Category)
+	}
+
+	if f.Sort != "" && !storage.ValidPostSort(f.Sort) {
+		return nil, fmt.Errorf("unsupported sort order %q", q.Sort)
+	} else if f.Sort != "" && f.Text != "" {
+		return nil, fmt.Errorf("search results are ordered by relevance and can't be sorted")
+	}
+
+	if q.Cursor != "" {
+		c, err := storage.DecodePostCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		f.Cursor = c
+	}
+
+	// fetch one extra post to find out if there's another page
+	if q.Limit > 0 {
+		f.Limit = q.Limit + 1
+	}
+
+	results, err := posts.FindMany(f)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &storage.PostPage{Posts: results}
+	if q.Limit > 0 && len(results) > q.Limit {
+		page.Posts = results[:q.Limit]
+		last := page.Posts[q.Limit-1]
+		if f.Text != "" {
+			offset := q.Limit
+			if f.Cursor != nil {
+				offset += f.Cursor.Offset
+			}
+
+			page.Next = storage.EncodeSearchCursor(last, offset)
+		} else {
+			page.Next = storage.EncodePostCursor(last)
+		}
+	}
+
+	return page, nil
 }
 
 func FindPost(ctx context.Context, q *queries.FindPost, posts storage.PostStore) (*v1.Post, error) {
 	return posts.Find(q.Name)
 }
+
+func FindDuePosts(ctx context.Context, q *queries.FindDuePosts, posts storage.PostStore) ([]*v1.Post, error) {
+	return posts.FindDue(q.Now)
+}
+
+func StoreBlob(ctx context.Context, c *commands.StoreBlob, blobStore driver.Driver) error {
+	_, err := blobStore.Inspect(c.Blob.Name)
+	if err != nil && err != blobs.ErrUnknown {
+		return err
+	}
+
+	replaced := err == nil
+	w, err := blobStore.Writer(c.Blob.Name)
+	if err != nil {
+		return err
+	}
+
+	size, err := io.Copy(w, c.Data)
+	if err != nil {
+		w.Cancel()
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if c.Blob.Meta == nil {
+		c.Blob.Meta = make(map[string]string)
+	}
+
+	c.Blob.Meta[blobs.MetaSize] = strconv.FormatInt(size, 10)
+	c.Blob.Meta[blobs.MetaCreated] = strconv.FormatInt(time.Now().Unix(), 10)
+	if err := blobStore.WriteMeta(c.Blob.Name, c.Blob); err != nil {
+		return err
+	}
+
+	c.Replaced = replaced
+	return nil
+}
+
+func DeleteBlob(ctx context.Context, c *commands.DeleteBlob, blobStore driver.Driver) error {
+	found, err := blobStore.Drop(c.Name)
+	if err != nil {
+		return err
+	} else if !found {
+		return blobs.ErrUnknown
+	}
+
+	return nil
+}
+
+func FindBlob(ctx context.Context, q *queries.FindBlob, blobStore driver.Driver) (*blobs.Blob, error) {
+	return blobStore.Inspect(q.Name)
+}
+
+func OpenBlob(ctx context.Context, q *queries.OpenBlob, blobStore driver.Driver) (io.ReadCloser, error) {
+	return blobStore.Reader(q.Name)
+}
+
+func SearchBlobs(ctx context.Context, q *queries.SearchBlobs, blobStore driver.Driver) ([]*blobs.Blob, error) {
+	names, err := blobStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*blobs.Blob, 0, len(names))
+	for _, name := range names {
+		b, err := blobStore.Inspect(name)
+		if err == blobs.ErrUnknown {
+			// dropped since it was listed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		result = append(result, b)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielkrainas/gobag/util/token"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+// claimLifetime is how long claims that are issued without an expiry can be
+// redeemed for.
+const claimLifetime = 7 * 24 * time.Hour
+
+func FindClaim(ctx context.Context, q *queries.FindClaim, claims storage.ClaimStore) (*v1.Claim, error) {
+	claim, err := claims.Find(q.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	claim.State = claim.CurrentState()
+	return claim, nil
+}
+
+func SearchClaims(ctx context.Context, q *queries.SearchClaims, claims storage.ClaimStore) ([]*v1.Claim, error) {
+	all, err := claims.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*v1.Claim, 0, len(all))
+	for _, c := range all {
+		c.State = c.CurrentState()
+		if q.State != "" && c.State != q.State {
+			continue
+		} else if q.ResourceType != v1.NoResource && c.ResourceType != q.ResourceType {
+			continue
+		}
+
+		result = append(result, c)
+	}
+
+	return result, nil
+}
+
+func RedeemClaim(ctx context.Context, c *commands.RedeemClaim, claims storage.ClaimStore) error {
+	err := claims.Redeem(c.Code, time.Now().Unix())
+	if err != storage.ErrNotFound {
+		return err
+	}
+
+	// find out why it couldn't be redeemed
+	claim, err := claims.Find(c.Code)
+	if err == storage.ErrNotFound {
+		return v1.InvalidField(v1.ErrorCodeClaimInvalid, "code", "no such claim")
+	} else if err != nil {
+		return err
+	}
+
+	return v1.InvalidField(v1.ErrorCodeClaimInvalid, "code", "claim is %s", claim.CurrentState())
+}
+
+func ReleaseClaim(ctx context.Context, c *commands.ReleaseClaim, claims storage.ClaimStore) error {
+	return claims.Release(c.Code)
+}
+
+func CreateClaim(ctx context.Context, c *commands.CreateClaim, claims storage.ClaimStore) error {
+	claim := c.Claim
+	now := time.Now()
+	claim.Code = token.Generate(string(claim.ResourceType))
+	claim.Created = now.Unix()
+	claim.Uses = 0
+	claim.Redeemed = 0
+	claim.Revoked = 0
+	if claim.MaxUses == 0 {
+		claim.MaxUses = 1
+	}
+
+	if err := claim.Validate(); err != nil {
+		return err
+	}
+
+	if claim.Expires == 0 {
+		claim.Expires = now.Add(claimLifetime).Unix()
+	} else if claim.Expires <= now.Unix() {
+		return v1.InvalidField(v1.ErrorCodeFieldInvalid, "expires", "expires must be in the future")
+	}
+
+	if err := claims.Store(claim, true); err != nil {
+		return err
+	}
+
+	claim.State = claim.CurrentState()
+	return nil
+}
+
+func RevokeClaim(ctx context.Context, c *commands.RevokeClaim, claims storage.ClaimStore) error {
+	claim, err := claims.Find(c.Code)
+	if err != nil {
+		return err
+	} else if claim.Revoked != 0 {
+		return nil
+	}
+
+	claim.Revoked = time.Now().Unix()
+	return claims.Store(claim, false)
+}
+
+func PurgeClaims(ctx context.Context, c *commands.PurgeClaims, claims storage.ClaimStore) error {
+	all, err := claims.FindAll()
+	if err != nil {
+		return err
+	}
+
+	c.Purged = make([]*v1.Claim, 0)
+	for _, claim := range all {
+		claim.State = claim.CurrentState()
+		if claim.State == v1.ClaimActive || !hasClaimState(c.States, claim.State) {
+			continue
+		}
+
+		if err := claims.Delete(claim.Code); err != nil && err != storage.ErrNotFound {
+			return err
+		}
+
+		c.Purged = append(c.Purged, claim)
+	}
+
+	return nil
+}
+
+func hasClaimState(states []v1.ClaimState, state v1.ClaimState) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
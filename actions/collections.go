@@ -0,0 +1,150 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/danielkrainas/gobag/util/slugify"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/blobs/driver"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+// configuredCollections validates the collections declared in the
+// configuration and adds the built-in pages collection unless one of them
+// replaces it.
+func configuredCollections(declared []*v1.Collection) ([]*v1.Collection, error) {
+	result := make([]*v1.Collection, 0, len(declared)+1)
+	seen := make(map[string]bool)
+	for _, c := range declared {
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("configuration: collection %q: %s", c.Name, v1.ErrorReason(err))
+		} else if seen[c.Name] {
+			return nil, fmt.Errorf("configuration: collection %q declared twice", c.Name)
+		}
+
+		cp := *c
+		cp.Configured = true
+		if cp.Fields == nil {
+			cp.Fields = []*v1.Field{}
+		}
+
+		seen[c.Name] = true
+		result = append(result, &cp)
+	}
+
+	if !seen[v1.PagesCollection.Name] {
+		result = append(result, v1.PagesCollection)
+	}
+
+	return result, nil
+}
+
+func SearchCollections(ctx context.Context, q *queries.SearchCollections, configured []*v1.Collection, collections storage.CollectionStore) ([]*v1.Collection, error) {
+	stored, err := collections.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := append([]*v1.Collection{}, configured...)
+	for _, c := range stored {
+		// the configuration wins over anything stored under the same name
+		if findConfigured(configured, c.Name) == nil {
+			result = append(result, c)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+func FindCollection(ctx context.Context, q *queries.FindCollection, configured []*v1.Collection, collections storage.CollectionStore) (*v1.Collection, error) {
+	if c := findConfigured(configured, q.Name); c != nil {
+		return c, nil
+	}
+
+	return collections.Find(q.Name)
+}
+
+func findConfigured(configured []*v1.Collection, name string) *v1.Collection {
+	for _, c := range configured {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func StoreCollection(ctx context.Context, c *commands.StoreCollection, collections storage.CollectionStore) error {
+	return collections.Store(c.Collection)
+}
+
+func DeleteCollection(ctx context.Context, c *commands.DeleteCollection, collections storage.CollectionStore, items storage.ItemStore) error {
+	if err := collections.Delete(c.Name); err != nil {
+		return err
+	}
+
+	return items.DeleteAll(c.Name)
+}
+
+func StoreItem(ctx context.Context, c *commands.StoreItem, items storage.ItemStore, blobStore driver.Driver) error {
+	i := c.Item
+	now := time.Now().Unix()
+	if c.New {
+		i.Created = now
+	} else {
+		i.Updated = now
+	}
+
+	if i.Name == "" {
+		i.Name = slugify.Marshal(i.Title)
+	}
+
+	if c.New {
+		if err := v1.ValidateName(i.Name); err != nil {
+			return err
+		}
+
+		if _, err := items.Find(i.Collection, i.Name); err == nil {
+			return storage.ErrConflict
+		} else if err != storage.ErrNotFound {
+			return err
+		}
+	}
+
+	for n, content := range i.Content {
+		if err := content.Validate(fmt.Sprintf("content[%d]", n)); err != nil {
+			return err
+		}
+	}
+
+	if err := checkBlobs(i.Content, blobStore); err != nil {
+		return err
+	}
+
+	return items.Store(i)
+}
+
+func DeleteItem(ctx context.Context, c *commands.DeleteItem, items storage.ItemStore) error {
+	return items.Delete(c.Collection, c.Name)
+}
+
+func FindItem(ctx context.Context, q *queries.FindItem, items storage.ItemStore) (*v1.Item, error) {
+	return items.Find(q.Collection, q.Name)
+}
+
+func SearchItems(ctx context.Context, q *queries.SearchItems, items storage.ItemStore) ([]*v1.Item, error) {
+	return items.FindMany(&storage.ItemFilters{
+		Collection: q.Collection,
+		Published:  q.Published,
+	})
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/satori/go.uuid"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+func StoreComment(ctx context.Context, c *commands.StoreComment, comments storage.CommentStore) error {
+	if c.New {
+		c.Comment.ID = uuid.NewV4().String()
+		c.Comment.Created = time.Now().Unix()
+	}
+
+	return comments.Store(c.Comment)
+}
+
+func DeleteComment(ctx context.Context, c *commands.DeleteComment, comments storage.CommentStore) error {
+	target, err := comments.Find(c.ID)
+	if err != nil {
+		return err
+	}
+
+	thread, err := comments.FindMany(&storage.CommentFilters{Post: target.Post})
+	if err != nil {
+		return err
+	}
+
+	return comments.Delete(append([]string{target.ID}, storage.Replies(thread, target.ID)...)...)
+}
+
+func ModerateComments(ctx context.Context, c *commands.ModerateComments, comments storage.CommentStore) error {
+	return comments.SetState(c.IDs, c.State)
+}
+
+func FindComment(ctx context.Context, q *queries.FindComment, comments storage.CommentStore) (*v1.Comment, error) {
+	return comments.Find(q.ID)
+}
+
+func SearchComments(ctx context.Context, q *queries.SearchComments, comments storage.CommentStore) ([]*v1.Comment, error) {
+	return comments.FindMany(&storage.CommentFilters{
+		Post:  q.Post,
+		State: q.State,
+	})
+}
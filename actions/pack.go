@@ -5,6 +5,9 @@ import (
 
 	"github.com/danielkrainas/gobag/decouple/cqrs"
 
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/blobs/driver"
+	"github.com/danielkrainas/tinkersnest/blobs/driver/loader"
 	"github.com/danielkrainas/tinkersnest/commands"
 	"github.com/danielkrainas/tinkersnest/configuration"
 	"github.com/danielkrainas/tinkersnest/queries"
@@ -19,22 +22,76 @@ type Pack interface {
 
 type pack struct {
 	store storage.Driver
+	blobs driver.Driver
+
+	// collections are the ones declared in the configuration.
+	collections []*v1.Collection
 }
 
 func (p *pack) Execute(ctx context.Context, q cqrs.Query) (interface{}, error) {
 	switch q := q.(type) {
 	case *queries.FindClaim:
 		return FindClaim(ctx, q, p.store.Claims())
+	case *queries.SearchClaims:
+		return SearchClaims(ctx, q, p.store.Claims())
 	case *queries.FindUser:
 		return FindUser(ctx, q, p.store.Users())
 	case *queries.CountUsers:
 		return CountUsers(ctx, q, p.store.Users())
 	case *queries.SearchUsers:
 		return SearchUsers(ctx, q, p.store.Users())
+	case *queries.FindSession:
+		return FindSession(ctx, q, p.store.Sessions())
+	case *queries.SearchSessions:
+		return SearchSessions(ctx, q, p.store.Sessions())
+	case *queries.FindAPIKey:
+		return FindAPIKey(ctx, q, p.store.APIKeys())
+	case *queries.SearchAPIKeys:
+		return SearchAPIKeys(ctx, q, p.store.APIKeys())
 	case *queries.SearchPosts:
-		return SearchPosts(ctx, q, p.store.Posts())
+		return SearchPosts(ctx, q, p.store.Posts(), p.store.Categories())
 	case *queries.FindPost:
 		return FindPost(ctx, q, p.store.Posts())
+	case *queries.FindDuePosts:
+		return FindDuePosts(ctx, q, p.store.Posts())
+	case *queries.SearchRevisions:
+		return SearchRevisions(ctx, q, p.store.Revisions())
+	case *queries.FindRevision:
+		return FindRevision(ctx, q, p.store.Revisions())
+	case *queries.DiffRevisions:
+		return DiffRevisions(ctx, q, p.store.Revisions())
+	case *queries.SearchTags:
+		return SearchTags(ctx, q, p.store.Tags(), p.store.Categories(), p.store.Posts())
+	case *queries.FindTag:
+		return FindTag(ctx, q, p.store.Tags(), p.store.Posts())
+	case *queries.SearchCategories:
+		return SearchCategories(ctx, q, p.store.Categories(), p.store.Posts())
+	case *queries.FindCategory:
+		return FindCategory(ctx, q, p.store.Categories(), p.store.Posts())
+	case *queries.FindComment:
+		return FindComment(ctx, q, p.store.Comments())
+	case *queries.SearchComments:
+		return SearchComments(ctx, q, p.store.Comments())
+	case *queries.SearchCollections:
+		return SearchCollections(ctx, q, p.collections, p.store.Collections())
+	case *queries.FindCollection:
+		return FindCollection(ctx, q, p.collections, p.store.Collections())
+	case *queries.SearchItems:
+		return SearchItems(ctx, q, p.store.Items())
+	case *queries.FindItem:
+		return FindItem(ctx, q, p.store.Items())
+	case *queries.SearchWebhooks:
+		return SearchWebhooks(ctx, q, p.store.Webhooks())
+	case *queries.FindWebhook:
+		return FindWebhook(ctx, q, p.store.Webhooks())
+	case *queries.SearchDeliveries:
+		return SearchDeliveries(ctx, q, p.store.Deliveries())
+	case *queries.FindBlob:
+		return FindBlob(ctx, q, p.blobs)
+	case *queries.OpenBlob:
+		return OpenBlob(ctx, q, p.blobs)
+	case *queries.SearchBlobs:
+		return SearchBlobs(ctx, q, p.blobs)
 	}
 
 	return nil, cqrs.ErrNoExecutor
@@ -44,16 +101,74 @@ func (p *pack) Handle(ctx context.Context, c cqrs.Command) error {
 	switch c := c.(type) {
 	case *commands.RedeemClaim:
 		return RedeemClaim(ctx, c, p.store.Claims())
+	case *commands.ReleaseClaim:
+		return ReleaseClaim(ctx, c, p.store.Claims())
 	case *commands.CreateClaim:
 		return CreateClaim(ctx, c, p.store.Claims())
+	case *commands.RevokeClaim:
+		return RevokeClaim(ctx, c, p.store.Claims())
+	case *commands.PurgeClaims:
+		return PurgeClaims(ctx, c, p.store.Claims())
 	case *commands.DeleteUser:
-		return DeleteUser(ctx, c, p.store.Users())
+		return DeleteUser(ctx, c, p.store.Users(), p.store.Sessions(), p.store.APIKeys())
 	case *commands.StoreUser:
 		return StoreUser(ctx, c, p.store.Users())
+	case *commands.StoreSession:
+		return StoreSession(ctx, c, p.store.Sessions())
+	case *commands.DeleteSession:
+		return DeleteSession(ctx, c, p.store.Sessions())
+	case *commands.DeleteUserSessions:
+		return DeleteUserSessions(ctx, c, p.store.Sessions())
+	case *commands.StoreAPIKey:
+		return StoreAPIKey(ctx, c, p.store.APIKeys())
+	case *commands.DeleteAPIKey:
+		return DeleteAPIKey(ctx, c, p.store.APIKeys())
 	case *commands.StorePost:
-		return StorePost(ctx, c, p.store.Posts())
+		return StorePost(ctx, c, p.store.Posts(), p.store.Revisions(), p.blobs)
 	case *commands.DeletePost:
-		return DeletePost(ctx, c, p.store.Posts())
+		return DeletePost(ctx, c, p.store.Posts(), p.store.Revisions(), p.store.Comments())
+	case *commands.TransitionPost:
+		return TransitionPost(ctx, c, p.store.Posts(), p.store.Revisions())
+	case *commands.StoreTag:
+		return StoreTag(ctx, c, p.store.Tags())
+	case *commands.DeleteTag:
+		return DeleteTag(ctx, c, p.store.Tags(), p.store.Posts(), p.store.Revisions())
+	case *commands.RenameTag:
+		return RenameTag(ctx, c, p.store.Tags(), p.store.Posts(), p.store.Revisions())
+	case *commands.MergeTags:
+		return MergeTags(ctx, c, p.store.Tags(), p.store.Posts(), p.store.Revisions())
+	case *commands.StoreCategory:
+		return StoreCategory(ctx, c, p.store.Categories())
+	case *commands.DeleteCategory:
+		return DeleteCategory(ctx, c, p.store.Categories(), p.store.Posts(), p.store.Revisions())
+	case *commands.RenameCategory:
+		return RenameCategory(ctx, c, p.store.Categories(), p.store.Posts(), p.store.Revisions())
+	case *commands.MergeCategories:
+		return MergeCategories(ctx, c, p.store.Categories(), p.store.Posts(), p.store.Revisions())
+	case *commands.StoreComment:
+		return StoreComment(ctx, c, p.store.Comments())
+	case *commands.DeleteComment:
+		return DeleteComment(ctx, c, p.store.Comments())
+	case *commands.ModerateComments:
+		return ModerateComments(ctx, c, p.store.Comments())
+	case *commands.StoreCollection:
+		return StoreCollection(ctx, c, p.store.Collections())
+	case *commands.DeleteCollection:
+		return DeleteCollection(ctx, c, p.store.Collections(), p.store.Items())
+	case *commands.StoreItem:
+		return StoreItem(ctx, c, p.store.Items(), p.blobs)
+	case *commands.DeleteItem:
+		return DeleteItem(ctx, c, p.store.Items())
+	case *commands.StoreWebhook:
+		return StoreWebhook(ctx, c, p.store.Webhooks())
+	case *commands.DeleteWebhook:
+		return DeleteWebhook(ctx, c, p.store.Webhooks(), p.store.Deliveries())
+	case *commands.AppendDelivery:
+		return AppendDelivery(ctx, c, p.store.Deliveries())
+	case *commands.StoreBlob:
+		return StoreBlob(ctx, c, p.blobs)
+	case *commands.DeleteBlob:
+		return DeleteBlob(ctx, c, p.blobs)
 	}
 
 	return cqrs.ErrNoHandler
@@ -65,8 +180,20 @@ func FromConfig(config *configuration.Config) (Pack, error) {
 		return nil, err
 	}
 
+	blobDriver, err := blobsloader.FromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := configuredCollections(config.Collections)
+	if err != nil {
+		return nil, err
+	}
+
 	p := &pack{
-		store: storageDriver,
+		store:       storageDriver,
+		blobs:       blobDriver,
+		collections: collections,
 	}
 
 	return p, nil
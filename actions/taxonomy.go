@@ -0,0 +1,282 @@
+package actions
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+// countTerms counts the posts of every tag and category. It reads every
+// post, which is fine for a blog but worth revisiting for bigger sites.
+func countTerms(posts storage.PostStore, categories storage.CategoryStore, published *bool) (map[string]int, map[string]int, error) {
+	all, err := posts.FindMany(&storage.PostFilters{Published: published})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cats, err := categories.FindAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tagCounts, catCounts := storage.CountTerms(all, cats)
+	return tagCounts, catCounts, nil
+}
+
+// rewritePosts applies fn to every post matching the filters and stores
+// them, recording the change as a new revision of each.
+func rewritePosts(f *storage.PostFilters, user string, posts storage.PostStore, revisions storage.RevisionStore, fn func(p *v1.Post)) error {
+	matched, err := posts.FindMany(f)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, p := range matched {
+		fn(p)
+		if err := posts.Store(p, false); err != nil {
+			return err
+		}
+
+		err := revisions.Append(&v1.Revision{
+			User:    user,
+			Created: now,
+			Post:    snapshotPost(p),
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func SearchTags(ctx context.Context, q *queries.SearchTags, tags storage.TagStore, categories storage.CategoryStore, posts storage.PostStore) ([]*v1.Tag, error) {
+	stored, err := tags.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, _, err := countTerms(posts, categories, q.Published)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*v1.Tag, 0, len(counts))
+	for _, t := range stored {
+		t.Count = counts[t.Slug]
+		delete(counts, t.Slug)
+		result = append(result, t)
+	}
+
+	for slug, n := range counts {
+		result = append(result, &v1.Tag{Slug: slug, Count: n})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Slug < result[j].Slug
+	})
+
+	return result, nil
+}
+
+// FindTag returns storage.ErrNotFound unless the tag is stored or carried by
+// at least one post.
+func FindTag(ctx context.Context, q *queries.FindTag, tags storage.TagStore, posts storage.PostStore) (*v1.Tag, error) {
+	t, err := tags.Find(q.Slug)
+	if err == storage.ErrNotFound {
+		t = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	tagged, err := posts.FindMany(&storage.PostFilters{Tags: []string{q.Slug}, Published: q.Published})
+	if err != nil {
+		return nil, err
+	}
+
+	if t == nil {
+		if len(tagged) == 0 {
+			return nil, storage.ErrNotFound
+		}
+
+		t = &v1.Tag{Slug: q.Slug}
+	}
+
+	t.Count = len(tagged)
+	return t, nil
+}
+
+func StoreTag(ctx context.Context, c *commands.StoreTag, tags storage.TagStore) error {
+	t := *c.Tag
+	t.Count = 0
+	return tags.Store(&t)
+}
+
+func DeleteTag(ctx context.Context, c *commands.DeleteTag, tags storage.TagStore, posts storage.PostStore, revisions storage.RevisionStore) error {
+	if err := tags.Delete(c.Slug); err != nil && err != storage.ErrNotFound {
+		return err
+	}
+
+	return retag(c.Slug, "", c.User, posts, revisions)
+}
+
+func RenameTag(ctx context.Context, c *commands.RenameTag, tags storage.TagStore, posts storage.PostStore, revisions storage.RevisionStore) error {
+	t, err := tags.Find(c.From)
+	if err == nil {
+		t.Slug = c.To
+		if err := tags.Store(t); err != nil {
+			return err
+		}
+
+		if err := tags.Delete(c.From); err != nil {
+			return err
+		}
+	} else if err != storage.ErrNotFound {
+		return err
+	}
+
+	return retag(c.From, c.To, c.User, posts, revisions)
+}
+
+func MergeTags(ctx context.Context, c *commands.MergeTags, tags storage.TagStore, posts storage.PostStore, revisions storage.RevisionStore) error {
+	if err := tags.Delete(c.From); err != nil && err != storage.ErrNotFound {
+		return err
+	}
+
+	return retag(c.From, c.Into, c.User, posts, revisions)
+}
+
+func retag(from string, to string, user string, posts storage.PostStore, revisions storage.RevisionStore) error {
+	return rewritePosts(&storage.PostFilters{Tags: []string{from}}, user, posts, revisions, func(p *v1.Post) {
+		p.Tags = storage.ReplaceTerm(p.Tags, from, to)
+	})
+}
+
+func SearchCategories(ctx context.Context, q *queries.SearchCategories, categories storage.CategoryStore, posts storage.PostStore) ([]*v1.Category, error) {
+	result, err := categories.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	_, counts, err := countTerms(posts, categories, q.Published)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range result {
+		c.Count = counts[c.Slug]
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Slug < result[j].Slug
+	})
+
+	return result, nil
+}
+
+func FindCategory(ctx context.Context, q *queries.FindCategory, categories storage.CategoryStore, posts storage.PostStore) (*v1.Category, error) {
+	c, err := categories.Find(q.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	_, counts, err := countTerms(posts, categories, q.Published)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Count = counts[c.Slug]
+	return c, nil
+}
+
+func StoreCategory(ctx context.Context, c *commands.StoreCategory, categories storage.CategoryStore) error {
+	category := *c.Category
+	category.Count = 0
+	return categories.Store(&category)
+}
+
+func DeleteCategory(ctx context.Context, c *commands.DeleteCategory, categories storage.CategoryStore, posts storage.PostStore, revisions storage.RevisionStore) error {
+	category, err := categories.Find(c.Slug)
+	if err != nil {
+		return err
+	}
+
+	if err := reparentCategories(categories, c.Slug, category.Parent); err != nil {
+		return err
+	}
+
+	if err := categories.Delete(c.Slug); err != nil {
+		return err
+	}
+
+	return recategorize(c.Slug, "", c.User, posts, revisions)
+}
+
+func RenameCategory(ctx context.Context, c *commands.RenameCategory, categories storage.CategoryStore, posts storage.PostStore, revisions storage.RevisionStore) error {
+	category, err := categories.Find(c.From)
+	if err != nil {
+		return err
+	}
+
+	category.Slug = c.To
+	if err := categories.Store(category); err != nil {
+		return err
+	}
+
+	if err := reparentCategories(categories, c.From, c.To); err != nil {
+		return err
+	}
+
+	if err := categories.Delete(c.From); err != nil {
+		return err
+	}
+
+	return recategorize(c.From, c.To, c.User, posts, revisions)
+}
+
+func MergeCategories(ctx context.Context, c *commands.MergeCategories, categories storage.CategoryStore, posts storage.PostStore, revisions storage.RevisionStore) error {
+	if err := reparentCategories(categories, c.From, c.Into); err != nil {
+		return err
+	}
+
+	if err := categories.Delete(c.From); err != nil {
+		return err
+	}
+
+	return recategorize(c.From, c.Into, c.User, posts, revisions)
+}
+
+// reparentCategories moves the direct subcategories of one category under
+// another.
+func reparentCategories(categories storage.CategoryStore, from string, to string) error {
+	all, err := categories.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range all {
+		if c.Parent != from {
+			continue
+		}
+
+		c.Parent = to
+		if err := categories.Store(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recategorize(from string, to string, user string, posts storage.PostStore, revisions storage.RevisionStore) error {
+	return rewritePosts(&storage.PostFilters{Categories: []string{from}}, user, posts, revisions, func(p *v1.Post) {
+		p.Categories = storage.ReplaceTerm(p.Categories, from, to)
+	})
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/satori/go.uuid"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+func StoreWebhook(ctx context.Context, c *commands.StoreWebhook, hooks storage.WebhookStore) error {
+	if c.New {
+		c.Webhook.ID = uuid.NewV4().String()
+		c.Webhook.Created = time.Now().Unix()
+	}
+
+	return hooks.Store(c.Webhook, c.New)
+}
+
+func DeleteWebhook(ctx context.Context, c *commands.DeleteWebhook, hooks storage.WebhookStore, deliveries storage.DeliveryStore) error {
+	if err := hooks.Delete(c.ID); err != nil {
+		return err
+	}
+
+	return deliveries.DeleteAll(c.ID)
+}
+
+func AppendDelivery(ctx context.Context, c *commands.AppendDelivery, deliveries storage.DeliveryStore) error {
+	return deliveries.Append(c.Delivery, c.Keep)
+}
+
+func SearchWebhooks(ctx context.Context, q *queries.SearchWebhooks, hooks storage.WebhookStore) ([]*v1.Webhook, error) {
+	return hooks.FindAll()
+}
+
+func FindWebhook(ctx context.Context, q *queries.FindWebhook, hooks storage.WebhookStore) (*v1.Webhook, error) {
+	return hooks.Find(q.ID)
+}
+
+func SearchDeliveries(ctx context.Context, q *queries.SearchDeliveries, deliveries storage.DeliveryStore) ([]*v1.Delivery, error) {
+	return deliveries.FindMany(q.Webhook)
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -16,7 +17,9 @@ var (
 )
 
 type AuthAPI interface {
-	Login(username, password string) (AuthToken, error)
+	Login(username, password string) (*v1.AuthTokens, error)
+	Refresh(refreshToken string) (*v1.AuthTokens, error)
+	Logout() error
 }
 
 type authAPI struct {
@@ -27,33 +30,76 @@ func (c *Client) Auth() AuthAPI {
 	return &authAPI{c}
 }
 
-func (api *authAPI) Login(username, password string) (AuthToken, error) {
-	u := &v1.User{Name: username, Password: password}
-	body, err := json.Marshal(u)
+func (api *authAPI) Login(username, password string) (*v1.AuthTokens, error) {
+	return api.requestTokens(&v1.AuthRequest{Name: username, Password: password})
+}
+
+// Refresh exchanges a refresh token for new tokens. The old refresh token
+// can't be used again afterwards.
+func (api *authAPI) Refresh(refreshToken string) (*v1.AuthTokens, error) {
+	return api.requestTokens(&v1.AuthRequest{RefreshToken: refreshToken})
+}
+
+func (api *authAPI) requestTokens(req *v1.AuthRequest) (*v1.AuthTokens, error) {
+	body, err := json.Marshal(req)
 	if err != nil {
-		return InvalidToken, err
+		return nil, err
 	}
 
 	url, err := api.urls().BuildAuth()
 	if err != nil {
-		return InvalidToken, err
+		return nil, err
 	}
 
 	r, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
-		return InvalidToken, err
+		return nil, err
 	}
 
 	resp, err := api.do(r)
 	if err != nil {
-		return InvalidToken, err
+		return nil, err
 	}
 
 	defer resp.Body.Close()
-	token, err := ioutil.ReadAll(resp.Body)
+	body, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return InvalidToken, err
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("authentication failed: %s", resp.Status)
+	}
+
+	tokens := &v1.AuthTokens{}
+	if err = json.Unmarshal(body, tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the session of the client's auth token.
+func (api *authAPI) Logout() error {
+	url, err := api.urls().BuildAuth()
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := api.do(r)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status returned: %s", resp.Status)
 	}
 
-	return AuthToken(token), nil
+	return nil
 }
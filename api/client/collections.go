@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+type CollectionsAPI interface {
+	SearchCollections() ([]*v1.Collection, error)
+	SearchItems(collection string) ([]*v1.Item, error)
+	GetItem(collection string, name string) (*v1.Item, error)
+	CreateItem(item *v1.Item) (*v1.Item, error)
+	UpdateItem(item *v1.Item) (*v1.Item, error)
+	DeleteItem(collection string, name string) error
+}
+
+type collectionsAPI struct {
+	*Client
+}
+
+func (c *Client) Collections() CollectionsAPI {
+	return &collectionsAPI{c}
+}
+
+func (api *collectionsAPI) SearchCollections() ([]*v1.Collection, error) {
+	url, err := api.urls().BuildCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	collections := make([]*v1.Collection, 0)
+	if err := api.send(http.MethodGet, url, nil, &collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func (api *collectionsAPI) SearchItems(collection string) ([]*v1.Item, error) {
+	url, err := api.urls().BuildCollectionItems(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*v1.Item, 0)
+	if err := api.send(http.MethodGet, url, nil, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (api *collectionsAPI) GetItem(collection string, name string) (*v1.Item, error) {
+	url, err := api.urls().BuildCollectionItem(collection, name)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &v1.Item{}
+	if err := api.send(http.MethodGet, url, nil, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (api *collectionsAPI) CreateItem(item *v1.Item) (*v1.Item, error) {
+	url, err := api.urls().BuildCollectionItems(item.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	created := &v1.Item{}
+	if err := api.send(http.MethodPost, url, item, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (api *collectionsAPI) UpdateItem(item *v1.Item) (*v1.Item, error) {
+	url, err := api.urls().BuildCollectionItem(item.Collection, item.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := &v1.Item{}
+	if err := api.send(http.MethodPut, url, item, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (api *collectionsAPI) DeleteItem(collection string, name string) error {
+	url, err := api.urls().BuildCollectionItem(collection, name)
+	if err != nil {
+		return err
+	}
+
+	return api.send(http.MethodDelete, url, nil, nil)
+}
+
+// send makes the request with in as its JSON body, if any, and decodes the
+// response into out, if any.
+func (api *collectionsAPI) send(method string, url string, in interface{}, out interface{}) error {
+	var rd io.Reader
+	if in != nil {
+		body, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+
+		rd = bytes.NewBuffer(body)
+	}
+
+	r, err := http.NewRequest(method, url, rd)
+	if err != nil {
+		return err
+	}
+
+	resp, err := api.do(r)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+type TaxonomyAPI interface {
+	SearchTags() ([]*v1.Tag, error)
+	SearchCategories() ([]*v1.Category, error)
+}
+
+type taxonomyAPI struct {
+	*Client
+}
+
+func (c *Client) Taxonomy() TaxonomyAPI {
+	return &taxonomyAPI{c}
+}
+
+func (api *taxonomyAPI) SearchTags() ([]*v1.Tag, error) {
+	url, err := api.urls().BuildTags()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*v1.Tag, 0)
+	if err := api.getJSON(url, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (api *taxonomyAPI) SearchCategories() ([]*v1.Category, error) {
+	url, err := api.urls().BuildCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*v1.Category, 0)
+	if err := api.getJSON(url, &categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (api *taxonomyAPI) getJSON(url string, v interface{}) error {
+	r, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := api.do(r)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,523 @@
+// Package openapi converts the route descriptors of the v1 API into an
+// OpenAPI 3 document.
+package openapi
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danielkrainas/gobag/api/describe"
+	"github.com/danielkrainas/gobag/api/errcode"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// Version is the version of the OpenAPI specification the documents follow.
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       *Info                `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower case method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+	Example     string  `json:"example,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+const (
+	securityScheme = "bearer"
+	errorsSchema   = "Errors"
+)
+
+// Generate returns the document describing the routes, for the given
+// version of the server.
+func Generate(routes []describe.Route, version string) *Document {
+	g := &generator{
+		schemas: make(map[string]*Schema),
+		codes:   map[string]bool{errcode.ErrorCodeUnknown.String(): true},
+	}
+
+	for name := range models {
+		g.model(name)
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: &Info{
+			Title:       "TinkersNest API",
+			Description: "Generated from the route descriptors of the server.",
+			Version:     version,
+		},
+		Paths: make(map[string]*PathItem),
+		Components: &Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityScheme: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "An access token from POST /v1/auth or an API key.",
+				},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		path, pathParams := parsePath(route.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		for _, m := range route.Methods {
+			(*item)[strings.ToLower(m.Method)] = g.operation(route, m, pathParams)
+		}
+	}
+
+	g.schemas[errorsSchema] = g.errorsSchema()
+	return doc
+}
+
+type generator struct {
+	schemas map[string]*Schema
+	// codes collects the error codes the routes can fail with.
+	codes map[string]bool
+}
+
+func (g *generator) operation(route describe.Route, m describe.Method, pathParams []*Parameter) *Operation {
+	op := &Operation{
+		OperationID: operationID(m.Method, route.Name),
+		Summary:     m.Description,
+		Description: route.Description,
+		Tags:        tags(route.Path),
+		Responses:   make(map[string]*Response),
+		Security:    security(route.Name, m.Method),
+	}
+
+	seen := make(map[string]bool)
+	addParam := func(p *Parameter) {
+		if key := p.In + ":" + p.Name; !seen[key] {
+			seen[key] = true
+			op.Parameters = append(op.Parameters, p)
+		}
+	}
+
+	for _, req := range m.Requests {
+		for _, p := range req.PathParameters {
+			addParam(parameter(p, "path"))
+		}
+
+		for _, p := range req.QueryParameters {
+			addParam(parameter(p, "query"))
+		}
+
+		for _, p := range req.Headers {
+			if !skipHeader(p.Name) {
+				addParam(parameter(p, "header"))
+			}
+		}
+
+		if req.Body.ContentType != "" {
+			if op.RequestBody == nil {
+				op.RequestBody = &RequestBody{
+					Required: true,
+					Content:  make(map[string]*MediaType),
+				}
+			}
+
+			typ := mediaType(req.Body.ContentType)
+			op.RequestBody.Content[typ] = &MediaType{Schema: g.bodySchema(route.Entity, m.Method, typ, req.Body, true)}
+		}
+
+		for _, resp := range req.Successes {
+			g.addResponse(op, route, m.Method, resp, false)
+		}
+
+		for _, resp := range req.Failures {
+			g.addResponse(op, route, m.Method, resp, true)
+		}
+	}
+
+	// the path decides the path parameters, whether the descriptor lists
+	// them or not
+	for _, p := range pathParams {
+		addParam(p)
+	}
+
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			p.Required = true
+		}
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &Response{Description: "Unexpected error"}
+	}
+
+	return op
+}
+
+// addResponse adds the response to the operation, merging it with any other
+// response of the same status.
+func (g *generator) addResponse(op *Operation, route describe.Route, method string, resp describe.Response, failure bool) {
+	status := strconv.Itoa(resp.StatusCode)
+	description := resp.Description
+	if description == "" {
+		description = resp.Name
+	}
+
+	var codes []string
+	for _, code := range resp.ErrorCodes {
+		codes = append(codes, "`"+code.String()+"`")
+		g.codes[code.String()] = true
+	}
+
+	if len(codes) > 0 {
+		description = strings.TrimSuffix(description, ".") + ": " + strings.Join(codes, ", ") + "."
+	}
+
+	r := op.Responses[status]
+	if r == nil {
+		if description == "" {
+			description = http.StatusText(resp.StatusCode)
+		}
+
+		r = &Response{Description: description}
+		op.Responses[status] = r
+	} else if description != "" && !strings.Contains(r.Description, description) {
+		r.Description += " " + description
+	}
+
+	for _, h := range resp.Headers {
+		if strings.EqualFold(h.Name, "Content-Type") {
+			continue
+		}
+
+		if r.Headers == nil {
+			r.Headers = make(map[string]*Header)
+		}
+
+		r.Headers[h.Name] = &Header{
+			Description: h.Description,
+			Schema:      parameterSchema(h),
+		}
+	}
+
+	if resp.Body.ContentType == "" {
+		return
+	}
+
+	if r.Content == nil {
+		r.Content = make(map[string]*MediaType)
+	}
+
+	typ := mediaType(resp.Body.ContentType)
+	if failure {
+		r.Content[typ] = &MediaType{Schema: ref(errorsSchema)}
+	} else {
+		r.Content[typ] = &MediaType{Schema: g.bodySchema(route.Entity, method, typ, resp.Body, false)}
+	}
+}
+
+// bodySchema picks the schema of a request or response body. JSON bodies
+// are the route's entity, except that requests and the responses to
+// anything but GET are about a single one of the entities of a list route,
+// like creating a user on /v1/users.
+func (g *generator) bodySchema(entity string, method string, typ string, body describe.Body, request bool) *Schema {
+	switch {
+	case typ == "application/json-patch+json":
+		return jsonPatchSchema()
+	case typ == "application/json" || typ == "application/merge-patch+json":
+	case typ == "*/*":
+		return &Schema{Type: "string", Format: "binary"}
+	default:
+		return &Schema{Type: "string", Description: body.Format}
+	}
+
+	// list routes take and return single entities for anything but GET,
+	// unless the example says otherwise
+	list := strings.HasPrefix(entity, "[]") && !request && method == http.MethodGet
+	if example := strings.TrimSpace(body.Format); example != "" {
+		list = strings.HasPrefix(example, "[")
+	}
+
+	entity = strings.TrimPrefix(entity, "[]")
+	var s *Schema
+	if _, ok := models[entity]; ok {
+		s = g.model(entity)
+	} else {
+		// the descriptor's example is the best description there is
+		s = &Schema{Type: "object", Description: body.Format}
+		if list {
+			s.Type = "array"
+			s.Items = &Schema{Type: "object"}
+		}
+
+		return s
+	}
+
+	if list {
+		return &Schema{Type: "array", Items: s}
+	}
+
+	return s
+}
+
+func (g *generator) errorsSchema() *Schema {
+	codes := make([]string, 0, len(g.codes))
+	for code := range g.codes {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"errors": {
+				Type: "array",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"code":    {Type: "string", Enum: codes},
+						"message": {Type: "string"},
+						"detail": {
+							Description: "More about the error. Errors about the request body name the offending field and why it was rejected.",
+							Type:        "object",
+							Properties: map[string]*Schema{
+								"field":  {Type: "string"},
+								"reason": {Type: "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func jsonPatchSchema() *Schema {
+	return &Schema{
+		Type: "array",
+		Items: &Schema{
+			Type:     "object",
+			Required: []string{"op", "path"},
+			Properties: map[string]*Schema{
+				"op":    {Type: "string", Enum: []string{"add", "remove", "replace", "move", "copy", "test"}},
+				"path":  {Type: "string"},
+				"from":  {Type: "string"},
+				"value": {},
+			},
+		},
+	}
+}
+
+func parameter(p describe.Parameter, in string) *Parameter {
+	param := &Parameter{
+		Name:        p.Name,
+		In:          in,
+		Description: p.Description,
+		Required:    p.Required,
+		Schema:      parameterSchema(p),
+	}
+
+	if len(p.Examples) > 0 {
+		param.Example = p.Examples[0]
+	}
+
+	return param
+}
+
+func parameterSchema(p describe.Parameter) *Schema {
+	s := &Schema{Type: "string"}
+	switch p.Type {
+	case "integer", "boolean":
+		s.Type = p.Type
+	}
+
+	if p.Regexp != nil {
+		s.Pattern = "^(?:" + p.Regexp.String() + ")$"
+	}
+
+	return s
+}
+
+// skipHeader reports whether a request header is left out of the
+// parameters. OpenAPI describes these elsewhere or not at all.
+func skipHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "host", "authorization", "content-type", "accept", "content-length":
+		return true
+	}
+
+	return false
+}
+
+// parsePath turns a route path into an OpenAPI path template, taking out the
+// regular expressions of its variables and returning a parameter for each
+// of them.
+func parsePath(path string) (string, []*Parameter) {
+	var out bytes.Buffer
+	var params []*Parameter
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			out.WriteByte(path[i])
+			continue
+		}
+
+		// regular expressions can have braces of their own
+		depth, end := 0, i
+		for ; end < len(path); end++ {
+			if path[end] == '{' {
+				depth++
+			} else if path[end] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+
+		v := path[i+1 : end]
+		name, pattern := v, ""
+		if n := strings.Index(v, ":"); n >= 0 {
+			name, pattern = v[:n], v[n+1:]
+		}
+
+		p := &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		}
+
+		if pattern != "" {
+			p.Schema.Pattern = "^(?:" + pattern + ")$"
+		}
+
+		params = append(params, p)
+		out.WriteString("{" + name + "}")
+		i = end
+	}
+
+	return out.String(), params
+}
+
+// mediaType drops the parameters of a content type. The descriptors use a
+// placeholder for bodies that can be of any type.
+func mediaType(contentType string) string {
+	if strings.HasPrefix(contentType, "<") {
+		return "*/*"
+	}
+
+	if typ, _, err := mime.ParseMediaType(contentType); err == nil {
+		return typ
+	}
+
+	return contentType
+}
+
+// operationID makes an id like getPostByName from the method and the route
+// name.
+func operationID(method string, routeName string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.Split(routeName, "-") {
+		if part != "" {
+			id += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+
+	return id
+}
+
+// tags groups operations by the first part of their path after the version,
+// like blog or users.
+func tags(path string) []string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		return nil
+	}
+
+	return []string{strings.TrimSuffix(parts[1], ".json")}
+}
+
+// security lists the ways an operation can be authorized. Operations open to
+// anyone have none, and the ones anonymous readers can use also work without
+// a token.
+func security(routeName string, method string) []map[string][]string {
+	if v1.RoutePermission(routeName, method) == v1.NoPermission {
+		return nil
+	}
+
+	bearer := map[string][]string{securityScheme: {}}
+	if v1.AllowsAnonymous(routeName, method) {
+		return []map[string][]string{{}, bearer}
+	}
+
+	return []map[string][]string{bearer}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+}
+
+// models are the entities of the route descriptors that have a schema.
+// Every struct they refer to gets a schema of its own.
+var models = map[string]reflect.Type{
+	"Post":  reflect.TypeOf(v1.Post{}),
+	"User":  reflect.TypeOf(v1.User{}),
+	"Claim": reflect.TypeOf(v1.Claim{}),
+}
+
+// enums lists the values of the string types that only take a few.
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(v1.PostState("")): {
+		string(v1.PostDraft),
+		string(v1.PostScheduled),
+		string(v1.PostPublished),
+		string(v1.PostArchived),
+	},
+	reflect.TypeOf(v1.Role("")): {
+		string(v1.RoleAdmin),
+		string(v1.RoleEditor),
+		string(v1.RoleAuthor),
+		string(v1.RoleViewer),
+	},
+	reflect.TypeOf(v1.ResourceType("")): {
+		string(v1.PostResource),
+		string(v1.UserResource),
+	},
+	reflect.TypeOf(v1.ClaimState("")): {
+		string(v1.ClaimActive),
+		string(v1.ClaimExpired),
+		string(v1.ClaimRedeemed),
+		string(v1.ClaimRevoked),
+	},
+}
+
+// serverFields are kept up to date by the server and ignored in requests.
+var serverFields = map[string]bool{
+	"Post.created":   true,
+	"Post.state":     true,
+	"Claim.code":     true,
+	"Claim.uses":     true,
+	"Claim.user":     true,
+	"Claim.created":  true,
+	"Claim.redeemed": true,
+	"Claim.revoked":  true,
+	"Claim.state":    true,
+}
+
+// writeOnlyFields are accepted in requests but never returned.
+var writeOnlyFields = map[string]bool{
+	"User.password": true,
+}
+
+func (g *generator) model(name string) *Schema {
+	return g.schema(models[name])
+}
+
+// schema describes the type from its JSON encoding. Named structs are added
+// to the components and referred to.
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if values, ok := enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// added before the fields so types can refer to themselves
+			g.schemas[t.Name()] = &Schema{}
+			*g.schemas[t.Name()] = *g.object(t)
+		}
+
+		return ref(t.Name())
+	}
+
+	// interfaces and anything else can be any JSON value
+	return &Schema{}
+}
+
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag == "-" {
+			continue
+		} else if n := strings.Split(tag, ",")[0]; n != "" {
+			name = n
+		}
+
+		p := g.schema(f.Type)
+		if p.Ref != "" && f.Type.Kind() == reflect.Ptr {
+			// anything next to a $ref is ignored
+			p = &Schema{AllOf: []*Schema{p}, Nullable: true}
+		}
+
+		key := t.Name() + "." + name
+		p.ReadOnly = serverFields[key]
+		p.WriteOnly = writeOnlyFields[key]
+
+		s.Properties[name] = p
+	}
+
+	return s
+}
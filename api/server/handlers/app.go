@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,11 +14,13 @@ import (
 	"github.com/danielkrainas/tinkersnest/api/v1"
 	"github.com/danielkrainas/tinkersnest/auth"
 	"github.com/danielkrainas/tinkersnest/configuration"
+	"github.com/danielkrainas/tinkersnest/events"
 	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/render"
 	"github.com/danielkrainas/tinkersnest/storage"
 )
 
-type dispatchFunc func(ctx context.Context, r *http.Request) http.Handler
+type dispatchFunc func(ctx *appRequestContext, r *http.Request) http.Handler
 
 type App struct {
 	context.Context // TODO: does this need to be a context?
@@ -27,6 +28,12 @@ type App struct {
 	config *configuration.Config
 
 	router *mux.Router
+
+	keys *auth.KeySet
+
+	renders *render.Cache
+
+	events *events.Bus
 }
 
 func (app *App) Value(key interface{}) interface{} {
@@ -60,6 +67,45 @@ func (arc *appRequestContext) Value(key interface{}) interface{} {
 	return arc.Context.Value(key)
 }
 
+// getUser returns the user the request was authorized as, or nil for
+// anonymous and claim based requests.
+func getUser(ctx context.Context) *v1.User {
+	if u, ok := ctx.Value("user").(*v1.User); ok {
+		return u
+	}
+
+	return nil
+}
+
+// getUserName returns the name of the user the request was authorized as, or
+// an empty string.
+func getUserName(ctx context.Context) string {
+	if u := getUser(ctx); u != nil {
+		return u.Name
+	}
+
+	return ""
+}
+
+// getSession returns the session of the bearer token the request was made
+// with, or nil for anonymous and claim based requests.
+func getSession(ctx context.Context) *v1.Session {
+	if s, ok := ctx.Value("session").(*v1.Session); ok {
+		return s
+	}
+
+	return nil
+}
+
+// getAPIKey returns the API key the request was made with, if any.
+func getAPIKey(ctx context.Context) *v1.APIKey {
+	if k, ok := ctx.Value("apikey").(*v1.APIKey); ok {
+		return k
+	}
+
+	return nil
+}
+
 func getURLBuilder(ctx context.Context) *v1.URLBuilder {
 	if ub, ok := ctx.Value("url.builder").(*v1.URLBuilder); ok {
 		return ub
@@ -68,55 +114,220 @@ func getURLBuilder(ctx context.Context) *v1.URLBuilder {
 	return nil
 }
 
-func NewApp(ctx context.Context, config *configuration.Config) (*App, error) {
+// dispatchers are the handlers of the API routes by route name. Each of them
+// must have a descriptor.
+var dispatchers = map[string]dispatchFunc{
+	v1.RouteNameBase: func(ctx *appRequestContext, r *http.Request) http.Handler {
+		return http.HandlerFunc(apiBase)
+	},
+
+	v1.RouteNameBlog:                blogListDispatcher,
+	v1.RouteNamePostByName:          postByNameDispatcher,
+	v1.RouteNamePostsByUser:         postsByUserDispatcher,
+	v1.RouteNamePostRevisions:       postRevisionsDispatcher,
+	v1.RouteNamePostRevision:        postRevisionDispatcher,
+	v1.RouteNamePostRevisionRestore: postRevisionRestoreDispatcher,
+	v1.RouteNamePostDiff:            postDiffDispatcher,
+	v1.RouteNameBlogFeed:            feedDispatcher,
+	v1.RouteNameUserFeed:            feedDispatcher,
+	v1.RouteNameTagFeed:             feedDispatcher,
+	v1.RouteNameTags:                tagsDispatcher,
+	v1.RouteNameTagBySlug:           tagBySlugDispatcher,
+	v1.RouteNameTagPosts:            tagPostsDispatcher,
+	v1.RouteNameTagRename:           tagRenameDispatcher,
+	v1.RouteNameTagMerge:            tagMergeDispatcher,
+	v1.RouteNameCategories:          categoriesDispatcher,
+	v1.RouteNameCategoryBySlug:      categoryBySlugDispatcher,
+	v1.RouteNameCategoryPosts:       categoryPostsDispatcher,
+	v1.RouteNameCategoryRename:      categoryRenameDispatcher,
+	v1.RouteNameCategoryMerge:       categoryMergeDispatcher,
+	v1.RouteNamePostComments:        postCommentsDispatcher,
+	v1.RouteNamePostComment:         postCommentDispatcher,
+	v1.RouteNameComments:            commentsDispatcher,
+	v1.RouteNameCommentModeration:   commentModerationDispatcher,
+	v1.RouteNameCollections:         collectionsDispatcher,
+	v1.RouteNameCollection:          collectionDispatcher,
+	v1.RouteNameCollectionItems:     collectionItemsDispatcher,
+	v1.RouteNameCollectionItem:      collectionItemDispatcher,
+	v1.RouteNameWebhooks:            webhooksDispatcher,
+	v1.RouteNameWebhook:             webhookDispatcher,
+	v1.RouteNameWebhookDeliveries:   webhookDeliveriesDispatcher,
+	v1.RouteNameEvents:              eventsDispatcher,
+	v1.RouteNameUserRegistry:        userRegistryDispatcher,
+	v1.RouteNameUserByName:          userByNameDispatcher,
+	v1.RouteNameUserSessions:        userSessionsDispatcher,
+	v1.RouteNameUserSessionByID:     userSessionByIDDispatcher,
+	v1.RouteNameUserKeys:            userKeysDispatcher,
+	v1.RouteNameUserKeyByID:         userKeyByIDDispatcher,
+	v1.RouteNameAuth:                authDispatcher,
+	v1.RouteNameAuthKeys:            authKeysDispatcher,
+	v1.RouteNameMedia:               mediaDispatcher,
+	v1.RouteNameMediaByName:         mediaByNameDispatcher,
+	v1.RouteNameMediaMeta:           mediaMetaDispatcher,
+	v1.RouteNameClaims:              claimsDispatcher,
+	v1.RouteNameClaim:               claimDispatcher,
+	v1.RouteNameOpenAPI:             openAPIDispatcher,
+}
+
+func NewApp(ctx context.Context, config *configuration.Config, bus *events.Bus) (*App, error) {
 	app := &App{
 		Context: ctx,
 		config:  config,
 		router:  v1.RouterWithPrefix(""),
+		renders: render.NewCache(render.DefaultCacheSize),
+		events:  bus,
 	}
 
-	app.register(v1.RouteNameBase, func(ctx context.Context, r *http.Request) http.Handler {
-		return http.HandlerFunc(apiBase)
-	})
+	keys, err := auth.KeySetFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error loading auth keys: %v", err)
+	}
+
+	app.keys = keys
+	if keys.Ephemeral() {
+		acontext.GetLogger(app).Warn("no auth keys configured, using a random key. tokens will not survive a restart or work across instances")
+	}
+
+	for name, dispatch := range dispatchers {
+		if err := app.register(name, dispatch); err != nil {
+			return nil, err
+		}
+	}
+
+	// the openapi document is only as good as the descriptors, so every
+	// described route must be served and every served route described
+	for _, descriptor := range v1.API.Routes {
+		if app.router.GetRoute(descriptor.Name).GetHandler() == nil {
+			return nil, fmt.Errorf("route %q is described but has no handler", descriptor.Name)
+		}
+	}
 
-	app.register(v1.RouteNameBlog, blogListDispatcher)
-	app.register(v1.RouteNamePostByName, postByNameDispatcher)
-	app.register(v1.RouteNameUserRegistry, userRegistryDispatcher)
-	app.register(v1.RouteNameUserByName, userByNameDispatcher)
-	app.register(v1.RouteNameAuth, authDispatcher)
 	return app, nil
 }
 
 func (app *App) authorizeUser(ctx *appRequestContext, r *http.Request) error {
 	route := mux.CurrentRoute(r)
 	routeName := route.GetName()
-	bearer := r.Header.Get("Authorization")
-	authParts := strings.Split(bearer, ":")
-	bearer = strings.TrimSpace(authParts[len(authParts)-1])
+	perm := v1.RoutePermission(routeName, r.Method)
+	bearer := bearerToken(r)
+	if _, hasClaim := ctx.Value("claim").(*v1.Claim); hasClaim && r.Method == http.MethodPost {
+		// the claim itself grants permission to create the resource
+		perm = v1.NoPermission
+	}
+
 	if bearer == "" {
-		_, hasClaim := ctx.Value("claim").(*v1.Claim)
-		if hasClaim && r.Method == http.MethodPost {
+		if perm == v1.NoPermission || v1.AllowsAnonymous(routeName, r.Method) {
 			return nil
-		} else if routeName != v1.RouteNameAuth {
-			return errors.New("invalid bearer token")
 		}
+
+		return v1.ErrorCodeUnauthorized
 	}
 
-	userName, err := auth.VerifyBearerToken(bearer)
-	if err != nil {
-		return err
+	var user *v1.User
+	var err error
+	if auth.IsAPIKey(bearer) {
+		user, err = app.authorizeAPIKey(ctx, bearer)
+	} else {
+		user, err = app.authorizeSession(ctx, bearer)
 	}
 
-	user, err := cqrs.DispatchQuery(ctx, queries.FindUser{userName})
 	if err != nil {
 		return err
 	}
 
 	ctx.Context = context.WithValue(ctx.Context, "user", user)
-	ctx.Context = acontext.WithLogger(ctx.Context, acontext.GetLoggerWithField(ctx.Context, "user.name", userName))
+	ctx.Context = acontext.WithLogger(ctx.Context, acontext.GetLoggerWithField(ctx.Context, "user.name", user.Name))
+	if !user.Can(perm) {
+		return v1.ErrorCodeForbidden
+	}
+
 	return nil
 }
 
+// authorizeSession checks an access token and the session it was issued for.
+func (app *App) authorizeSession(ctx *appRequestContext, bearer string) (*v1.User, error) {
+	userName, sessionID, err := app.keys.VerifyBearerToken(bearer)
+	if err != nil {
+		acontext.GetLogger(ctx).Errorf("bearer token rejected: %v", err)
+		return nil, v1.ErrorCodeUnauthorized
+	}
+
+	sessionRaw, err := cqrs.DispatchQuery(ctx, &queries.FindSession{ID: sessionID})
+	if err != nil && err != storage.ErrNotFound {
+		return nil, err
+	}
+
+	session, ok := sessionRaw.(*v1.Session)
+	if !ok || session == nil || session.User != userName || session.Expired() {
+		acontext.GetLogger(ctx).Errorf("bearer token session %q was revoked or has expired", sessionID)
+		return nil, v1.ErrorCodeUnauthorized
+	}
+
+	user, err := findAuthorizedUser(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Context = context.WithValue(ctx.Context, "session", session)
+	return user, nil
+}
+
+// authorizeAPIKey checks an API key and returns its user, restricted to the
+// key's scopes.
+func (app *App) authorizeAPIKey(ctx *appRequestContext, key string) (*v1.User, error) {
+	keyID, hash, err := auth.ParseAPIKey(key)
+	if err != nil {
+		acontext.GetLogger(ctx).Errorf("api key rejected: %v", err)
+		return nil, v1.ErrorCodeUnauthorized
+	}
+
+	keyRaw, err := cqrs.DispatchQuery(ctx, &queries.FindAPIKey{ID: keyID})
+	if err != nil && err != storage.ErrNotFound {
+		return nil, err
+	}
+
+	apiKey, ok := keyRaw.(*v1.APIKey)
+	if !ok || apiKey == nil || !auth.SecretHashEqual(apiKey.Hash, hash) || apiKey.Expired() {
+		acontext.GetLogger(ctx).Errorf("api key %q is unknown, revoked or has expired", keyID)
+		return nil, v1.ErrorCodeUnauthorized
+	}
+
+	user, err := findAuthorizedUser(ctx, apiKey.User)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Context = context.WithValue(ctx.Context, "apikey", apiKey)
+	return user.Restrict(apiKey.Scopes), nil
+}
+
+func findAuthorizedUser(ctx *appRequestContext, userName string) (*v1.User, error) {
+	userRaw, err := cqrs.DispatchQuery(ctx, &queries.FindUser{Name: userName})
+	if err != nil && err != storage.ErrNotFound {
+		return nil, err
+	}
+
+	user, ok := userRaw.(*v1.User)
+	if !ok || user == nil {
+		acontext.GetLogger(ctx).Errorf("credentials belong to %q, who is not a known user", userName)
+		return nil, v1.ErrorCodeUnauthorized
+	}
+
+	return user, nil
+}
+
+// bearerToken returns the access token or API key from an
+// "Authorization: Bearer <token>" header, or an empty string if there isn't
+// one.
+func bearerToken(r *http.Request) string {
+	parts := strings.SplitN(strings.TrimSpace(r.Header.Get("Authorization")), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(parts[1])
+}
+
 func (app *App) dispatcher(dispatch dispatchFunc) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := app.context(w, r)
@@ -124,10 +335,10 @@ func (app *App) dispatcher(dispatch dispatchFunc) http.Handler {
 
 		if err := preloadClaim(ctx, r); err != nil {
 			acontext.GetLogger(ctx).Error(err)
-			ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		} else if err := app.authorizeUser(ctx, r); err != nil {
 			acontext.GetLogger(ctx).Error(err)
-			ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		} else {
 			dispatch(ctx, r).ServeHTTP(w, r)
 		}
@@ -183,8 +394,14 @@ func (app *App) context(w http.ResponseWriter, r *http.Request) *appRequestConte
 	return arc
 }
 
-func (app *App) register(routeName string, dispatch dispatchFunc) {
-	app.router.GetRoute(routeName).Handler(app.dispatcher(dispatch))
+func (app *App) register(routeName string, dispatch dispatchFunc) error {
+	route := app.router.GetRoute(routeName)
+	if route == nil {
+		return fmt.Errorf("route %q has no descriptor", routeName)
+	}
+
+	route.Handler(app.dispatcher(dispatch))
+	return nil
 }
 
 func (app *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -214,42 +431,41 @@ func preloadClaim(ctx *appRequestContext, r *http.Request) error {
 		route := mux.CurrentRoute(r)
 		routeName := route.GetName()
 
+		expect := v1.NoResource
+		switch routeName {
+		case v1.RouteNameBlog:
+			expect = v1.PostResource
+		case v1.RouteNameUserRegistry:
+			expect = v1.UserResource
+		default:
+			// not something that requires a claim, and it mustn't stand in
+			// for the permissions the route needs
+			acontext.GetLogger(ctx).Warn("ignoring unneeded claim for this request")
+			return nil
+		}
+
 		rclaim, err := cqrs.DispatchQuery(ctx, &queries.FindClaim{Code: code})
 		if err != nil && err != storage.ErrNotFound {
 			return err
 		} else if err == storage.ErrNotFound {
-			return fmt.Errorf("no such claim")
+			return v1.InvalidField(v1.ErrorCodeClaimInvalid, "TINKERSNEST-CLAIM", "no such claim")
 		}
 
 		claim, ok := rclaim.(*v1.Claim)
 		if !ok {
-			// TODO: api error type
-			return fmt.Errorf("claim data is invalid")
+			return fmt.Errorf("couldn't convert raw value (%#+v) to claim", rclaim)
 		}
 
-		if claim.Redeemed != 0 {
-			// TODO: api error type
-			return fmt.Errorf("no such claim")
+		if state := claim.CurrentState(); state != v1.ClaimActive {
+			return v1.InvalidField(v1.ErrorCodeClaimInvalid, "TINKERSNEST-CLAIM", "claim is %s", state)
 		}
 
-		ctx.Context = context.WithValue(ctx.Context, "claim", claim)
-		ctx.Context = acontext.WithLogger(ctx.Context, acontext.GetLoggerWithField(ctx.Context, "claim", code))
-
-		expect := v1.NoResource
-		switch routeName {
-		case v1.RouteNameBlog:
-			expect = v1.PostResource
-		case v1.RouteNameUserRegistry:
-			expect = v1.UserResource
-		default:
-			// not something that requires a claim
-			acontext.GetLogger(ctx).Warn("ignoring unneeded claim for this request")
+		if expect != claim.ResourceType {
+			return v1.InvalidField(v1.ErrorCodeClaimInvalid, "TINKERSNEST-CLAIM", "claim cannot be used for %q resources", expect)
 		}
 
-		if expect != v1.NoResource && expect != claim.ResourceType {
-			// TODO: api error type
-			return fmt.Errorf("claim cannot be used for %q resources", expect)
-		}
+		ctx.Context = context.WithValue(ctx.Context, "claim", claim)
+		ctx.Context = acontext.WithLogger(ctx.Context, acontext.GetLoggerWithField(ctx.Context, "claim", code))
 	}
 
 	return nil
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	cfg "github.com/danielkrainas/gobag/configuration"
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+	"github.com/gorilla/mux"
+
+	"github.com/danielkrainas/tinkersnest/actions"
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	_ "github.com/danielkrainas/tinkersnest/blobs/driver/inmemory"
+	"github.com/danielkrainas/tinkersnest/configuration"
+	"github.com/danielkrainas/tinkersnest/events"
+	"github.com/danielkrainas/tinkersnest/setup"
+	_ "github.com/danielkrainas/tinkersnest/storage/driver/inmemory"
+)
+
+func newTestApp(t *testing.T) *App {
+	app, err := NewApp(acontext.Background(), &configuration.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+// newStoredApp returns an app dispatching to in-memory storage the way the
+// server does, and the setup manager in front of it.
+func newStoredApp(t *testing.T) (*App, *setup.SetupManager) {
+	config := &configuration.Config{Storage: cfg.Driver{"inmemory": cfg.Parameters{}}}
+	ap, err := actions.FromConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setupManager := &setup.SetupManager{}
+	bus := events.NewBus(0)
+	ctx := cqrs.WithCommandDispatch(acontext.Background(), &cqrs.CommandDispatcher{
+		Handlers: []cqrs.CommandHandler{setupManager, &events.Handler{Bus: bus, Inner: ap}},
+	})
+
+	ctx = cqrs.WithQueryDispatch(ctx, &cqrs.QueryDispatcher{
+		Executors: []cqrs.QueryExecutor{setupManager, ap},
+	})
+
+	app, err := NewApp(ctx, config, bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app, setupManager
+}
+
+// serve sends a request to the app with the bearer token, if any, and the
+// header name and value pairs.
+func serve(app *App, method string, path string, token string, body string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, r)
+	return rec
+}
+
+// createAdmin creates the first user, who is an admin, and returns their
+// access token.
+func createAdmin(t *testing.T, app *App, setupManager *setup.SetupManager) string {
+	claim := setupManager.AddFirstUserClaim(app)
+	rec := serve(app, "POST", "/v1/users", "", `{"name":"admin","password":"secret"}`, "TINKERSNEST-CLAIM", claim.Code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("creating the first user = %d %s", rec.Code, rec.Body)
+	}
+
+	return login(t, app, "admin", "secret")
+}
+
+func login(t *testing.T, app *App, name string, password string) string {
+	rec := serve(app, "POST", "/v1/auth", "", fmt.Sprintf(`{"name":%q,"password":%q}`, name, password))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("logging in as %s = %d %s", name, rec.Code, rec.Body)
+	}
+
+	tokens := &v1.AuthTokens{}
+	if err := json.Unmarshal(rec.Body.Bytes(), tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	return tokens.AccessToken
+}
+
+// hasErrorCode reports whether the error response body has an error with the
+// code.
+func hasErrorCode(body []byte, code string) bool {
+	resp := struct {
+		Errors []struct {
+			Code string `json:"code"`
+		} `json:"errors"`
+	}{}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false
+	}
+
+	for _, e := range resp.Errors {
+		if e.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestEveryRouteIsDescribed(t *testing.T) {
+	described := make(map[string]bool)
+	for _, descriptor := range v1.API.Routes {
+		described[descriptor.Name] = true
+	}
+
+	for name := range dispatchers {
+		if !described[name] {
+			t.Errorf("route %q has a handler but no descriptor", name)
+		}
+	}
+
+	app := newTestApp(t)
+	err := app.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if !described[route.GetName()] {
+			t.Errorf("router has route %q, which has no descriptor", route.GetName())
+		} else if route.GetHandler() == nil {
+			t.Errorf("route %q has no handler", route.GetName())
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEveryDescribedRouteIsServed(t *testing.T) {
+	for _, descriptor := range v1.API.Routes {
+		dispatch, ok := dispatchers[descriptor.Name]
+		if !ok {
+			t.Errorf("route %q is described but has no handler", descriptor.Name)
+			continue
+		}
+
+		r := httptest.NewRequest(http.MethodGet, descriptor.Path, nil)
+		methods, ok := dispatch(&appRequestContext{}, r).(handlers.MethodHandler)
+		if !ok {
+			// a plain handler serves every method
+			continue
+		}
+
+		expected := make([]string, 0, len(descriptor.Methods))
+		for _, m := range descriptor.Methods {
+			expected = append(expected, m.Method)
+		}
+
+		served := make([]string, 0, len(methods))
+		for m := range methods {
+			served = append(served, m)
+		}
+
+		sort.Strings(expected)
+		sort.Strings(served)
+		if strings.Join(expected, ",") != strings.Join(served, ",") {
+			t.Errorf("route %q is described with %v but serves %v", descriptor.Name, expected, served)
+		}
+	}
+}
+
+func TestRegisterUndescribedRoute(t *testing.T) {
+	app := newTestApp(t)
+	if err := app.register("no-such-route", dispatchers[v1.RouteNameBase]); err == nil {
+		t.Fatal("registering a route without a descriptor succeeded")
+	}
+}
@@ -1,80 +1,220 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
-	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
-	"github.com/danielkrainas/gobag/api/errcode"
 	"github.com/danielkrainas/gobag/context"
 	"github.com/danielkrainas/gobag/decouple/cqrs"
 	"github.com/gorilla/handlers"
 
 	"github.com/danielkrainas/tinkersnest/api/v1"
 	"github.com/danielkrainas/tinkersnest/auth"
+	"github.com/danielkrainas/tinkersnest/commands"
 	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
 )
 
-func authDispatcher(ctx context.Context, r *http.Request) http.Handler {
+func authDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
 	h := &authHandler{
-		Context: ctx,
+		appRequestContext: ctx,
 	}
 
 	return handlers.MethodHandler{
-		"POST": withTraceLogging("Authorize", h.Auth),
+		"POST":   withTraceLogging("Authorize", h.Auth),
+		"DELETE": withTraceLogging("Logout", h.Logout),
+	}
+}
+
+func authKeysDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &authHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetAuthKeys", h.GetKeys),
 	}
 }
 
 type authHandler struct {
-	context.Context
+	*appRequestContext
 }
 
 func (ctx *authHandler) Auth(w http.ResponseWriter, r *http.Request) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
-	creds := &v1.User{}
-	if err = json.Unmarshal(body, creds); err != nil {
+	req := &v1.AuthRequest{}
+	if err = json.Unmarshal(body, req); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return
+	}
+
+	var user *v1.User
+	var session *v1.Session
+	if req.RefreshToken != "" {
+		user, session = ctx.refreshSession(req.RefreshToken)
+	} else {
+		user, session = ctx.startSession(req, r.UserAgent())
+	}
+
+	if user == nil {
 		return
 	}
 
-	userData, err := cqrs.DispatchQuery(ctx, &queries.FindUser{Name: creds.Name})
+	keys := getApp(ctx).keys
+	refreshToken, refreshHash, err := auth.GenerateRefreshToken(session.ID)
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
-	user, ok := userData.(*v1.User)
-	if !ok {
-		acontext.GetLogger(ctx).Error("couldn't cast user data")
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown)
+	now := time.Now()
+	isNew := session.Created == 0
+	if isNew {
+		session.Created = now.Unix()
+	}
+
+	session.Refreshed = now.Unix()
+	session.Expires = now.Add(keys.SessionLifetime()).Unix()
+	session.RefreshHash = refreshHash
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreSession{New: isNew, Session: session}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
-	creds.HashedPassword = auth.HashPassword(creds.Password, user.Salt)
-	if creds.HashedPassword != user.HashedPassword {
-		acontext.GetLogger(ctx).Error("invalid username or password")
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail("invalid username or password"))
+	accessToken, err := keys.BearerToken(user, session.ID)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
-	token, err := auth.BearerToken(user)
+	tokens := &v1.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(keys.TokenLifetime() / time.Second),
+	}
+
+	if err := v1.ServeJSON(w, tokens); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending auth tokens json: %v", err)
+	}
+}
+
+// startSession checks the user's credentials and returns a new, not yet
+// stored, session for them.
+func (ctx *authHandler) startSession(req *v1.AuthRequest, userAgent string) (*v1.User, *v1.Session) {
+	userData, err := cqrs.DispatchQuery(ctx, &queries.FindUser{Name: req.Name})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil, nil
+	}
+
+	user, ok := userData.(*v1.User)
+	if !ok || user == nil || auth.HashPassword(req.Password, user.Salt) != user.HashedPassword {
+		acontext.GetLogger(ctx).Error("invalid username or password")
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnauthorized)
+		return nil, nil
+	}
+
+	id, err := auth.GenerateSessionID()
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil, nil
+	}
+
+	session := &v1.Session{
+		ID:        id,
+		User:      user.Name,
+		UserAgent: userAgent,
+	}
+
+	return user, session
+}
+
+// refreshSession returns the session the refresh token belongs to. Refresh
+// tokens are rotated on every use, so an old token being presented again means
+// it was copied; the session is revoked to lock out whoever holds it.
+func (ctx *authHandler) refreshSession(refreshToken string) (*v1.User, *v1.Session) {
+	sessionID, refreshHash, err := auth.ParseRefreshToken(refreshToken)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnauthorized)
+		return nil, nil
+	}
+
+	sessionRaw, err := cqrs.DispatchQuery(ctx, &queries.FindSession{ID: sessionID})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil, nil
+	}
+
+	session, ok := sessionRaw.(*v1.Session)
+	if !ok || session == nil || session.Expired() {
+		acontext.GetLogger(ctx).Errorf("session %q was revoked or has expired", sessionID)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnauthorized)
+		return nil, nil
+	}
+
+	if !auth.SecretHashEqual(session.RefreshHash, refreshHash) {
+		acontext.GetLogger(ctx).Warnf("refresh token reused for session %q, revoking it", sessionID)
+		if err := cqrs.DispatchCommand(ctx, &commands.DeleteSession{ID: sessionID}); err != nil {
+			acontext.GetLogger(ctx).Errorf("error revoking session: %v", err)
+		}
+
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnauthorized)
+		return nil, nil
+	}
+
+	userData, err := cqrs.DispatchQuery(ctx, &queries.FindUser{Name: session.User})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil, nil
+	}
+
+	user, ok := userData.(*v1.User)
+	if !ok || user == nil {
+		acontext.GetLogger(ctx).Errorf("session user %q no longer exists", session.User)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnauthorized)
+		return nil, nil
+	}
+
+	return user, session
+}
+
+func (ctx *authHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	session := getSession(ctx)
+	if session == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnauthorized)
 		return
 	}
 
-	if _, err = io.WriteString(w, token); err != nil {
-		acontext.GetLogger(ctx).Errorf("error sending auth token: %v", err)
+	err := cqrs.DispatchCommand(ctx, &commands.DeleteSession{ID: session.ID})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *authHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	if err := v1.ServeJSON(w, getApp(ctx).keys.PublicKeys()); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending auth keys json: %v", err)
 	}
 }
@@ -3,10 +3,13 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
-	"github.com/danielkrainas/gobag/api/errcode"
 	"github.com/danielkrainas/gobag/context"
 	"github.com/danielkrainas/gobag/decouple/cqrs"
 	"github.com/gorilla/handlers"
@@ -15,12 +18,13 @@ import (
 	"github.com/danielkrainas/tinkersnest/api/v1"
 	"github.com/danielkrainas/tinkersnest/commands"
 	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/render"
 	"github.com/danielkrainas/tinkersnest/storage"
 )
 
-func blogListDispatcher(ctx context.Context, r *http.Request) http.Handler {
+func blogListDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
 	h := &blogHandler{
-		Context: ctx,
+		appRequestContext: ctx,
 	}
 
 	return handlers.MethodHandler{
@@ -29,85 +33,261 @@ func blogListDispatcher(ctx context.Context, r *http.Request) http.Handler {
 	}
 }
 
-func postByNameDispatcher(ctx context.Context, r *http.Request) http.Handler {
+func postsByUserDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
 	h := &blogHandler{
-		Context: ctx,
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetUserPosts", h.GetAllPosts),
+	}
+}
+
+func postByNameDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &blogHandler{
+		appRequestContext: ctx,
 	}
 
 	return handlers.MethodHandler{
 		"GET":    withTraceLogging("GetPost", h.GetPost),
 		"DELETE": withTraceLogging("DeletePost", h.DeletePost),
 		"PUT":    withTraceLogging("UpdatePost", h.UpdatePost),
+		"PATCH":  withTraceLogging("PatchPost", h.PatchPost),
 	}
 }
 
 type blogHandler struct {
-	context.Context
+	*appRequestContext
 }
 
-func (ctx *blogHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+// findModifiablePost loads the post named in the route and checks that the
+// current user is allowed to change it. It returns nil after appending the
+// appropriate error if the post is missing or access is denied.
+func (ctx *blogHandler) findModifiablePost() *v1.Post {
 	postName := acontext.GetStringValue(ctx, "vars.post_name")
 	postRaw, err := cqrs.DispatchQuery(ctx, &queries.FindPost{
 		Name: postName,
 	})
 
-	if err != nil {
+	if err != nil && err != storage.ErrNotFound {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
-		return
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	post, ok := postRaw.(*v1.Post)
+	if !ok || post == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
 	}
 
-	if postRaw == nil {
-		ctx.Context = acontext.AppendError(ctx, v1.ErrorCodeResourceUnknown)
+	if !canModifyPost(getUser(ctx), post) {
+		acontext.GetLogger(ctx).Errorf("user not allowed to modify post %q", postName)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeForbidden)
+		return nil
+	}
+
+	return post
+}
+
+// canModifyPost reports whether the user may change or delete the post.
+// Authors can only touch their own posts while editors can change any.
+func canModifyPost(u *v1.User, p *v1.Post) bool {
+	if u == nil {
+		return false
+	} else if u.Can(v1.PermissionEditAnyPost) {
+		return true
+	}
+
+	return u.Can(v1.PermissionWritePosts) && p.Author != nil && p.Author.User == u.Name
+}
+
+func (ctx *blogHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	post := ctx.findModifiablePost()
+	if post == nil || !checkIfMatch(ctx.appRequestContext, r, post.Version) {
 		return
 	}
 
-	post := postRaw.(*v1.Post)
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
 	p := &v1.Post{}
 	if err = json.Unmarshal(body, p); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
 		return
 	}
 
-	post.Publish = p.Publish
+	// publishing and the schedule are only changed when the body has them,
+	// and a schedule of 0 clears it
+	schedule := struct {
+		Publish     *bool  `json:"publish"`
+		PublishAt   *int64 `json:"publish_at"`
+		UnpublishAt *int64 `json:"unpublish_at"`
+	}{}
+
+	if err = json.Unmarshal(body, &schedule); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return
+	}
+
+	if !checkPostCategories(ctx.appRequestContext, p) {
+		return
+	}
+
+	if schedule.Publish != nil {
+		post.Publish = *schedule.Publish
+	}
+
+	if schedule.PublishAt != nil {
+		post.PublishAt = *schedule.PublishAt
+	}
+
+	if schedule.UnpublishAt != nil {
+		post.UnpublishAt = *schedule.UnpublishAt
+	}
+
 	if p.Title != "" {
 		post.Title = p.Title
 	}
 
+	if p.Tags != nil {
+		post.Tags = p.Tags
+	}
+
+	if p.Categories != nil {
+		post.Categories = p.Categories
+	}
+
 	if len(p.Content) > 0 {
 		post.Content = p.Content
 	}
 
-	if err := cqrs.DispatchCommand(ctx, &commands.StorePost{New: false, Post: post}); err != nil {
+	if err := cqrs.DispatchCommand(ctx, &commands.StorePost{New: false, Post: post, User: getUserName(ctx)}); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+		if err == storage.ErrConflict {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodePreconditionFailed)
+		} else {
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
 		return
 	}
 
+	w.Header().Set("ETag", etag(post.Version))
 	if err := v1.ServeJSON(w, post); err != nil {
 		acontext.GetLogger(ctx).Errorf("error sending post json: %v", err)
 	}
 }
 
+// PatchPost applies a JSON Merge Patch or JSON Patch to the post. Unlike PUT
+// it can clear fields and change the author, and the patched post is checked
+// as a whole before it's stored.
+func (ctx *blogHandler) PatchPost(w http.ResponseWriter, r *http.Request) {
+	post := ctx.findModifiablePost()
+	if post == nil || !checkIfMatch(ctx.appRequestContext, r, post.Version) {
+		return
+	}
+
+	p := &v1.Post{}
+	if !readPatch(ctx.appRequestContext, w, r, post, p) {
+		return
+	}
+
+	if err := validatePatchedPost(post, p); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return
+	}
+
+	if !checkPostCategories(ctx.appRequestContext, p) || !ctx.checkPostAuthor(post, p) {
+		return
+	}
+
+	// the server keeps these up to date
+	p.Created = post.Created
+	p.State = post.State
+	p.Version = post.Version
+	if err := cqrs.DispatchCommand(ctx, &commands.StorePost{New: false, Post: p, User: getUserName(ctx)}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		if err == storage.ErrConflict {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodePreconditionFailed)
+		} else {
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "post.name", p.Name).Infof("blog post %q patched", p.Name)
+	w.Header().Set("ETag", etag(p.Version))
+	if err := v1.ServeJSON(w, p); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending post json: %v", err)
+	}
+}
+
+// checkPostAuthor makes sure a patch only hands the post to another user if
+// the current user may edit anyone's posts, and only to a user that exists.
+func (ctx *blogHandler) checkPostAuthor(post *v1.Post, p *v1.Post) bool {
+	if post.Author != nil && p.Author.User == post.Author.User {
+		return true
+	}
+
+	if !getUser(ctx).Can(v1.PermissionEditAnyPost) {
+		acontext.GetLogger(ctx).Errorf("user not allowed to change the author of post %q", post.Name)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeForbidden)
+		return false
+	}
+
+	userRaw, err := cqrs.DispatchQuery(ctx, &queries.FindUser{Name: p.Author.User})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	if u, ok := userRaw.(*v1.User); !ok || u == nil {
+		err := v1.InvalidField(v1.ErrorCodeFieldInvalid, "author.user", "unknown user %q", p.Author.User)
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return false
+	}
+
+	return true
+}
+
+// validatePatchedPost checks what a patch made of the post that PUT can't
+// change. The rest is validated when the post is stored.
+func validatePatchedPost(post *v1.Post, p *v1.Post) error {
+	if p.Name != post.Name {
+		return v1.InvalidField(v1.ErrorCodeFieldInvalid, "name", "name can't be changed")
+	} else if p.Author == nil || p.Author.User == "" {
+		return v1.InvalidField(v1.ErrorCodeFieldRequired, "author.user", "author.user can't be empty")
+	}
+
+	return nil
+}
+
 func (ctx *blogHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
-	postName := acontext.GetStringValue(ctx, "vars.post_name")
-	err := cqrs.DispatchCommand(ctx, &commands.DeletePost{postName})
+	post := ctx.findModifiablePost()
+	if post == nil {
+		return
+	}
+
+	err := cqrs.DispatchCommand(ctx, &commands.DeletePost{Name: post.Name})
 	if err != nil {
 		if err == storage.ErrNotFound {
 			acontext.GetLogger(ctx).Error("post not found")
-			ctx.Context = acontext.AppendError(ctx, v1.ErrorCodeResourceUnknown)
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
 			return
 		} else {
 			acontext.GetLogger(ctx).Error(err)
-			ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 			return
 		}
 	}
@@ -116,23 +296,48 @@ func (ctx *blogHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 }
 
 func (ctx *blogHandler) GetPost(w http.ResponseWriter, r *http.Request) {
+	renderHTML, err := renderParam(r)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
 	postName := acontext.GetStringValue(ctx, "vars.post_name")
 	post, err := cqrs.DispatchQuery(ctx, &queries.FindPost{
 		Name: postName,
 	})
 
-	if err != nil {
+	if err != nil && err != storage.ErrNotFound {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
-	if post == nil {
-		ctx.Context = acontext.AppendError(ctx, v1.ErrorCodeResourceUnknown)
+	p, ok := post.(*v1.Post)
+	if !ok || p == nil || (!p.Publish && getUser(ctx) == nil) {
+		// drafts don't exist as far as anonymous readers are concerned
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
 		return
 	}
 
-	if err := v1.ServeJSON(w, post); err != nil {
+	representation := ""
+	if renderHTML {
+		representation = "html"
+		if p, err = renderPost(ctx, p); err != nil {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+			return
+		}
+	}
+
+	// drafts are only found by signed in users
+	w.Header().Add("Vary", "Authorization")
+	if setETag(w, r, p.Version, representation) {
+		return
+	}
+
+	if err := v1.ServeJSON(w, p); err != nil {
 		acontext.GetLogger(ctx).Errorf("error sending post json: %v", err)
 	}
 }
@@ -141,44 +346,262 @@ func (ctx *blogHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
 	p := &v1.Post{}
 	if err = json.Unmarshal(body, p); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
 		return
 	}
 
-	if err := cqrs.DispatchCommand(ctx, &commands.StorePost{New: true, Post: p}); err != nil {
+	if !checkPostCategories(ctx.appRequestContext, p) {
+		return
+	}
+
+	if user := getUser(ctx); user != nil {
+		if p.Author == nil {
+			p.Author = &v1.Author{Name: user.FullName}
+		}
+
+		p.Author.User = user.Name
+	}
+
+	// the claim is redeemed first so that two requests can't both use its
+	// last redemption, and given back if the store fails
+	if claim, ok := ctx.Value("claim").(*v1.Claim); ok {
+		err := cqrs.DispatchCommand(ctx, &commands.RedeemClaim{Code: claim.Code})
+		if err != nil {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+			return
+		}
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StorePost{New: true, Post: p, User: getUserName(ctx)}); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+		releaseClaim(ctx.appRequestContext)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
 	acontext.GetLoggerWithField(ctx, "post.name", p.Name).Infof("blog post %q created", p.Name)
+	w.Header().Set("ETag", etag(p.Version))
 	if err := v1.ServeJSON(w, p); err != nil {
 		acontext.GetLogger(ctx).Errorf("error sending blog post json: %v", err)
 	}
 }
 
 func (ctx *blogHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
-	q := &queries.SearchPosts{}
+	q, err := searchPostsQuery(r)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	renderHTML, err := renderParam(r)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if getUser(ctx) == nil {
+		published := true
+		q.Published = &published
+	}
+
+	userName := ""
 	routeName := mux.CurrentRoute(r).GetName()
-	if routeName == v1.RouteNamePostsByUser {
-		q.Author = &v1.Author{User: acontext.GetStringValue(ctx, "vars.user_name")}
+	switch routeName {
+	case v1.RouteNamePostsByUser:
+		userName = acontext.GetStringValue(ctx, "vars.user_name")
+		q.Author = &v1.Author{User: userName}
+	case v1.RouteNameTagPosts:
+		q.Tags = append(q.Tags, acontext.GetStringValue(ctx, "vars.tag"))
+	case v1.RouteNameCategoryPosts:
+		q.Category = acontext.GetStringValue(ctx, "vars.category")
 	}
 
-	posts, err := cqrs.DispatchQuery(ctx, q)
+	pageRaw, err := cqrs.DispatchQuery(ctx, q)
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+		if err == storage.ErrInvalidCursor {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		} else {
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
 		return
 	}
 
-	if err := v1.ServeJSON(w, posts); err != nil {
+	page := pageRaw.(*storage.PostPage)
+	if page.Next != "" {
+		values := r.URL.Query()
+		values.Set("cursor", page.Next)
+
+		var nextURL string
+		urls := getURLBuilder(ctx)
+		switch routeName {
+		case v1.RouteNamePostsByUser:
+			values.Del("author")
+			nextURL, err = urls.BuildPostsByUser(userName, values)
+		case v1.RouteNameTagPosts:
+			nextURL, err = urls.BuildTagPosts(acontext.GetStringValue(ctx, "vars.tag"), values)
+		case v1.RouteNameCategoryPosts:
+			values.Del("category")
+			nextURL, err = urls.BuildCategoryPosts(q.Category, values)
+		default:
+			nextURL, err = urls.BuildBlog(values)
+		}
+
+		if err != nil {
+			acontext.GetLogger(ctx).Errorf("error building next page url: %v", err)
+		} else {
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", nextURL))
+		}
+	}
+
+	if renderHTML {
+		for i, p := range page.Posts {
+			if page.Posts[i], err = renderPost(ctx, p); err != nil {
+				acontext.GetLogger(ctx).Error(err)
+				ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+				return
+			}
+		}
+	}
+
+	if err := v1.ServeJSON(w, page.Posts); err != nil {
 		acontext.GetLogger(ctx).Errorf("error sending posts json: %v", err)
 	}
 }
+
+func searchPostsQuery(r *http.Request) (*queries.SearchPosts, error) {
+	params := r.URL.Query()
+	q := &queries.SearchPosts{
+		Tags:     params["tag"],
+		Sort:     params.Get("sort"),
+		Cursor:   params.Get("cursor"),
+		Text:     strings.TrimSpace(params.Get("q")),
+		Category: params.Get("category"),
+	}
+
+	if q.Sort != "" && !storage.ValidPostSort(storage.PostSort(q.Sort)) {
+		return nil, fmt.Errorf("unsupported sort order %q", q.Sort)
+	} else if q.Sort != "" && q.Text != "" {
+		return nil, fmt.Errorf("sort can't be combined with q, results are ordered by relevance")
+	}
+
+	if author := params.Get("author"); author != "" {
+		q.Author = &v1.Author{User: author}
+	}
+
+	if raw := params.Get("published"); raw != "" {
+		published, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("published must be true or false")
+		}
+
+		q.Published = &published
+	}
+
+	var err error
+	if q.CreatedAfter, err = int64Param(params, "created_after"); err != nil {
+		return nil, err
+	}
+
+	if q.CreatedBefore, err = int64Param(params, "created_before"); err != nil {
+		return nil, err
+	}
+
+	limit, err := int64Param(params, "limit")
+	if err != nil {
+		return nil, err
+	} else if limit < 0 {
+		return nil, fmt.Errorf("limit must be a positive number")
+	}
+
+	q.Limit = int(limit)
+	return q, nil
+}
+
+func int64Param(params url.Values, name string) (int64, error) {
+	raw := params.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+
+	return v, nil
+}
+
+// renderParam reads the render query parameter. html is the only format
+// posts can be rendered to.
+func renderParam(r *http.Request) (bool, error) {
+	switch format := r.URL.Query().Get("render"); format {
+	case "":
+		return false, nil
+	case "html":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported render format %q", format)
+	}
+}
+
+// renderPost returns a copy of the post with each content block turned into
+// sanitized html. The output is cached against the post's latest revision.
+func renderPost(ctx context.Context, p *v1.Post) (*v1.Post, error) {
+	urls := getURLBuilder(ctx)
+	renders := getApp(ctx).renders
+	key := ""
+	revisionRaw, err := cqrs.DispatchQuery(ctx, &queries.FindRevision{Post: p.Name})
+	if err != nil && err != storage.ErrNotFound {
+		return nil, err
+	} else if revision, ok := revisionRaw.(*v1.Revision); ok && revision != nil {
+		// links are absolute so the base url is part of the key too
+		base, err := urls.BuildBaseURL()
+		if err != nil {
+			return nil, err
+		}
+
+		key = fmt.Sprintf("%s@%d %s", p.Name, revision.Number, base)
+	}
+
+	cp := *p
+	if key != "" {
+		if content, ok := renders.Get(key, p.Content); ok {
+			cp.Content = content
+			return &cp, nil
+		}
+	}
+
+	cp.Content = make([]*v1.Content, 0, len(p.Content))
+	for _, c := range p.Content {
+		out := render.HTML(c, urls)
+		if c.Blob != "" {
+			if out, err = render.Media(c, urls); err != nil {
+				return nil, err
+			}
+		}
+
+		cp.Content = append(cp.Content, &v1.Content{
+			Type: string(v1.ContentHtml),
+			Data: out,
+			Rel:  c.Rel,
+		})
+	}
+
+	if key != "" {
+		renders.Add(key, p.Content, cp.Content)
+	}
+
+	return &cp, nil
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+// purgedClaimStates are the states purged when none are asked for.
+var purgedClaimStates = []v1.ClaimState{
+	v1.ClaimExpired,
+	v1.ClaimRedeemed,
+	v1.ClaimRevoked,
+}
+
+func claimsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &claimHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetClaims", h.GetClaims),
+		"POST":   withTraceLogging("CreateClaim", h.CreateClaim),
+		"DELETE": withTraceLogging("PurgeClaims", h.PurgeClaims),
+	}
+}
+
+func claimDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &claimHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetClaim", h.GetClaim),
+		"DELETE": withTraceLogging("RevokeClaim", h.RevokeClaim),
+	}
+}
+
+type claimHandler struct {
+	*appRequestContext
+}
+
+func (ctx *claimHandler) GetClaims(w http.ResponseWriter, r *http.Request) {
+	q := &queries.SearchClaims{}
+	params := r.URL.Query()
+	if raw := params.Get("state"); raw != "" {
+		q.State = v1.ClaimState(raw)
+		if !q.State.Valid() {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.InvalidField(v1.ErrorCodeParameterInvalid, "state", "unknown claim state %q", raw))
+			return
+		}
+	}
+
+	if raw := params.Get("resource_type"); raw != "" {
+		q.ResourceType = v1.ResourceType(raw)
+		if q.ResourceType != v1.PostResource && q.ResourceType != v1.UserResource {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.InvalidField(v1.ErrorCodeParameterInvalid, "resource_type", "claims can't be for %q resources", raw))
+			return
+		}
+	}
+
+	claims, err := cqrs.DispatchQuery(ctx, q)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, claims); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending claims json: %v", err)
+	}
+}
+
+func (ctx *claimHandler) CreateClaim(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	in := &v1.Claim{}
+	if err = json.Unmarshal(body, in); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return
+	}
+
+	// everything else about the claim is up to the server
+	claim := &v1.Claim{
+		ResourceType: in.ResourceType,
+		Email:        in.Email,
+		Role:         in.Role,
+		MaxUses:      in.MaxUses,
+		Expires:      in.Expires,
+		User:         getUser(ctx).Name,
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.CreateClaim{Claim: claim}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "claim", claim.Code).Infof("%s claim issued", claim.ResourceType)
+	if err := v1.ServeJSON(w, claim); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending claim json: %v", err)
+	}
+}
+
+func (ctx *claimHandler) PurgeClaims(w http.ResponseWriter, r *http.Request) {
+	cmd := &commands.PurgeClaims{States: purgedClaimStates}
+	if raw := r.URL.Query()["state"]; len(raw) > 0 {
+		cmd.States = make([]v1.ClaimState, 0, len(raw))
+		for _, s := range raw {
+			state := v1.ClaimState(s)
+			if !state.Valid() || state == v1.ClaimActive {
+				ctx.Context = acontext.AppendError(ctx.Context, v1.InvalidField(v1.ErrorCodeParameterInvalid, "state", "can't purge %q claims", s))
+				return
+			}
+
+			cmd.States = append(cmd.States, state)
+		}
+	}
+
+	if err := cqrs.DispatchCommand(ctx, cmd); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLogger(ctx).Infof("%d claims purged", len(cmd.Purged))
+	if err := v1.ServeJSON(w, cmd.Purged); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending claims json: %v", err)
+	}
+}
+
+func (ctx *claimHandler) GetClaim(w http.ResponseWriter, r *http.Request) {
+	code := acontext.GetStringValue(ctx, "vars.claim_code")
+	claimRaw, err := cqrs.DispatchQuery(ctx, &queries.FindClaim{Code: code})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	claim, ok := claimRaw.(*v1.Claim)
+	if !ok || claim == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	}
+
+	if err := v1.ServeJSON(w, claim); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending claim json: %v", err)
+	}
+}
+
+func (ctx *claimHandler) RevokeClaim(w http.ResponseWriter, r *http.Request) {
+	code := acontext.GetStringValue(ctx, "vars.claim_code")
+	if err := cqrs.DispatchCommand(ctx, &commands.RevokeClaim{Code: code}); err != nil {
+		if err == storage.ErrNotFound {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		} else {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "claim", code).Info("claim revoked")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// releaseClaim gives back the redemption of the request's claim, if it has
+// one, when the resource it was redeemed for couldn't be stored.
+func releaseClaim(ctx *appRequestContext) {
+	claim, ok := ctx.Value("claim").(*v1.Claim)
+	if !ok {
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.ReleaseClaim{Code: claim.Code}); err != nil {
+		acontext.GetLoggerWithField(ctx, "claim", claim.Code).Errorf("error releasing claim: %v", err)
+	}
+}
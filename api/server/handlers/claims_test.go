@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+func TestFailedCreateKeepsClaim(t *testing.T) {
+	app, setupManager := newStoredApp(t)
+	first := setupManager.AddFirstUserClaim(app)
+
+	// a failed first user leaves the first user claim usable
+	if rec := serve(app, "POST", "/v1/users", "", `{"name":"Not A Name","password":"secret"}`, "TINKERSNEST-CLAIM", first.Code); rec.Code != http.StatusBadRequest {
+		t.Fatalf("creating an invalid first user = %d %s, want 400", rec.Code, rec.Body)
+	}
+
+	if rec := serve(app, "POST", "/v1/users", "", `{"name":"admin","password":"secret"}`, "TINKERSNEST-CLAIM", first.Code); rec.Code != http.StatusOK {
+		t.Fatalf("creating the first user = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	token := login(t, app, "admin", "secret")
+	rec := serve(app, "POST", "/v1/claims", token, `{"resource_type":"user"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("creating a claim = %d %s", rec.Code, rec.Body)
+	}
+
+	claim := &v1.Claim{}
+	if err := json.Unmarshal(rec.Body.Bytes(), claim); err != nil {
+		t.Fatal(err)
+	}
+
+	failures := []struct {
+		reason string
+		body   string
+		status int
+		code   string
+	}{
+		{"bad body", `{"name":`, http.StatusBadRequest, "BODY_INVALID"},
+		{"invalid name", `{"name":"Not A Name","password":"secret"}`, http.StatusBadRequest, "NAME_INVALID"},
+		{"name taken", `{"name":"admin","password":"secret"}`, http.StatusConflict, "NAME_TAKEN"},
+	}
+
+	for _, f := range failures {
+		rec := serve(app, "POST", "/v1/users", "", f.body, "TINKERSNEST-CLAIM", claim.Code)
+		if rec.Code != f.status || !hasErrorCode(rec.Body.Bytes(), f.code) {
+			t.Errorf("%s: create = %d %s, want %d %s", f.reason, rec.Code, rec.Body, f.status, f.code)
+		}
+
+		if uses := claimUses(t, app, token, claim.Code); uses != 0 {
+			t.Errorf("%s: claim has %d uses after the create failed, want 0", f.reason, uses)
+		}
+	}
+
+	if rec := serve(app, "POST", "/v1/users", "", `{"name":"bob","password":"secret"}`, "TINKERSNEST-CLAIM", claim.Code); rec.Code != http.StatusOK {
+		t.Fatalf("creating a user with the claim = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	if uses := claimUses(t, app, token, claim.Code); uses != 1 {
+		t.Errorf("claim has %d uses after the user was created, want 1", uses)
+	}
+}
+
+func claimUses(t *testing.T, app *App, token string, code string) int {
+	rec := serve(app, "GET", fmt.Sprintf("/v1/claims/%s", code), token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("getting claim %s = %d %s", code, rec.Code, rec.Body)
+	}
+
+	claim := &v1.Claim{}
+	if err := json.Unmarshal(rec.Body.Bytes(), claim); err != nil {
+		t.Fatal(err)
+	}
+
+	return claim.Uses
+}
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+func collectionsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &collectionHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetCollections", h.GetCollections),
+	}
+}
+
+func collectionDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &collectionHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetCollection", h.GetCollection),
+		"PUT":    withTraceLogging("UpdateCollection", h.UpdateCollection),
+		"DELETE": withTraceLogging("DeleteCollection", h.DeleteCollection),
+	}
+}
+
+func collectionItemsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &collectionHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":  withTraceLogging("GetItems", h.GetItems),
+		"POST": withTraceLogging("CreateItem", h.CreateItem),
+	}
+}
+
+func collectionItemDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &collectionHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetItem", h.GetItem),
+		"PUT":    withTraceLogging("UpdateItem", h.UpdateItem),
+		"DELETE": withTraceLogging("DeleteItem", h.DeleteItem),
+	}
+}
+
+type collectionHandler struct {
+	*appRequestContext
+}
+
+func (ctx *collectionHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := cqrs.DispatchQuery(ctx, &queries.SearchCollections{})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, collections); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending collections json: %v", err)
+	}
+}
+
+func (ctx *collectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	c := ctx.findCollection()
+	if c == nil {
+		return
+	}
+
+	if err := v1.ServeJSON(w, c); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending collection json: %v", err)
+	}
+}
+
+func (ctx *collectionHandler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	name := acontext.GetStringValue(ctx, "vars.collection")
+	if !ctx.checkNotConfigured(name) {
+		return
+	}
+
+	c := &v1.Collection{}
+	if !ctx.readBody(r, c) {
+		return
+	}
+
+	c.Name = name
+	c.Configured = false
+	if c.Fields == nil {
+		c.Fields = []*v1.Field{}
+	}
+
+	if err := c.Validate(); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreCollection{Collection: c}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "collection.name", name).Infof("collection %q stored", name)
+	if err := v1.ServeJSON(w, c); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending collection json: %v", err)
+	}
+}
+
+func (ctx *collectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	name := acontext.GetStringValue(ctx, "vars.collection")
+	if !ctx.checkNotConfigured(name) {
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteCollection{Name: name}); err != nil {
+		if err == storage.ErrNotFound {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		} else {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "collection.name", name).Infof("collection %q deleted", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *collectionHandler) GetItems(w http.ResponseWriter, r *http.Request) {
+	c := ctx.findCollection()
+	if c == nil {
+		return
+	}
+
+	q := &queries.SearchItems{Collection: c.Name}
+	if getUser(ctx) == nil {
+		published := true
+		q.Published = &published
+	}
+
+	items, err := cqrs.DispatchQuery(ctx, q)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, items); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending items json: %v", err)
+	}
+}
+
+func (ctx *collectionHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
+	c := ctx.findCollection()
+	if c == nil {
+		return
+	}
+
+	item := &v1.Item{}
+	if !ctx.readBody(r, item) {
+		return
+	}
+
+	item.Collection = c.Name
+	var err error
+	if item.Name == "" && item.Title == "" {
+		err = v1.InvalidField(v1.ErrorCodeFieldRequired, "title", "name or title is required")
+	} else {
+		err = c.ValidateItem(item)
+	}
+
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreItem{New: true, Item: item}); err != nil {
+		if err == storage.ErrConflict {
+			err = v1.InvalidField(v1.ErrorCodeNameTaken, "name", "%s already has an item named %q", c.Name, item.Name)
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, err)
+		} else {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "item.name", item.Name).Infof("%s item %q created", c.Name, item.Name)
+	if err := v1.ServeJSON(w, item); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending item json: %v", err)
+	}
+}
+
+func (ctx *collectionHandler) GetItem(w http.ResponseWriter, r *http.Request) {
+	c := ctx.findCollection()
+	if c == nil {
+		return
+	}
+
+	item := ctx.findItem(c)
+	if item == nil {
+		return
+	}
+
+	if err := v1.ServeJSON(w, item); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending item json: %v", err)
+	}
+}
+
+func (ctx *collectionHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	c := ctx.findCollection()
+	if c == nil {
+		return
+	}
+
+	existing := ctx.findItem(c)
+	if existing == nil {
+		return
+	}
+
+	item := &v1.Item{}
+	if !ctx.readBody(r, item) {
+		return
+	}
+
+	item.Collection = existing.Collection
+	item.Name = existing.Name
+	item.Created = existing.Created
+	if err := c.ValidateItem(item); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreItem{New: false, Item: item}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "item.name", item.Name).Infof("%s item %q updated", c.Name, item.Name)
+	if err := v1.ServeJSON(w, item); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending item json: %v", err)
+	}
+}
+
+func (ctx *collectionHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	c := ctx.findCollection()
+	if c == nil {
+		return
+	}
+
+	name := acontext.GetStringValue(ctx, "vars.item_name")
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteItem{Collection: c.Name, Name: name}); err != nil {
+		if err == storage.ErrNotFound {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		} else {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "item.name", name).Infof("%s item %q deleted", c.Name, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *collectionHandler) findCollection() *v1.Collection {
+	c, err := cqrs.DispatchQuery(ctx, &queries.FindCollection{
+		Name: acontext.GetStringValue(ctx, "vars.collection"),
+	})
+
+	if err == storage.ErrNotFound {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	} else if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	return c.(*v1.Collection)
+}
+
+// findItem loads the item named in the route. Unpublished items don't exist
+// as far as anonymous readers are concerned.
+func (ctx *collectionHandler) findItem(c *v1.Collection) *v1.Item {
+	i, err := cqrs.DispatchQuery(ctx, &queries.FindItem{
+		Collection: c.Name,
+		Name:       acontext.GetStringValue(ctx, "vars.item_name"),
+	})
+
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	item, ok := i.(*v1.Item)
+	if !ok || item == nil || (!item.Publish && getUser(ctx) == nil) {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	}
+
+	return item
+}
+
+// checkNotConfigured appends an error and returns false if the collection is
+// declared in the configuration, since those can't be changed at runtime.
+func (ctx *collectionHandler) checkNotConfigured(name string) bool {
+	c, err := cqrs.DispatchQuery(ctx, &queries.FindCollection{Name: name})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	if c, ok := c.(*v1.Collection); ok && c != nil && c.Configured {
+		err := v1.InvalidField(v1.ErrorCodeParameterInvalid, "collection", "collection %q is declared in the configuration", name)
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return false
+	}
+
+	return true
+}
+
+func (ctx *collectionHandler) readBody(r *http.Request, v interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+const (
+	maxCommentLength     = 10000
+	maxCommentNameLength = 100
+)
+
+func postCommentsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &commentHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":  withTraceLogging("GetComments", h.GetComments),
+		"POST": withTraceLogging("CreateComment", h.CreateComment),
+	}
+}
+
+func postCommentDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &commentHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetComment", h.GetComment),
+		"DELETE": withTraceLogging("DeleteComment", h.DeleteComment),
+	}
+}
+
+func commentsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &commentHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetCommentQueue", h.GetCommentQueue),
+	}
+}
+
+func commentModerationDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &commentHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"POST": withTraceLogging("ModerateComments", h.ModerateComments),
+	}
+}
+
+type commentHandler struct {
+	*appRequestContext
+}
+
+// canModerate reports whether the current user sees every comment rather
+// than only the approved ones.
+func (ctx *commentHandler) canModerate() bool {
+	u := getUser(ctx)
+	return u != nil && u.Can(v1.PermissionModerateComments)
+}
+
+func (ctx *commentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	post := ctx.findPost()
+	if post == nil {
+		return
+	}
+
+	state := v1.CommentApproved
+	if ctx.canModerate() {
+		var ok bool
+		if state, ok = ctx.stateParam(r, ""); !ok {
+			return
+		}
+	}
+
+	ctx.serveComments(w, &queries.SearchComments{Post: post.Name, State: state})
+}
+
+func (ctx *commentHandler) GetCommentQueue(w http.ResponseWriter, r *http.Request) {
+	state, ok := ctx.stateParam(r, v1.CommentPending)
+	if !ok {
+		return
+	}
+
+	ctx.serveComments(w, &queries.SearchComments{State: state})
+}
+
+func (ctx *commentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	post := ctx.findPost()
+	if post == nil {
+		return
+	}
+
+	in := &v1.Comment{}
+	if !ctx.readBody(r, in) {
+		return
+	}
+
+	c := &v1.Comment{
+		Post:   post.Name,
+		Parent: in.Parent,
+		Body:   strings.TrimSpace(in.Body),
+		State:  v1.CommentPending,
+	}
+
+	if user := getUser(ctx); user != nil {
+		// comments from people with an account skip the queue
+		c.State = v1.CommentApproved
+		c.Author = &v1.CommentAuthor{Name: user.FullName, User: user.Name}
+		if c.Author.Name == "" {
+			c.Author.Name = user.Name
+		}
+	} else if in.Author != nil {
+		c.Author = &v1.CommentAuthor{
+			Name:  strings.TrimSpace(in.Author.Name),
+			Email: strings.TrimSpace(in.Author.Email),
+		}
+	}
+
+	if err := validateComment(c); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if c.Parent != "" && !ctx.checkParent(c) {
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreComment{New: true, Comment: c}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "comment.id", c.ID).Infof("comment on %q created as %s", post.Name, c.State)
+	ctx.serveComment(w, c)
+}
+
+func (ctx *commentHandler) GetComment(w http.ResponseWriter, r *http.Request) {
+	if ctx.findPost() == nil {
+		return
+	}
+
+	c := ctx.findComment()
+	if c == nil {
+		return
+	}
+
+	ctx.serveComment(w, c)
+}
+
+func (ctx *commentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	if ctx.findPost() == nil {
+		return
+	}
+
+	c := ctx.findComment()
+	if c == nil {
+		return
+	}
+
+	user := getUser(ctx)
+	if !ctx.canModerate() && (user == nil || c.Author == nil || c.Author.User != user.Name) {
+		acontext.GetLogger(ctx).Errorf("user not allowed to delete comment %q", c.ID)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeForbidden)
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteComment{ID: c.ID}); err != nil {
+		if err == storage.ErrNotFound {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		} else {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "comment.id", c.ID).Info("comment deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *commentHandler) ModerateComments(w http.ResponseWriter, r *http.Request) {
+	m := &v1.CommentModeration{}
+	if !ctx.readBody(r, m) {
+		return
+	}
+
+	var err error
+	switch {
+	case len(m.IDs) == 0:
+		err = fmt.Errorf("ids is required")
+	case !m.State.Valid():
+		err = fmt.Errorf("invalid comment state %q", m.State)
+	}
+
+	if err == nil {
+		err = cqrs.DispatchCommand(ctx, &commands.ModerateComments{IDs: m.IDs, State: m.State})
+		if err == storage.ErrNotFound {
+			err = fmt.Errorf("ids include an unknown comment")
+		} else if err != nil {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+			return
+		}
+	}
+
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	acontext.GetLogger(ctx).Infof("%d comment(s) moved to %s", len(m.IDs), m.State)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findPost loads the post named in the route. Anonymous readers can't see or
+// comment on drafts.
+func (ctx *commentHandler) findPost() *v1.Post {
+	postName := acontext.GetStringValue(ctx, "vars.post_name")
+	postRaw, err := cqrs.DispatchQuery(ctx, &queries.FindPost{
+		Name: postName,
+	})
+
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	post, ok := postRaw.(*v1.Post)
+	if !ok || post == nil || (!post.Publish && getUser(ctx) == nil) {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	}
+
+	return post
+}
+
+// findComment loads the comment named in the route. Comments awaiting
+// moderation or marked as spam only exist for moderators.
+func (ctx *commentHandler) findComment() *v1.Comment {
+	c, err := cqrs.DispatchQuery(ctx, &queries.FindComment{
+		ID: acontext.GetStringValue(ctx, "vars.comment_id"),
+	})
+
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	comment, ok := c.(*v1.Comment)
+	if !ok || comment == nil || comment.Post != acontext.GetStringValue(ctx, "vars.post_name") ||
+		(comment.State != v1.CommentApproved && !ctx.canModerate()) {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	}
+
+	return comment
+}
+
+// checkParent appends an error and returns false unless the reply answers a
+// comment on the same post that the author is able to see.
+func (ctx *commentHandler) checkParent(c *v1.Comment) bool {
+	parentRaw, err := cqrs.DispatchQuery(ctx, &queries.FindComment{ID: c.Parent})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	parent, ok := parentRaw.(*v1.Comment)
+	if !ok || parent == nil || parent.Post != c.Post ||
+		(parent.State != v1.CommentApproved && !ctx.canModerate()) {
+		err = fmt.Errorf("unknown parent comment %q", c.Parent)
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return false
+	}
+
+	return true
+}
+
+func validateComment(c *v1.Comment) error {
+	switch {
+	case c.Body == "":
+		return fmt.Errorf("body is required")
+	case len(c.Body) > maxCommentLength:
+		return fmt.Errorf("body can't be longer than %d bytes", maxCommentLength)
+	case c.Author == nil || c.Author.Name == "":
+		return fmt.Errorf("author name is required")
+	case len(c.Author.Name) > maxCommentNameLength:
+		return fmt.Errorf("author name can't be longer than %d bytes", maxCommentNameLength)
+	case c.Author.Email != "" && !strings.Contains(c.Author.Email, "@"):
+		return fmt.Errorf("invalid author email %q", c.Author.Email)
+	}
+
+	return nil
+}
+
+// stateParam reads the state query parameter, falling back to def when it's
+// missing.
+func (ctx *commentHandler) stateParam(r *http.Request, def v1.CommentState) (v1.CommentState, bool) {
+	raw := r.URL.Query().Get("state")
+	if raw == "" {
+		return def, true
+	}
+
+	state := v1.CommentState(raw)
+	if !state.Valid() {
+		err := fmt.Errorf("invalid comment state %q", raw)
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return "", false
+	}
+
+	return state, true
+}
+
+func (ctx *commentHandler) serveComments(w http.ResponseWriter, q *queries.SearchComments) {
+	result, err := cqrs.DispatchQuery(ctx, q)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	comments := result.([]*v1.Comment)
+	for _, c := range comments {
+		ctx.hideEmail(c)
+	}
+
+	if err := v1.ServeJSON(w, comments); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending comments json: %v", err)
+	}
+}
+
+func (ctx *commentHandler) serveComment(w http.ResponseWriter, c *v1.Comment) {
+	ctx.hideEmail(c)
+	if err := v1.ServeJSON(w, c); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending comment json: %v", err)
+	}
+}
+
+// hideEmail keeps the addresses of commenters away from everyone but the
+// moderators.
+func (ctx *commentHandler) hideEmail(c *v1.Comment) {
+	if c.Author != nil && !ctx.canModerate() {
+		author := *c.Author
+		author.Email = ""
+		c.Author = &author
+	}
+}
+
+func (ctx *commentHandler) readBody(r *http.Request, v interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielkrainas/gobag/context"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// etag is the entity tag of a post or user at the version.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// representationETag is the entity tag of another representation of a post
+// or user at the version than the one that is stored, such as its rendered
+// HTML, so that caches can't mix the two up.
+func representationETag(version int64, representation string) string {
+	return `"` + strconv.FormatInt(version, 10) + "-" + representation + `"`
+}
+
+// setETag sets the ETag header for the version, or for the representation of
+// it if one is given, and reports whether the request's If-None-Match
+// already has it, in which case the response has been answered with 304 Not
+// Modified.
+func setETag(w http.ResponseWriter, r *http.Request, version int64, representation string) bool {
+	tag := etag(version)
+	if representation != "" {
+		tag = representationETag(version, representation)
+	}
+
+	w.Header().Set("ETag", tag)
+	if inm := r.Header.Get("If-None-Match"); inm != "" && matchETag(inm, tag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	return false
+}
+
+// checkIfMatch reports whether the request's If-Match header, if it has one,
+// matches the version of the resource it changes. It appends
+// PRECONDITION_FAILED to the context when it doesn't.
+func checkIfMatch(ctx *appRequestContext, r *http.Request, version int64) bool {
+	im := r.Header.Get("If-Match")
+	if im == "" || matchETag(im, etag(version), false) {
+		return true
+	}
+
+	acontext.GetLogger(ctx).Warnf("if-match %s doesn't match version %d", im, version)
+	ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodePreconditionFailed)
+	return false
+}
+
+// matchETag reports whether the tag is in the comma separated list of an
+// If-Match or If-None-Match header, or the list is "*". If-None-Match
+// compares weakly and ignores the W/ prefix, while If-Match never matches
+// weak tags.
+func matchETag(header string, tag string, weak bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" {
+			return true
+		}
+
+		if strings.HasPrefix(t, "W/") {
+			if !weak {
+				continue
+			}
+
+			t = t[2:]
+		}
+
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
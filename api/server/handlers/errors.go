@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/danielkrainas/gobag/api/errcode"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// apiError passes errors that are already API errors, like the validation
+// errors of actions, through as they are and reports anything else as
+// UNKNOWN.
+func apiError(err error) error {
+	if _, ok := err.(errcode.ErrorCoder); ok {
+		return err
+	}
+
+	return errcode.ErrorCodeUnknown.WithDetail(err)
+}
+
+// bodyError reports a request body that couldn't be decoded as BODY_INVALID,
+// naming the field when the error was a value of the wrong type.
+func bodyError(err error) error {
+	field := ""
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		field = e.Field
+	}
+
+	return v1.InvalidField(v1.ErrorCodeBodyInvalid, field, "%v", err)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/queries"
+)
+
+const (
+	// keepAliveInterval is how often an idle stream is sent a comment so
+	// proxies don't close it.
+	keepAliveInterval = 15 * time.Second
+
+	streamBuffer = 64
+)
+
+func eventsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &eventHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetEvents", h.GetEvents),
+	}
+}
+
+type eventHandler struct {
+	*appRequestContext
+}
+
+// eventFilter matches events about a kind of resource and, if name is set,
+// only the one resource with that name or id.
+type eventFilter struct {
+	kind string
+	name string
+}
+
+func (ctx *eventHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseEventFilters(r.URL.Query()["resource"])
+	if err != nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := fmt.Errorf("response writer can't stream events")
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	sub, missed := getApp(ctx).events.SubscribeSince(r.Header.Get("Last-Event-ID"), streamBuffer)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// keeps nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	acontext.GetLogger(ctx).Infof("event stream opened, replaying %d events", len(missed))
+	for _, e := range missed {
+		if !ctx.send(w, e, filters) {
+			return
+		}
+	}
+
+	flusher.Flush()
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				// the client can reconnect with the last id it saw to
+				// pick up where it left off
+				acontext.GetLogger(ctx).Warn("event stream fell behind, closing it")
+				return
+			}
+
+			if !ctx.send(w, e, filters) {
+				return
+			}
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+
+		case <-r.Context().Done():
+			acontext.GetLogger(ctx).Info("event stream closed")
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// send writes the event to the stream if it passes the filters and the
+// current user may see it. It only returns false if the write failed.
+func (ctx *eventHandler) send(w http.ResponseWriter, e *v1.Event, filters []*eventFilter) bool {
+	if !matchEvent(e, filters) {
+		return true
+	}
+
+	e = ctx.visible(e)
+	if e == nil {
+		return true
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		acontext.GetLogger(ctx).Errorf("error encoding %s event: %v", e.Type, err)
+		return true
+	}
+
+	if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending %s event: %v", e.Type, err)
+		return false
+	}
+
+	return true
+}
+
+// visible returns a copy of the event with only what the current user may
+// see, or nil if they may not see it at all. Anonymous readers see changes
+// to published posts, public profiles and approved comments on published
+// posts, but no deletions of posts or comments since those can't be told
+// apart from deletions of drafts and held comments.
+func (ctx *eventHandler) visible(e *v1.Event) *v1.Event {
+	u := getUser(ctx)
+	cp := *e
+	switch e.Type.Kind() {
+	case "post":
+		if u != nil && u.Can(v1.PermissionReadPosts) {
+			return &cp
+		}
+
+		if p, ok := e.Data.(*v1.Post); !ok || !p.Publish {
+			return nil
+		}
+
+	case "user":
+		if u != nil && u.Can(v1.PermissionReadUsers) {
+			return &cp
+		}
+
+		if user, ok := e.Data.(*v1.User); ok {
+			cp.Data = user.Profile()
+		}
+
+	case "comment":
+		if u != nil && u.Can(v1.PermissionModerateComments) {
+			return &cp
+		}
+
+		c, ok := e.Data.(*v1.Comment)
+		if !ok || c.State != v1.CommentApproved || !ctx.postVisible(c.Post) {
+			return nil
+		}
+
+		if c.Author != nil {
+			comment := *c
+			author := *c.Author
+			author.Email = ""
+			comment.Author = &author
+			cp.Data = &comment
+		}
+
+	case "media":
+		if u == nil || !u.Can(v1.PermissionReadMedia) {
+			return nil
+		}
+
+	default:
+		return nil
+	}
+
+	return &cp
+}
+
+// postVisible reports whether the current user may see the named post.
+func (ctx *eventHandler) postVisible(name string) bool {
+	u := getUser(ctx)
+	if u != nil && u.Can(v1.PermissionReadPosts) {
+		return true
+	}
+
+	postRaw, err := cqrs.DispatchQuery(ctx, &queries.FindPost{Name: name})
+	if err != nil {
+		return false
+	}
+
+	post, ok := postRaw.(*v1.Post)
+	return ok && post != nil && post.Publish
+}
+
+func matchEvent(e *v1.Event, filters []*eventFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	kind := e.Type.Kind()
+	for _, f := range filters {
+		if f.kind == kind && (f.name == "" || f.name == e.Resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseEventFilters(values []string) ([]*eventFilter, error) {
+	filters := make([]*eventFilter, 0, len(values))
+	for _, v := range values {
+		parts := strings.SplitN(v, "/", 2)
+		f := &eventFilter{kind: parts[0]}
+		if len(parts) > 1 {
+			if parts[1] == "" {
+				return nil, fmt.Errorf("resource %q has an empty name", v)
+			}
+
+			f.name = parts[1]
+		}
+
+		switch f.kind {
+		case "post", "user", "comment", "media":
+		default:
+			return nil, fmt.Errorf("unknown resource kind %q", f.kind)
+		}
+
+		filters = append(filters, f)
+	}
+
+	return filters, nil
+}
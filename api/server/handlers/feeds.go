@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+	"github.com/gorilla/mux"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/feed"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+const (
+	defaultFeedTitle = "tinkersnest"
+	defaultFeedLimit = 20
+)
+
+func feedDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &feedHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetFeed", h.GetFeed),
+	}
+}
+
+type feedHandler struct {
+	*appRequestContext
+}
+
+func (ctx *feedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	format := feed.Format(acontext.GetStringValue(ctx, "vars.format"))
+	if !format.Valid() {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	}
+
+	config := getApp(ctx).config.Feed
+	published := true
+	q := &queries.SearchPosts{
+		Published: &published,
+		Sort:      string(storage.SortCreatedDesc),
+		Limit:     config.Limit,
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultFeedLimit
+	}
+
+	f := &feed.Feed{
+		Title:       config.Title,
+		Description: config.Description,
+	}
+
+	if f.Title == "" {
+		f.Title = defaultFeedTitle
+	}
+
+	var err error
+	urls := getURLBuilder(ctx)
+	switch mux.CurrentRoute(r).GetName() {
+	case v1.RouteNameUserFeed:
+		userName := acontext.GetStringValue(ctx, "vars.user_name")
+		q.Author = &v1.Author{User: userName}
+		f.Title = fmt.Sprintf("%s: posts by %s", f.Title, userName)
+		if f.Link, err = urls.BuildPostsByUser(userName); err == nil {
+			f.FeedURL, err = urls.BuildUserFeed(userName, string(format))
+		}
+
+	case v1.RouteNameTagFeed:
+		tag := acontext.GetStringValue(ctx, "vars.tag")
+		q.Tags = []string{tag}
+		f.Title = fmt.Sprintf("%s: posts tagged %s", f.Title, tag)
+		if f.Link, err = urls.BuildBlog(url.Values{"tag": []string{tag}}); err == nil {
+			f.FeedURL, err = urls.BuildTagFeed(tag, string(format))
+		}
+
+	default:
+		if f.Link, err = urls.BuildBlog(); err == nil {
+			f.FeedURL, err = urls.BuildBlogFeed(string(format))
+		}
+	}
+
+	if err != nil {
+		acontext.GetLogger(ctx).Errorf("error building feed urls: %v", err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	pageRaw, err := cqrs.DispatchQuery(ctx, q)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	page := pageRaw.(*storage.PostPage)
+	for _, p := range page.Posts {
+		item, err := feedItem(ctx, p)
+		if err != nil {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+			return
+		}
+
+		if item.Published.After(f.Updated) {
+			f.Updated = item.Published
+		}
+
+		f.Items = append(f.Items, item)
+	}
+
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	if err := feed.Encode(w, format, f); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending %s feed: %v", format, err)
+	}
+}
+
+// feedItem turns a published post into a feed item with its content
+// rendered to html.
+func feedItem(ctx *feedHandler, p *v1.Post) (*feed.Item, error) {
+	link, err := getURLBuilder(ctx).BuildPostByName(p.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := renderPost(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var content bytes.Buffer
+	for _, c := range rendered.Content {
+		content.Write(c.Data)
+	}
+
+	// scheduled posts went live when their embargo lifted, not when they
+	// were written
+	published := p.Created
+	if p.PublishAt > 0 {
+		published = p.PublishAt
+	}
+
+	item := &feed.Item{
+		ID:          link,
+		Title:       p.Title,
+		Link:        link,
+		Published:   time.Unix(published, 0),
+		Tags:        p.Tags,
+		ContentHTML: content.String(),
+	}
+
+	if p.Author != nil {
+		item.Author = p.Author.Name
+		if item.Author == "" {
+			item.Author = p.Author.User
+		}
+	}
+
+	return item, nil
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/auth"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+func userKeysDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &apiKeyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":  withTraceLogging("GetUserKeys", h.GetUserKeys),
+		"POST": withTraceLogging("CreateUserKey", h.CreateUserKey),
+	}
+}
+
+func userKeyByIDDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &apiKeyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"DELETE": withTraceLogging("RevokeUserKey", h.RevokeUserKey),
+	}
+}
+
+type apiKeyHandler struct {
+	*appRequestContext
+}
+
+// checkAccess reports whether the current user may manage the keys of the
+// named user, which is limited to their own keys unless they manage users.
+func (ctx *apiKeyHandler) checkAccess(userName string) bool {
+	current := getUser(ctx)
+	if current == nil || (current.Name != userName && !current.Can(v1.PermissionManageUsers)) {
+		acontext.GetLogger(ctx).Errorf("user not allowed to manage keys of %q", userName)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeForbidden)
+		return false
+	}
+
+	return true
+}
+
+func (ctx *apiKeyHandler) GetUserKeys(w http.ResponseWriter, r *http.Request) {
+	userName := acontext.GetStringValue(ctx, "vars.user_name")
+	if !ctx.checkAccess(userName) {
+		return
+	}
+
+	keys, err := cqrs.DispatchQuery(ctx, &queries.SearchAPIKeys{User: userName})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, keys); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending keys json: %v", err)
+	}
+}
+
+func (ctx *apiKeyHandler) CreateUserKey(w http.ResponseWriter, r *http.Request) {
+	userName := acontext.GetStringValue(ctx, "vars.user_name")
+	if !ctx.checkAccess(userName) {
+		return
+	}
+
+	if getAPIKey(ctx) != nil {
+		// a key could otherwise be used to mint keys with wider scopes
+		acontext.GetLogger(ctx).Error("api keys cannot create other api keys")
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeForbidden)
+		return
+	}
+
+	userRaw, err := cqrs.DispatchQuery(ctx, &queries.FindUser{Name: userName})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	owner, ok := userRaw.(*v1.User)
+	if !ok || owner == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	k := &v1.APIKey{}
+	if err = json.Unmarshal(body, k); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return
+	}
+
+	if err := validateAPIKey(k, owner); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return
+	}
+
+	id, key, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	k.ID = id
+	k.User = owner.Name
+	k.Created = time.Now().Unix()
+	k.Hash = hash
+	k.Key = ""
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreAPIKey{New: true, Key: k}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "key.id", id).Infof("api key %q created for %q", k.Name, owner.Name)
+	created := *k
+	created.Key = key
+	if err := v1.ServeJSON(w, &created); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending key json: %v", err)
+	}
+}
+
+func (ctx *apiKeyHandler) RevokeUserKey(w http.ResponseWriter, r *http.Request) {
+	userName := acontext.GetStringValue(ctx, "vars.user_name")
+	keyID := acontext.GetStringValue(ctx, "vars.key_id")
+	if !ctx.checkAccess(userName) {
+		return
+	}
+
+	keyRaw, err := cqrs.DispatchQuery(ctx, &queries.FindAPIKey{ID: keyID})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	k, ok := keyRaw.(*v1.APIKey)
+	if !ok || k == nil || k.User != userName {
+		acontext.GetLogger(ctx).Error("api key not found")
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteAPIKey{ID: k.ID}); err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateAPIKey checks a requested key. Its scopes must be permissions the
+// owner's roles already grant.
+func validateAPIKey(k *v1.APIKey, owner *v1.User) error {
+	if k.Name == "" {
+		return v1.InvalidField(v1.ErrorCodeFieldRequired, "name", "an api key needs a name")
+	}
+
+	if len(k.Scopes) == 0 {
+		return v1.InvalidField(v1.ErrorCodeFieldRequired, "scopes", "at least one scope is required")
+	}
+
+	for i, p := range k.Scopes {
+		field := fmt.Sprintf("scopes[%d]", i)
+		if !p.Valid() {
+			return v1.InvalidField(v1.ErrorCodeFieldInvalid, field, "unknown scope %q", p)
+		} else if !owner.Can(p) {
+			return v1.InvalidField(v1.ErrorCodeFieldInvalid, field, "%q does not have the %q permission", owner.Name, p)
+		}
+	}
+
+	if k.Expires != 0 && k.Expires <= time.Now().Unix() {
+		return v1.InvalidField(v1.ErrorCodeFieldInvalid, "expires", "must be in the future")
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/blobs"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+)
+
+const defaultMediaMaxSize = 32 << 20
+
+// defaultMediaTypes are the content types media can be uploaded with unless
+// others are configured. Nothing a browser would run as a page, like HTML or
+// SVG, is among them.
+var defaultMediaTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"video/mp4",
+	"video/webm",
+	"audio/mpeg",
+	"audio/ogg",
+	"audio/wave",
+	"application/pdf",
+	"application/zip",
+	"application/octet-stream",
+	"text/plain",
+}
+
+func mediaDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &mediaHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetAllMedia", h.GetAllMedia),
+	}
+}
+
+func mediaByNameDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &mediaHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("DownloadMedia", h.DownloadMedia),
+		"PUT":    withTraceLogging("UploadMedia", h.UploadMedia),
+		"DELETE": withTraceLogging("DeleteMedia", h.DeleteMedia),
+	}
+}
+
+func mediaMetaDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &mediaHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("InspectMedia", h.InspectMedia),
+	}
+}
+
+type mediaHandler struct {
+	*appRequestContext
+}
+
+func (ctx *mediaHandler) appendBlobError(err error) {
+	if err == blobs.ErrUnknown {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	} else if err == blobs.ErrInvalidName {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	acontext.GetLogger(ctx).Error(err)
+	ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+}
+
+func (ctx *mediaHandler) GetAllMedia(w http.ResponseWriter, r *http.Request) {
+	media, err := cqrs.DispatchQuery(ctx, &queries.SearchBlobs{})
+	if err != nil {
+		ctx.appendBlobError(err)
+		return
+	}
+
+	if err := v1.ServeJSON(w, media); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending media json: %v", err)
+	}
+}
+
+func (ctx *mediaHandler) InspectMedia(w http.ResponseWriter, r *http.Request) {
+	blobName := acontext.GetStringValue(ctx, "vars.blob_name")
+	blob, err := cqrs.DispatchQuery(ctx, &queries.FindBlob{Name: blobName})
+	if err != nil {
+		ctx.appendBlobError(err)
+		return
+	}
+
+	if err := v1.ServeJSON(w, blob); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending media json: %v", err)
+	}
+}
+
+func (ctx *mediaHandler) DownloadMedia(w http.ResponseWriter, r *http.Request) {
+	blobName := acontext.GetStringValue(ctx, "vars.blob_name")
+	blobRaw, err := cqrs.DispatchQuery(ctx, &queries.FindBlob{Name: blobName})
+	if err != nil {
+		ctx.appendBlobError(err)
+		return
+	}
+
+	rdRaw, err := cqrs.DispatchQuery(ctx, &queries.OpenBlob{Name: blobName})
+	if err != nil {
+		ctx.appendBlobError(err)
+		return
+	}
+
+	blob := blobRaw.(*blobs.Blob)
+	rd := rdRaw.(io.ReadCloser)
+	defer rd.Close()
+
+	// media uploaded before types were checked may have any type, so only
+	// the accepted ones are served as they are
+	contentType := blob.Meta[blobs.MetaContentType]
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !mediaTypeAllowed(ctx, mediaType) {
+		contentType = "application/octet-stream"
+		mediaType = contentType
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !strings.HasPrefix(mediaType, "image/") && !strings.HasPrefix(mediaType, "video/") {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": blobName}))
+	}
+
+	if size := blob.Meta[blobs.MetaSize]; size != "" {
+		w.Header().Set("Content-Length", size)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rd); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending media %q: %v", blobName, err)
+	}
+}
+
+func (ctx *mediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	blobName := acontext.GetStringValue(ctx, "vars.blob_name")
+	maxSize := getApp(ctx).config.Media.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMediaMaxSize
+	}
+
+	data := bufio.NewReader(http.MaxBytesReader(w, r.Body, maxSize))
+	contentType, err := uploadedMediaType(r.Header.Get("Content-Type"), data)
+	if err == nil && !mediaTypeAllowed(ctx, contentType) {
+		err = fmt.Errorf("media can't be %q", contentType)
+	}
+
+	if err != nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnsupportedMediaType.WithDetail(err))
+		return
+	}
+
+	blob := &blobs.Blob{
+		Name: blobName,
+		Meta: map[string]string{
+			blobs.MetaContentType: contentType,
+		},
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreBlob{Blob: blob, Data: data}); err != nil {
+		if _, ok := err.(*http.MaxBytesError); ok {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeMediaTooLarge.WithDetail(fmt.Sprintf("media can't be larger than %d bytes", maxSize)))
+			return
+		}
+
+		ctx.appendBlobError(err)
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "blob.name", blobName).Infof("media %q uploaded", blobName)
+	if location, err := getURLBuilder(ctx).BuildMediaByName(blobName); err == nil {
+		w.Header().Set("Location", location)
+	}
+
+	if err := v1.ServeJSON(w, blob); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending media json: %v", err)
+	}
+}
+
+func (ctx *mediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	blobName := acontext.GetStringValue(ctx, "vars.blob_name")
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteBlob{Name: blobName}); err != nil {
+		ctx.appendBlobError(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadedMediaType returns the media type of an upload, without parameters.
+// It's detected from the data when the request doesn't give a more specific
+// one than application/octet-stream.
+func uploadedMediaType(contentType string, data *bufio.Reader) (string, error) {
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return "", err
+		} else if mediaType != "application/octet-stream" {
+			return mediaType, nil
+		}
+	}
+
+	// a short upload is sniffed whole, and any read error is left for the
+	// store to report
+	head, _ := data.Peek(512)
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType, err
+}
+
+func mediaTypeAllowed(ctx context.Context, mediaType string) bool {
+	allowed := getApp(ctx).config.Media.Types
+	if len(allowed) == 0 {
+		allowed = defaultMediaTypes
+	}
+
+	for _, t := range allowed {
+		if strings.EqualFold(t, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func TestUploadMediaTypes(t *testing.T) {
+	app, setupManager := newStoredApp(t)
+	token := createAdmin(t, app, setupManager)
+
+	uploads := []struct {
+		name        string
+		contentType string
+		data        string
+		status      int
+		stored      string
+	}{
+		{"declared", "image/png; charset=binary", pngHeader, http.StatusOK, "image/png"},
+		{"sniffed", "", pngHeader, http.StatusOK, "image/png"},
+		{"sniffed-octet-stream", "application/octet-stream", "<html><script>alert(1)</script></html>", http.StatusUnsupportedMediaType, ""},
+		{"html", "text/html", "<p>hi</p>", http.StatusUnsupportedMediaType, ""},
+		{"svg", "image/svg+xml", "<svg onload=\"alert(1)\"/>", http.StatusUnsupportedMediaType, ""},
+		{"bad-type", "image/", pngHeader, http.StatusUnsupportedMediaType, ""},
+	}
+
+	for _, u := range uploads {
+		rec := serve(app, "PUT", "/v1/media/"+u.name, token, u.data, "Content-Type", u.contentType)
+		if rec.Code != u.status {
+			t.Errorf("%s: upload = %d %s, want %d", u.name, rec.Code, rec.Body, u.status)
+			continue
+		} else if u.status != http.StatusOK {
+			if rec := serve(app, "GET", "/v1/media/"+u.name, token, ""); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: refused upload was stored", u.name)
+			}
+
+			continue
+		}
+
+		rec = serve(app, "GET", "/v1/media/"+u.name, token, "")
+		if got := rec.Header().Get("Content-Type"); got != u.stored {
+			t.Errorf("%s: downloaded as %q, want %q", u.name, got, u.stored)
+		}
+	}
+}
+
+func TestUploadMediaTooLarge(t *testing.T) {
+	app, setupManager := newStoredApp(t)
+	token := createAdmin(t, app, setupManager)
+	app.config.Media.MaxSize = 1024
+
+	if rec := serve(app, "PUT", "/v1/media/small", token, strings.Repeat("a", 1024), "Content-Type", "text/plain"); rec.Code != http.StatusOK {
+		t.Errorf("upload at the limit = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	rec := serve(app, "PUT", "/v1/media/large", token, strings.Repeat("a", 1025), "Content-Type", "text/plain")
+	if rec.Code != http.StatusRequestEntityTooLarge || !hasErrorCode(rec.Body.Bytes(), "MEDIA_TOO_LARGE") {
+		t.Errorf("upload over the limit = %d %s, want 413 MEDIA_TOO_LARGE", rec.Code, rec.Body)
+	}
+
+	if rec := serve(app, "GET", "/v1/media/large", token, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("upload over the limit was stored")
+	}
+}
+
+func TestDownloadMediaHeaders(t *testing.T) {
+	app, setupManager := newStoredApp(t)
+	token := createAdmin(t, app, setupManager)
+
+	serve(app, "PUT", "/v1/media/photo", token, pngHeader, "Content-Type", "image/png")
+	serve(app, "PUT", "/v1/media/notes", token, "hello", "Content-Type", "text/plain")
+
+	// a type accepted before isn't served as it is once it's no longer accepted
+	serve(app, "PUT", "/v1/media/old", token, "<p>hi</p>", "Content-Type", "text/plain")
+	app.config.Media.Types = []string{"image/png"}
+
+	downloads := []struct {
+		name        string
+		contentType string
+		disposition string
+	}{
+		{"photo", "image/png", ""},
+		{"old", "application/octet-stream", `attachment; filename=old`},
+	}
+
+	for _, d := range downloads {
+		rec := serve(app, "GET", "/v1/media/"+d.name, token, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: download = %d %s", d.name, rec.Code, rec.Body)
+			continue
+		}
+
+		if got := rec.Header().Get("Content-Type"); got != d.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", d.name, got, d.contentType)
+		}
+
+		if got := rec.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("%s: X-Content-Type-Options = %q, want nosniff", d.name, got)
+		}
+
+		if got := rec.Header().Get("Content-Disposition"); got != d.disposition {
+			t.Errorf("%s: Content-Disposition = %q, want %q", d.name, got, d.disposition)
+		}
+	}
+
+	app.config.Media.Types = nil
+	rec := serve(app, "GET", "/v1/media/notes", token, "")
+	if got := rec.Header().Get("Content-Disposition"); got != "attachment; filename=notes" {
+		t.Errorf("text download has Content-Disposition %q, want an attachment", got)
+	}
+}
+
+func TestRenderedMediaIsPublic(t *testing.T) {
+	app, setupManager := newStoredApp(t)
+	token := createAdmin(t, app, setupManager)
+
+	if rec := serve(app, "PUT", "/v1/media/cat", token, pngHeader, "Content-Type", "image/png"); rec.Code != http.StatusOK {
+		t.Fatalf("uploading media = %d %s", rec.Code, rec.Body)
+	}
+
+	post := `{"name":"cat","title":"Cat","publish":true,"content":[{"type":"image/png","blob":"cat"}]}`
+	if rec := serve(app, "POST", "/v1/blog/posts", token, post); rec.Code != http.StatusOK {
+		t.Fatalf("creating a post = %d %s", rec.Code, rec.Body)
+	}
+
+	rec := serve(app, "GET", "/v1/blog/posts/cat?render=html", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rendering the post anonymously = %d %s", rec.Code, rec.Body)
+	}
+
+	rendered := &v1.Post{}
+	if err := json.Unmarshal(rec.Body.Bytes(), rendered); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rendered post links to the media by its full URL
+	var m []string
+	for _, c := range rendered.Content {
+		if m = regexp.MustCompile(`<img src="([^"]+)"`).FindStringSubmatch(string(c.Data)); m != nil {
+			break
+		}
+	}
+
+	if m == nil {
+		t.Fatalf("rendered post has no image: %s", rec.Body)
+	}
+
+	src, err := url.Parse(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec = serve(app, "GET", src.Path, "", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != pngHeader {
+		t.Errorf("downloading %s anonymously = %d %q, want the image", src, rec.Code, rec.Body)
+	}
+
+	if rec := serve(app, "GET", "/v1/media", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("listing media anonymously = %d %s, want 401", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/openapi"
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+func openAPIDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &openAPIHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetOpenAPI", h.GetOpenAPI),
+	}
+}
+
+type openAPIHandler struct {
+	*appRequestContext
+}
+
+func (ctx *openAPIHandler) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc := openapi.Generate(v1.API.Routes, acontext.GetVersion(ctx))
+	if err := v1.ServeJSON(w, doc); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending openapi json: %v", err)
+	}
+}
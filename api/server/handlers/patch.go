@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/danielkrainas/gobag/context"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/patch"
+)
+
+// readPatch applies the patch in the request body to the JSON encoding of
+// current and decodes the result into patched. The Content-Type picks JSON
+// Merge Patch or JSON Patch. It returns false after appending the
+// appropriate error if the patch can't be applied or the result isn't a
+// valid resource. A failed JSON Patch test is a failed precondition.
+func readPatch(ctx *appRequestContext, w http.ResponseWriter, r *http.Request, current interface{}, patched interface{}) bool {
+	typ, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (typ != patch.MergePatchType && typ != patch.JSONPatchType) {
+		w.Header().Set("Accept-Patch", strings.Join(patch.Types, ", "))
+		err = fmt.Errorf("patches must be %s", strings.Join(patch.Types, " or "))
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeUnsupportedMediaType.WithDetail(err))
+		return false
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	result, err := patch.Apply(typ, doc, body)
+	if err == patch.ErrTestFailed {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodePreconditionFailed.WithDetail(err))
+		return false
+	} else if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return false
+	}
+
+	// members the resource doesn't have are most likely typos
+	d := json.NewDecoder(bytes.NewReader(result))
+	d.DisallowUnknownFields()
+	if err := d.Decode(patched); err != nil {
+		acontext.GetLogger(ctx).Errorf("patched resource is invalid: %v", err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+func postRevisionsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &blogHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetPostRevisions", h.GetPostRevisions),
+	}
+}
+
+func postRevisionDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &blogHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetPostRevision", h.GetPostRevision),
+	}
+}
+
+func postRevisionRestoreDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &blogHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"POST": withTraceLogging("RestorePostRevision", h.RestorePostRevision),
+	}
+}
+
+func postDiffDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &blogHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("DiffPostRevisions", h.DiffPostRevisions),
+	}
+}
+
+func (ctx *blogHandler) GetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	postName := acontext.GetStringValue(ctx, "vars.post_name")
+	revisions, err := cqrs.DispatchQuery(ctx, &queries.SearchRevisions{Post: postName})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if list, ok := revisions.([]*v1.Revision); !ok || len(list) == 0 {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	}
+
+	if err := v1.ServeJSON(w, revisions); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending revisions json: %v", err)
+	}
+}
+
+func (ctx *blogHandler) GetPostRevision(w http.ResponseWriter, r *http.Request) {
+	postName := acontext.GetStringValue(ctx, "vars.post_name")
+	revision := ctx.findRevision(postName)
+	if revision == nil {
+		return
+	}
+
+	if err := v1.ServeJSON(w, revision); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending revision json: %v", err)
+	}
+}
+
+// RestorePostRevision makes an older revision the current version of the
+// post. The history is kept, restoring adds a new revision. The post keeps
+// its current author, since handing it to someone else takes a patch and the
+// permission to do so.
+func (ctx *blogHandler) RestorePostRevision(w http.ResponseWriter, r *http.Request) {
+	post := ctx.findModifiablePost()
+	if post == nil || !checkIfMatch(ctx.appRequestContext, r, post.Version) {
+		return
+	}
+
+	revision := ctx.findRevision(post.Name)
+	if revision == nil {
+		return
+	}
+
+	restored := revision.Post
+	restored.Name = post.Name
+	restored.Created = post.Created
+	restored.Version = post.Version
+	restored.Author = post.Author
+	err := cqrs.DispatchCommand(ctx, &commands.StorePost{
+		New:          false,
+		Post:         restored,
+		User:         getUserName(ctx),
+		RestoredFrom: revision.Number,
+	})
+
+	if err == storage.ErrConflict {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodePreconditionFailed)
+		return
+	} else if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "post.name", post.Name).Infof("blog post %q restored to revision %d", post.Name, revision.Number)
+	w.Header().Set("ETag", etag(restored.Version))
+	if err := v1.ServeJSON(w, restored); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending post json: %v", err)
+	}
+}
+
+func (ctx *blogHandler) DiffPostRevisions(w http.ResponseWriter, r *http.Request) {
+	postName := acontext.GetStringValue(ctx, "vars.post_name")
+	q := &queries.DiffRevisions{Post: postName}
+	var err error
+	if q.From, err = revisionNumberParam(r, "from"); err == nil {
+		q.To, err = revisionNumberParam(r, "to")
+	}
+
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	d, err := cqrs.DispatchQuery(ctx, q)
+	if err == storage.ErrNotFound {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	} else if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, d); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending diff json: %v", err)
+	}
+}
+
+// findRevision loads the revision named in the route. It returns nil after
+// appending the appropriate error if the revision doesn't exist.
+func (ctx *blogHandler) findRevision(postName string) *v1.Revision {
+	number, err := strconv.Atoi(acontext.GetStringValue(ctx, "vars.revision"))
+	if err != nil || number < 1 {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	}
+
+	revisionRaw, err := cqrs.DispatchQuery(ctx, &queries.FindRevision{
+		Post:   postName,
+		Number: number,
+	})
+
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	revision, ok := revisionRaw.(*v1.Revision)
+	if !ok || revision == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	}
+
+	return revision
+}
+
+func revisionNumberParam(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a revision number", name)
+	}
+
+	return n, nil
+}
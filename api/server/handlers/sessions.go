@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+func userSessionsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &sessionHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetUserSessions", h.GetUserSessions),
+		"DELETE": withTraceLogging("RevokeUserSessions", h.RevokeUserSessions),
+	}
+}
+
+func userSessionByIDDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &sessionHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"DELETE": withTraceLogging("RevokeSession", h.RevokeSession),
+	}
+}
+
+type sessionHandler struct {
+	*appRequestContext
+}
+
+func (ctx *sessionHandler) GetUserSessions(w http.ResponseWriter, r *http.Request) {
+	userName := acontext.GetStringValue(ctx, "vars.user_name")
+	sessions, err := cqrs.DispatchQuery(ctx, &queries.SearchSessions{User: userName})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, sessions); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending sessions json: %v", err)
+	}
+}
+
+func (ctx *sessionHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	userName := acontext.GetStringValue(ctx, "vars.user_name")
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteUserSessions{User: userName}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLogger(ctx).Infof("revoked all sessions of %q", userName)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *sessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userName := acontext.GetStringValue(ctx, "vars.user_name")
+	sessionID := acontext.GetStringValue(ctx, "vars.session_id")
+	sessionRaw, err := cqrs.DispatchQuery(ctx, &queries.FindSession{ID: sessionID})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	session, ok := sessionRaw.(*v1.Session)
+	if !ok || session == nil || session.User != userName {
+		acontext.GetLogger(ctx).Error("session not found")
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteSession{ID: session.ID}); err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,549 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+func tagsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetTags", h.GetTags),
+	}
+}
+
+func tagBySlugDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetTag", h.GetTag),
+		"PUT":    withTraceLogging("UpdateTag", h.UpdateTag),
+		"DELETE": withTraceLogging("DeleteTag", h.DeleteTag),
+	}
+}
+
+func tagPostsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &blogHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetTagPosts", h.GetAllPosts),
+	}
+}
+
+func tagRenameDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"POST": withTraceLogging("RenameTag", h.RenameTag),
+	}
+}
+
+func tagMergeDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"POST": withTraceLogging("MergeTags", h.MergeTags),
+	}
+}
+
+func categoriesDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetCategories", h.GetCategories),
+	}
+}
+
+func categoryBySlugDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetCategory", h.GetCategory),
+		"PUT":    withTraceLogging("UpdateCategory", h.UpdateCategory),
+		"DELETE": withTraceLogging("DeleteCategory", h.DeleteCategory),
+	}
+}
+
+func categoryPostsDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &blogHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetCategoryPosts", h.GetAllPosts),
+	}
+}
+
+func categoryRenameDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"POST": withTraceLogging("RenameCategory", h.RenameCategory),
+	}
+}
+
+func categoryMergeDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &taxonomyHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"POST": withTraceLogging("MergeCategories", h.MergeCategories),
+	}
+}
+
+type taxonomyHandler struct {
+	*appRequestContext
+}
+
+// published keeps the post counts shown to anonymous readers down to the
+// posts they can see.
+func (ctx *taxonomyHandler) published() *bool {
+	if getUser(ctx) != nil {
+		return nil
+	}
+
+	published := true
+	return &published
+}
+
+func (ctx *taxonomyHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := cqrs.DispatchQuery(ctx, &queries.SearchTags{Published: ctx.published()})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, tags); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending tags json: %v", err)
+	}
+}
+
+func (ctx *taxonomyHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	ctx.serveTag(w, acontext.GetStringValue(ctx, "vars.tag"))
+}
+
+func (ctx *taxonomyHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	slug := acontext.GetStringValue(ctx, "vars.tag")
+	t := &v1.Tag{}
+	if !ctx.readBody(r, t) {
+		return
+	}
+
+	t.Slug = slug
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreTag{Tag: t}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "tag.slug", slug).Infof("tag %q updated", slug)
+	ctx.serveTag(w, slug)
+}
+
+func (ctx *taxonomyHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	slug := acontext.GetStringValue(ctx, "vars.tag")
+	if ctx.findTag(slug) == nil {
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteTag{Slug: slug, User: getUserName(ctx)}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "tag.slug", slug).Infof("tag %q deleted", slug)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *taxonomyHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	from := acontext.GetStringValue(ctx, "vars.tag")
+	to, ok := ctx.readTarget(r, from)
+	if !ok || ctx.findTag(from) == nil {
+		return
+	}
+
+	if exists, ok := ctx.tagExists(to); !ok {
+		return
+	} else if exists {
+		err := fmt.Errorf("tag %q already exists, merge into it instead", to)
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.RenameTag{From: from, To: to, User: getUserName(ctx)}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "tag.slug", from).Infof("tag %q renamed to %q", from, to)
+	ctx.serveTag(w, to)
+}
+
+func (ctx *taxonomyHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	from := acontext.GetStringValue(ctx, "vars.tag")
+	into, ok := ctx.readTarget(r, from)
+	if !ok || ctx.findTag(from) == nil {
+		return
+	}
+
+	if exists, ok := ctx.tagExists(into); !ok {
+		return
+	} else if !exists {
+		err := fmt.Errorf("tag %q doesn't exist, rename to it instead", into)
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.MergeTags{From: from, Into: into, User: getUserName(ctx)}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "tag.slug", from).Infof("tag %q merged into %q", from, into)
+	ctx.serveTag(w, into)
+}
+
+func (ctx *taxonomyHandler) serveTag(w http.ResponseWriter, slug string) {
+	t := ctx.findTag(slug)
+	if t == nil {
+		return
+	}
+
+	if err := v1.ServeJSON(w, t); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending tag json: %v", err)
+	}
+}
+
+// findTag returns nil after appending the appropriate error if the tag isn't
+// stored or carried by any post.
+func (ctx *taxonomyHandler) findTag(slug string) *v1.Tag {
+	t, err := cqrs.DispatchQuery(ctx, &queries.FindTag{Slug: slug, Published: ctx.published()})
+	if err == storage.ErrNotFound {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	} else if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	return t.(*v1.Tag)
+}
+
+func (ctx *taxonomyHandler) tagExists(slug string) (exists bool, ok bool) {
+	_, err := cqrs.DispatchQuery(ctx, &queries.FindTag{Slug: slug})
+	if err == storage.ErrNotFound {
+		return false, true
+	} else if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false, false
+	}
+
+	return true, true
+}
+
+func (ctx *taxonomyHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := cqrs.DispatchQuery(ctx, &queries.SearchCategories{Published: ctx.published()})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, categories); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending categories json: %v", err)
+	}
+}
+
+func (ctx *taxonomyHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	ctx.serveCategory(w, acontext.GetStringValue(ctx, "vars.category"))
+}
+
+func (ctx *taxonomyHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	slug := acontext.GetStringValue(ctx, "vars.category")
+	c := &v1.Category{}
+	if !ctx.readBody(r, c) {
+		return
+	}
+
+	c.Slug = slug
+	all := loadCategories(ctx.appRequestContext)
+	if all == nil {
+		return
+	}
+
+	if err := validateCategoryParent(all, c); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreCategory{Category: c}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "category.slug", slug).Infof("category %q updated", slug)
+	ctx.serveCategory(w, slug)
+}
+
+func (ctx *taxonomyHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	slug := acontext.GetStringValue(ctx, "vars.category")
+	if ctx.findCategory(slug) == nil {
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteCategory{Slug: slug, User: getUserName(ctx)}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "category.slug", slug).Infof("category %q deleted", slug)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *taxonomyHandler) RenameCategory(w http.ResponseWriter, r *http.Request) {
+	from := acontext.GetStringValue(ctx, "vars.category")
+	to, ok := ctx.readTarget(r, from)
+	if !ok {
+		return
+	}
+
+	all := loadCategories(ctx.appRequestContext)
+	if all == nil {
+		return
+	} else if findCategory(all, from) == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	} else if findCategory(all, to) != nil {
+		err := fmt.Errorf("category %q already exists, merge into it instead", to)
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.RenameCategory{From: from, To: to, User: getUserName(ctx)}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "category.slug", from).Infof("category %q renamed to %q", from, to)
+	ctx.serveCategory(w, to)
+}
+
+func (ctx *taxonomyHandler) MergeCategories(w http.ResponseWriter, r *http.Request) {
+	from := acontext.GetStringValue(ctx, "vars.category")
+	into, ok := ctx.readTarget(r, from)
+	if !ok {
+		return
+	}
+
+	all := loadCategories(ctx.appRequestContext)
+	if all == nil {
+		return
+	} else if findCategory(all, from) == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return
+	}
+
+	var err error
+	if findCategory(all, into) == nil {
+		err = fmt.Errorf("category %q doesn't exist, rename to it instead", into)
+	} else if hasTerm(storage.Subcategories(all, from), into) {
+		err = fmt.Errorf("can't merge %q into its own subcategory %q", from, into)
+	}
+
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.MergeCategories{From: from, Into: into, User: getUserName(ctx)}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "category.slug", from).Infof("category %q merged into %q", from, into)
+	ctx.serveCategory(w, into)
+}
+
+func (ctx *taxonomyHandler) serveCategory(w http.ResponseWriter, slug string) {
+	c := ctx.findCategory(slug)
+	if c == nil {
+		return
+	}
+
+	if err := v1.ServeJSON(w, c); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending category json: %v", err)
+	}
+}
+
+func (ctx *taxonomyHandler) findCategory(slug string) *v1.Category {
+	c, err := cqrs.DispatchQuery(ctx, &queries.FindCategory{Slug: slug, Published: ctx.published()})
+	if err == storage.ErrNotFound {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	} else if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	return c.(*v1.Category)
+}
+
+// loadCategories returns nil after appending the error if the categories
+// can't be read.
+func loadCategories(ctx *appRequestContext) []*v1.Category {
+	all, err := cqrs.DispatchQuery(ctx, &queries.SearchCategories{})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	return all.([]*v1.Category)
+}
+
+// checkPostCategories appends an error and returns false unless every
+// category the post is filed under exists.
+func checkPostCategories(ctx *appRequestContext, p *v1.Post) bool {
+	if len(p.Categories) == 0 {
+		return true
+	}
+
+	all := loadCategories(ctx)
+	if all == nil {
+		return false
+	}
+
+	for i, slug := range p.Categories {
+		if findCategory(all, slug) == nil {
+			err := v1.InvalidField(v1.ErrorCodeFieldInvalid, fmt.Sprintf("categories[%d]", i), "unknown category %q", slug)
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, err)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ctx *taxonomyHandler) readBody(r *http.Request, v interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return false
+	}
+
+	return true
+}
+
+// readTarget reads the slug a tag or category is being renamed or merged to.
+func (ctx *taxonomyHandler) readTarget(r *http.Request, from string) (string, bool) {
+	target := &v1.TermTarget{}
+	if !ctx.readBody(r, target) {
+		return "", false
+	}
+
+	var err error
+	switch {
+	case target.To == "":
+		err = fmt.Errorf("to is required")
+	case strings.Contains(target.To, "/"):
+		err = fmt.Errorf("to can't contain a slash")
+	case target.To == from:
+		err = fmt.Errorf("to must be different from %q", from)
+	}
+
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return "", false
+	}
+
+	return target.To, true
+}
+
+// validateCategoryParent makes sure the parent exists and that the category
+// isn't being moved below itself.
+func validateCategoryParent(all []*v1.Category, c *v1.Category) error {
+	if c.Parent == "" {
+		return nil
+	} else if findCategory(all, c.Parent) == nil {
+		return fmt.Errorf("unknown parent category %q", c.Parent)
+	} else if hasTerm(storage.Subcategories(all, c.Slug), c.Parent) {
+		return fmt.Errorf("category %q can't be its own ancestor", c.Slug)
+	}
+
+	return nil
+}
+
+func findCategory(all []*v1.Category, slug string) *v1.Category {
+	for _, c := range all {
+		if c.Slug == slug {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func hasTerm(terms []string, term string) bool {
+	for _, t := range terms {
+		if t == term {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,12 +1,11 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
-	"github.com/danielkrainas/gobag/api/errcode"
 	"github.com/danielkrainas/gobag/context"
 	"github.com/danielkrainas/gobag/decouple/cqrs"
 	"github.com/gorilla/handlers"
@@ -18,9 +17,9 @@ import (
 	"github.com/danielkrainas/tinkersnest/storage"
 )
 
-func userRegistryDispatcher(ctx context.Context, r *http.Request) http.Handler {
+func userRegistryDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
 	h := &userHandler{
-		Context: ctx,
+		appRequestContext: ctx,
 	}
 
 	return handlers.MethodHandler{
@@ -29,51 +28,75 @@ func userRegistryDispatcher(ctx context.Context, r *http.Request) http.Handler {
 	}
 }
 
-func userByNameDispatcher(ctx context.Context, r *http.Request) http.Handler {
+func userByNameDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
 	h := &userHandler{
-		Context: ctx,
+		appRequestContext: ctx,
 	}
 
 	return handlers.MethodHandler{
 		"GET":    withTraceLogging("GetUser", h.GetUser),
 		"DELETE": withTraceLogging("DeleteUser", h.DeleteUser),
 		"PUT":    withTraceLogging("UpdateUser", h.UpdateUser),
+		"PATCH":  withTraceLogging("PatchUser", h.PatchUser),
 	}
 }
 
 type userHandler struct {
-	context.Context
+	*appRequestContext
 }
 
-func (ctx *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+// findModifiableUser loads the user named in the route if the current user
+// may change it, which users can only do to themselves unless they manage
+// users. It returns nil after appending the appropriate error otherwise.
+func (ctx *userHandler) findModifiableUser() *v1.User {
 	userName := acontext.GetStringValue(ctx, "vars.user_name")
+	current := getUser(ctx)
+	if current == nil || (current.Name != userName && !current.Can(v1.PermissionManageUsers)) {
+		acontext.GetLogger(ctx).Errorf("user not allowed to modify user %q", userName)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeForbidden)
+		return nil
+	}
+
 	userRaw, err := cqrs.DispatchQuery(ctx, &queries.FindUser{
 		Name: userName,
 	})
 
-	if err != nil {
+	if err != nil && err != storage.ErrNotFound {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
-		return
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	user, ok := userRaw.(*v1.User)
+	if !ok || user == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
 	}
 
-	if userRaw == nil {
-		ctx.Context = acontext.AppendError(ctx, v1.ErrorCodeResourceUnknown)
+	return user
+}
+
+func (ctx *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	user := ctx.findModifiableUser()
+	if user == nil || !checkIfMatch(ctx.appRequestContext, r, user.Version) {
 		return
 	}
 
-	user := userRaw.(*v1.User)
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
 	u := &v1.User{}
 	if err = json.Unmarshal(body, u); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return
+	}
+
+	if u.Roles != nil && !ctx.checkRoleChange(user, u.Roles) {
 		return
 	}
 
@@ -89,28 +112,108 @@ func (ctx *userHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		user.FullName = u.FullName
 	}
 
+	if u.Roles != nil {
+		user.Roles = u.Roles
+	}
+
 	if err := cqrs.DispatchCommand(ctx, &commands.StoreUser{New: false, User: user}); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+		if err == storage.ErrConflict {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodePreconditionFailed)
+		} else {
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
 		return
 	}
 
+	w.Header().Set("ETag", etag(user.Version))
 	if err := v1.ServeJSON(w, user); err != nil {
 		acontext.GetLogger(ctx).Errorf("error sending user json: %v", err)
 	}
 }
 
+// PatchUser applies a JSON Merge Patch or JSON Patch to the user. Setting a
+// password changes it, the name can't be changed and only admins can change
+// roles, like with PUT.
+func (ctx *userHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	user := ctx.findModifiableUser()
+	if user == nil || !checkIfMatch(ctx.appRequestContext, r, user.Version) {
+		return
+	}
+
+	current := *user
+	current.Password = ""
+	u := &v1.User{}
+	if !readPatch(ctx.appRequestContext, w, r, &current, u) {
+		return
+	}
+
+	if u.Name != user.Name {
+		err := v1.InvalidField(v1.ErrorCodeFieldInvalid, "name", "name can't be changed")
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, err)
+		return
+	}
+
+	if !ctx.checkRoleChange(user, u.Roles) {
+		return
+	}
+
+	// the credentials aren't part of the JSON so they're carried over
+	u.Salt = user.Salt
+	u.HashedPassword = user.HashedPassword
+	u.Version = user.Version
+	if u.Password != "" {
+		u.HashedPassword = auth.HashPassword(u.Password, u.Salt)
+		u.Password = ""
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreUser{New: false, User: u}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		if err == storage.ErrConflict {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodePreconditionFailed)
+		} else {
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "user.name", u.Name).Infof("user %q patched", u.Name)
+	w.Header().Set("ETag", etag(u.Version))
+	if err := v1.ServeJSON(w, u); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending user json: %v", err)
+	}
+}
+
+// checkRoleChange makes sure only users who manage users give the user new
+// roles. Whether the roles exist is checked when the user is stored.
+func (ctx *userHandler) checkRoleChange(user *v1.User, roles []v1.Role) bool {
+	if sameRoles(roles, user.Roles) {
+		return true
+	}
+
+	if !getUser(ctx).Can(v1.PermissionManageUsers) {
+		acontext.GetLogger(ctx).Errorf("user not allowed to change roles of %q", user.Name)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeForbidden)
+		return false
+	}
+
+	return true
+}
+
 func (ctx *userHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userName := acontext.GetStringValue(ctx, "vars.user_name")
-	err := cqrs.DispatchCommand(ctx, &commands.DeleteUser{userName})
+	err := cqrs.DispatchCommand(ctx, &commands.DeleteUser{Name: userName})
 	if err != nil {
 		if err == storage.ErrNotFound {
 			acontext.GetLogger(ctx).Error("user not found")
-			ctx.Context = acontext.AppendError(ctx, v1.ErrorCodeResourceUnknown)
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
 			return
 		} else {
 			acontext.GetLogger(ctx).Error(err)
-			ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 			return
 		}
 	}
@@ -126,16 +229,30 @@ func (ctx *userHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
-	if user == nil {
-		ctx.Context = acontext.AppendError(ctx, v1.ErrorCodeResourceUnknown)
+	u, ok := user.(*v1.User)
+	if !ok || u == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
 		return
 	}
 
-	if err := v1.ServeJSON(w, user); err != nil {
+	var body interface{} = u
+	representation := ""
+	if getUser(ctx) == nil {
+		// anonymous readers only get the public profile
+		body = u.Profile()
+		representation = "profile"
+	}
+
+	w.Header().Add("Vary", "Authorization")
+	if setETag(w, r, u.Version, representation) {
+		return
+	}
+
+	if err := v1.ServeJSON(w, body); err != nil {
 		acontext.GetLogger(ctx).Errorf("error sending user json: %v", err)
 	}
 }
@@ -144,40 +261,60 @@ func (ctx *userHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
 	u := &v1.User{}
 	if err = json.Unmarshal(body, u); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
 		return
 	}
 
+	if claim, ok := ctx.Value("claim").(*v1.Claim); ok && claim.Email != "" {
+		// the claim was issued to someone in particular
+		if u.Email == "" {
+			u.Email = claim.Email
+		} else if !strings.EqualFold(u.Email, claim.Email) {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.InvalidField(v1.ErrorCodeClaimInvalid, "email", "claim was issued for another email"))
+			return
+		}
+	}
+
 	if u.Salt, err = auth.GenerateSalt(); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
-	u.HashedPassword = auth.HashPassword(u.Password, u.Salt)
-	if err := cqrs.DispatchCommand(ctx, &commands.StoreUser{New: true, User: u}); err != nil {
+	if u.Roles, err = ctx.newUserRoles(u.Roles); err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, err)
 		return
 	}
 
+	u.HashedPassword = auth.HashPassword(u.Password, u.Salt)
+	// the claim is redeemed first so that two requests can't both use its
+	// last redemption, and given back if the store fails
 	if claim, ok := ctx.Value("claim").(*v1.Claim); ok {
 		err := cqrs.DispatchCommand(ctx, &commands.RedeemClaim{Code: claim.Code})
 		if err != nil {
 			acontext.GetLogger(ctx).Error(err)
-			ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 			return
 		}
 	}
 
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreUser{New: true, User: u}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		releaseClaim(ctx.appRequestContext)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
 	acontext.GetLoggerWithField(ctx, "user.name", u.Name).Infof("user %q created", u.Name)
+	w.Header().Set("ETag", etag(u.Version))
 	if err := v1.ServeJSON(w, u); err != nil {
 		acontext.GetLogger(ctx).Errorf("error sending user json: %v", err)
 	}
@@ -187,7 +324,7 @@ func (ctx *userHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := cqrs.DispatchQuery(ctx, &queries.SearchUsers{})
 	if err != nil {
 		acontext.GetLogger(ctx).Error(err)
-		ctx.Context = acontext.AppendError(ctx.Context, errcode.ErrorCodeUnknown.WithDetail(err))
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
 		return
 	}
 
@@ -195,3 +332,49 @@ func (ctx *userHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		acontext.GetLogger(ctx).Errorf("error sending users json: %v", err)
 	}
 }
+
+// newUserRoles decides the roles of a user being created. The very first user
+// is always an admin and a claim issued with a role grants it, otherwise only
+// users that can manage users may pick roles and everyone else gets the
+// default role.
+func (ctx *userHandler) newUserRoles(requested []v1.Role) ([]v1.Role, error) {
+	countRaw, err := cqrs.DispatchQuery(ctx, &queries.CountUsers{})
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	if count, ok := countRaw.(int); ok && count == 0 {
+		return []v1.Role{v1.RoleAdmin}, nil
+	}
+
+	if claim, ok := ctx.Value("claim").(*v1.Claim); ok && claim.Role != "" {
+		granted := []v1.Role{claim.Role}
+		if len(requested) == 0 || sameRoles(requested, granted) {
+			return granted, nil
+		}
+	}
+
+	if len(requested) == 0 {
+		return []v1.Role{v1.DefaultRole}, nil
+	}
+
+	if current := getUser(ctx); current == nil || !current.Can(v1.PermissionManageUsers) {
+		return nil, v1.ErrorCodeForbidden
+	}
+
+	return requested, nil
+}
+
+func sameRoles(a []v1.Role, b []v1.Role) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
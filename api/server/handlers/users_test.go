@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/auth"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+)
+
+// storeLegacyUser stores a user the way they were before roles existed.
+func storeLegacyUser(t *testing.T, app *App, name string, roles ...v1.Role) {
+	salt, err := auth.GenerateSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := &v1.User{
+		Name:           name,
+		Password:       "secret",
+		Roles:          roles,
+		Salt:           salt,
+		HashedPassword: auth.HashPassword("secret", salt),
+	}
+
+	if err := cqrs.DispatchCommand(app, &commands.StoreUser{New: true, User: u}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func userRoles(t *testing.T, app *App, name string) []v1.Role {
+	u, err := cqrs.DispatchQuery(app, &queries.FindUser{Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u.(*v1.User).Roles
+}
+
+func TestBootstrapMakesOldestUserAdmin(t *testing.T) {
+	app, setupManager := newStoredApp(t)
+	storeLegacyUser(t, app, "zed")
+	storeLegacyUser(t, app, "amy")
+	if err := setupManager.Bootstrap(app); err != nil {
+		t.Fatal(err)
+	}
+
+	if roles := userRoles(t, app, "zed"); len(roles) != 1 || roles[0] != v1.RoleAdmin {
+		t.Fatalf("oldest user has roles %v, want admin", roles)
+	} else if roles := userRoles(t, app, "amy"); len(roles) != 0 {
+		t.Errorf("other user has roles %v, want none", roles)
+	}
+
+	// the new admin can manage the other users
+	token := login(t, app, "zed", "secret")
+	if rec := serve(app, "PUT", "/v1/users/amy", token, `{"roles":["editor"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("admin changing roles = %d %s", rec.Code, rec.Body)
+	}
+
+	if err := setupManager.Bootstrap(app); err != nil {
+		t.Fatal(err)
+	} else if roles := userRoles(t, app, "amy"); len(roles) != 1 || roles[0] != v1.RoleEditor {
+		t.Errorf("bootstrapping again changed roles to %v", roles)
+	}
+}
+
+func TestBootstrapKeepsRoles(t *testing.T) {
+	app, setupManager := newStoredApp(t)
+	storeLegacyUser(t, app, "old")
+	storeLegacyUser(t, app, "boss", v1.RoleAdmin)
+	if err := setupManager.Bootstrap(app); err != nil {
+		t.Fatal(err)
+	}
+
+	if roles := userRoles(t, app, "old"); len(roles) != 0 {
+		t.Errorf("oldest user was given %v although another user has roles", roles)
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/gorilla/handlers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+	"github.com/danielkrainas/tinkersnest/webhooks"
+)
+
+func webhooksDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &webhookHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":  withTraceLogging("GetWebhooks", h.GetWebhooks),
+		"POST": withTraceLogging("CreateWebhook", h.CreateWebhook),
+	}
+}
+
+func webhookDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &webhookHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET":    withTraceLogging("GetWebhook", h.GetWebhook),
+		"PUT":    withTraceLogging("UpdateWebhook", h.UpdateWebhook),
+		"DELETE": withTraceLogging("DeleteWebhook", h.DeleteWebhook),
+	}
+}
+
+func webhookDeliveriesDispatcher(ctx *appRequestContext, r *http.Request) http.Handler {
+	h := &webhookHandler{
+		appRequestContext: ctx,
+	}
+
+	return handlers.MethodHandler{
+		"GET": withTraceLogging("GetWebhookDeliveries", h.GetWebhookDeliveries),
+	}
+}
+
+type webhookHandler struct {
+	*appRequestContext
+}
+
+func (ctx *webhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooksRaw, err := cqrs.DispatchQuery(ctx, &queries.SearchWebhooks{})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	hooks, _ := hooksRaw.([]*v1.Webhook)
+	for _, h := range hooks {
+		h.Secret = ""
+	}
+
+	if err := v1.ServeJSON(w, hooks); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending webhooks json: %v", err)
+	}
+}
+
+func (ctx *webhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	h := &v1.Webhook{}
+	if !ctx.readBody(r, h) {
+		return
+	}
+
+	if err := validateWebhook(h); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	if h.Secret == "" {
+		secret, err := webhooks.GenerateSecret()
+		if err != nil {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+			return
+		}
+
+		h.Secret = secret
+	}
+
+	h.User = getUser(ctx).Name
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreWebhook{New: true, Webhook: h}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "webhook.id", h.ID).Infof("webhook for %s registered", h.URL)
+	if err := v1.ServeJSON(w, h); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending webhook json: %v", err)
+	}
+}
+
+func (ctx *webhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	h := ctx.findWebhook()
+	if h == nil {
+		return
+	}
+
+	h.Secret = ""
+	if err := v1.ServeJSON(w, h); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending webhook json: %v", err)
+	}
+}
+
+func (ctx *webhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	h := ctx.findWebhook()
+	if h == nil {
+		return
+	}
+
+	in := &v1.Webhook{}
+	if !ctx.readBody(r, in) {
+		return
+	}
+
+	if err := validateWebhook(in); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeParameterInvalid.WithDetail(err))
+		return
+	}
+
+	h.URL = in.URL
+	h.Events = in.Events
+	if in.Secret != "" {
+		h.Secret = in.Secret
+	}
+
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreWebhook{Webhook: h}); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "webhook.id", h.ID).Infof("webhook for %s updated", h.URL)
+	h.Secret = ""
+	if err := v1.ServeJSON(w, h); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending webhook json: %v", err)
+	}
+}
+
+func (ctx *webhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := acontext.GetStringValue(ctx, "vars.webhook_id")
+	if err := cqrs.DispatchCommand(ctx, &commands.DeleteWebhook{ID: id}); err != nil {
+		if err == storage.ErrNotFound {
+			ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		} else {
+			acontext.GetLogger(ctx).Error(err)
+			ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		}
+
+		return
+	}
+
+	acontext.GetLoggerWithField(ctx, "webhook.id", id).Info("webhook deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctx *webhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	h := ctx.findWebhook()
+	if h == nil {
+		return
+	}
+
+	deliveries, err := cqrs.DispatchQuery(ctx, &queries.SearchDeliveries{Webhook: h.ID})
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return
+	}
+
+	if err := v1.ServeJSON(w, deliveries); err != nil {
+		acontext.GetLogger(ctx).Errorf("error sending deliveries json: %v", err)
+	}
+}
+
+func (ctx *webhookHandler) findWebhook() *v1.Webhook {
+	id := acontext.GetStringValue(ctx, "vars.webhook_id")
+	hookRaw, err := cqrs.DispatchQuery(ctx, &queries.FindWebhook{ID: id})
+	if err != nil && err != storage.ErrNotFound {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return nil
+	}
+
+	h, ok := hookRaw.(*v1.Webhook)
+	if !ok || h == nil {
+		ctx.Context = acontext.AppendError(ctx.Context, v1.ErrorCodeResourceUnknown)
+		return nil
+	}
+
+	return h
+}
+
+func (ctx *webhookHandler) readBody(r *http.Request, v interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, apiError(err))
+		return false
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		acontext.GetLogger(ctx).Error(err)
+		ctx.Context = acontext.AppendError(ctx.Context, bodyError(err))
+		return false
+	}
+
+	return true
+}
+
+func validateWebhook(h *v1.Webhook) error {
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https url")
+	}
+
+	for _, e := range h.Events {
+		if !e.Valid() {
+			return fmt.Errorf("unknown event %q", e)
+		}
+	}
+
+	if h.Events == nil {
+		h.Events = []v1.EventType{}
+	}
+
+	return nil
+}
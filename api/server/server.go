@@ -16,9 +16,13 @@ import (
 
 	"github.com/danielkrainas/tinkersnest/actions"
 	"github.com/danielkrainas/tinkersnest/api/server/handlers"
+	"github.com/danielkrainas/tinkersnest/blobs/driver/loader"
 	"github.com/danielkrainas/tinkersnest/configuration"
+	"github.com/danielkrainas/tinkersnest/events"
+	"github.com/danielkrainas/tinkersnest/scheduler"
 	"github.com/danielkrainas/tinkersnest/setup"
 	"github.com/danielkrainas/tinkersnest/storage/loader"
+	"github.com/danielkrainas/tinkersnest/webhooks"
 )
 
 type Server struct {
@@ -29,6 +33,9 @@ type Server struct {
 	query   *cqrs.QueryDispatcher
 	command *cqrs.CommandDispatcher
 	setup   *setup.SetupManager
+	sched   *scheduler.Scheduler
+	events  *events.Bus
+	hooks   *webhooks.Dispatcher
 }
 
 func New(ctx context.Context, config *configuration.Config) (*Server, error) {
@@ -53,17 +60,21 @@ func New(ctx context.Context, config *configuration.Config) (*Server, error) {
 		},
 	}
 
+	bus := events.NewBus(events.ReplaySize)
 	command := &cqrs.CommandDispatcher{
 		Handlers: []cqrs.CommandHandler{
 			setupManager,
-			ap,
+			&events.Handler{
+				Bus:   bus,
+				Inner: ap,
+			},
 		},
 	}
 
 	ctx = cqrs.WithCommandDispatch(ctx, command)
 	ctx = cqrs.WithQueryDispatch(ctx, query)
 
-	app, err := handlers.NewApp(ctx, config)
+	app, err := handlers.NewApp(ctx, config, bus)
 	if err != nil {
 		return nil, fmt.Errorf("error creating server app: %v", err)
 	}
@@ -92,6 +103,9 @@ func New(ctx context.Context, config *configuration.Config) (*Server, error) {
 		query:   query,
 		command: command,
 		setup:   setupManager,
+		sched:   scheduler.New(config),
+		events:  bus,
+		hooks:   webhooks.New(config, bus),
 		server: &http.Server{
 			Addr:    config.HTTP.Addr,
 			Handler: n,
@@ -100,11 +114,15 @@ func New(ctx context.Context, config *configuration.Config) (*Server, error) {
 
 	log.Infof("using %q logging formatter", config.Log.Formatter)
 	storageloader.LogSummary(ctx, config)
+	blobsloader.LogSummary(ctx, config)
 
 	if err := setupManager.Bootstrap(ctx); err != nil {
 		return nil, err
 	}
 
+	s.sched.Start(ctx)
+	s.hooks.Start(ctx)
+
 	return s, nil
 }
 
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"net/mail"
+	"time"
+)
+
+// ClaimState tells whether a claim can still be redeemed, and if not, why.
+type ClaimState string
+
+var (
+	ClaimActive   ClaimState = "active"
+	ClaimExpired  ClaimState = "expired"
+	ClaimRedeemed ClaimState = "redeemed"
+	ClaimRevoked  ClaimState = "revoked"
+)
+
+func (s ClaimState) Valid() bool {
+	switch s {
+	case ClaimActive, ClaimExpired, ClaimRedeemed, ClaimRevoked:
+		return true
+	}
+
+	return false
+}
+
+type Claim struct {
+	Code         string       `json:"code"`
+	ResourceType ResourceType `json:"resource_type"`
+	// Email and Role are given to the user created with the claim, who can't
+	// pick another email or role.
+	Email string `json:"email,omitempty"`
+	Role  Role   `json:"role,omitempty"`
+	// MaxUses is how many times the claim can be redeemed. Claims without one
+	// can be redeemed once.
+	MaxUses int `json:"max_uses"`
+	Uses    int `json:"uses"`
+	// User is the admin that issued the claim.
+	User    string `json:"user,omitempty"`
+	Created int64  `json:"created"`
+	// Expires is the unix time the claim can no longer be redeemed after,
+	// if any.
+	Expires int64 `json:"expires,omitempty"`
+	// Redeemed is the last time the claim was redeemed.
+	Redeemed int64 `json:"redeemed"`
+	Revoked  int64 `json:"revoked,omitempty"`
+	// State is worked out again whenever the claim is read.
+	State ClaimState `json:"state,omitempty"`
+}
+
+// Validate checks that the claim has a code and is for a kind of resource
+// that can be claimed.
+func (c *Claim) Validate() error {
+	if c.Code == "" {
+		return InvalidField(ErrorCodeFieldRequired, "code", "code is required")
+	}
+
+	switch c.ResourceType {
+	case PostResource, UserResource:
+	default:
+		return InvalidField(ErrorCodeFieldInvalid, "resource_type", "claims can't be for %q resources", c.ResourceType)
+	}
+
+	if c.MaxUses < 0 {
+		return InvalidField(ErrorCodeFieldInvalid, "max_uses", "max_uses can't be negative")
+	} else if c.Expires < 0 {
+		return InvalidField(ErrorCodeFieldInvalid, "expires", "expires can't be negative")
+	}
+
+	if c.ResourceType != UserResource && (c.Email != "" || c.Role != "") {
+		return InvalidField(ErrorCodeFieldInvalid, "resource_type", "only user claims can have an email or role")
+	}
+
+	if c.Email != "" {
+		if addr, err := mail.ParseAddress(c.Email); err != nil || addr.Address != c.Email {
+			return InvalidField(ErrorCodeFieldInvalid, "email", "%q isn't an email address", c.Email)
+		}
+	}
+
+	if c.Role != "" && !c.Role.Valid() {
+		return InvalidField(ErrorCodeFieldInvalid, "role", "unknown role %q", c.Role)
+	}
+
+	return nil
+}
+
+// UsesLeft is how many more times the claim can be redeemed.
+func (c *Claim) UsesLeft() int {
+	max := c.MaxUses
+	if max == 0 {
+		max = 1
+	}
+
+	uses := c.Uses
+	if uses == 0 && c.Redeemed != 0 {
+		// redeemed before uses were counted
+		uses = 1
+	}
+
+	if uses >= max {
+		return 0
+	}
+
+	return max - uses
+}
+
+func (c *Claim) Expired() bool {
+	return c.Expires != 0 && c.Expires <= time.Now().Unix()
+}
+
+// CurrentState works out the state of the claim. A claim that was revoked
+// or used up stays that way once it expires.
+func (c *Claim) CurrentState() ClaimState {
+	switch {
+	case c.Revoked != 0:
+		return ClaimRevoked
+	case c.UsesLeft() == 0:
+		return ClaimRedeemed
+	case c.Expired():
+		return ClaimExpired
+	}
+
+	return ClaimActive
+}
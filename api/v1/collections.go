@@ -0,0 +1,201 @@
+package v1
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+var CollectionNameRegex = regexp.MustCompile(`[a-z0-9][a-z0-9_-]*`)
+
+var collectionNameRegex = regexp.MustCompile(`^` + CollectionNameRegex.String() + `$`)
+
+type FieldType string
+
+const (
+	FieldString  FieldType = "string"
+	FieldNumber  FieldType = "number"
+	FieldBoolean FieldType = "boolean"
+	// FieldTime holds epoch seconds. RFC 3339 times are accepted as well.
+	FieldTime FieldType = "time"
+	// FieldList holds a list of strings.
+	FieldList FieldType = "list"
+)
+
+func (t FieldType) Valid() bool {
+	switch t {
+	case FieldString, FieldNumber, FieldBoolean, FieldTime, FieldList:
+		return true
+	}
+
+	return false
+}
+
+type Field struct {
+	Name        string    `json:"name" yaml:"name"`
+	Type        FieldType `json:"type" yaml:"type"`
+	Required    bool      `json:"required,omitempty" yaml:"required"`
+	Description string    `json:"description,omitempty" yaml:"description"`
+}
+
+// Collection is a user-defined content type such as pages, projects or
+// recipes. Its fields declare the schema every item in it must follow.
+type Collection struct {
+	Name        string   `json:"name" yaml:"name"`
+	Title       string   `json:"title" yaml:"title"`
+	Description string   `json:"description" yaml:"description"`
+	Fields      []*Field `json:"fields" yaml:"fields"`
+
+	// Configured is set for collections declared in the server
+	// configuration, which can't be changed through the API.
+	Configured bool `json:"configured" yaml:"-"`
+}
+
+// PagesCollection holds standalone pages such as "about" or "contact". It is
+// always available unless the configuration declares its own "pages".
+var PagesCollection = &Collection{
+	Name:        "pages",
+	Title:       "Pages",
+	Description: "Standalone pages outside of the blog",
+	Fields:      []*Field{},
+	Configured:  true,
+}
+
+// Item is an entry in a collection. Its content uses the same blocks as a
+// post while Fields holds the values declared by the collection.
+type Item struct {
+	Collection string                 `json:"collection"`
+	Name       string                 `json:"name"`
+	Title      string                 `json:"title"`
+	Publish    bool                   `json:"publish"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Content    []*Content             `json:"content"`
+	Created    int64                  `json:"created"`
+	Updated    int64                  `json:"updated,omitempty"`
+}
+
+// Validate checks that the collection has a usable name and schema.
+func (c *Collection) Validate() error {
+	if !collectionNameRegex.MatchString(c.Name) {
+		return InvalidField(ErrorCodeNameInvalid, "name", "invalid collection name %q", c.Name)
+	}
+
+	seen := make(map[string]bool)
+	for n, f := range c.Fields {
+		switch {
+		case f == nil || f.Name == "":
+			return InvalidField(ErrorCodeFieldRequired, fmt.Sprintf("fields[%d].name", n), "collection %q has a field without a name", c.Name)
+		case seen[f.Name]:
+			return InvalidField(ErrorCodeFieldInvalid, fmt.Sprintf("fields[%d].name", n), "collection %q declares field %q twice", c.Name, f.Name)
+		case !f.Type.Valid():
+			return InvalidField(ErrorCodeFieldInvalid, fmt.Sprintf("fields[%d].type", n), "field %q has invalid type %q", f.Name, f.Type)
+		}
+
+		seen[f.Name] = true
+	}
+
+	return nil
+}
+
+// ValidateItem checks the item's fields against the schema, converting their
+// values to a single representation per field type: string, float64, bool,
+// int64 epoch seconds or []string. Errors name the field as "fields.<name>".
+func (c *Collection) ValidateItem(i *Item) error {
+	declared := make(map[string]*Field, len(c.Fields))
+	for _, f := range c.Fields {
+		declared[f.Name] = f
+	}
+
+	for name := range i.Fields {
+		if declared[name] == nil {
+			return InvalidField(ErrorCodeFieldInvalid, "fields."+name, "collection %q has no field %q", c.Name, name)
+		}
+	}
+
+	fields := make(map[string]interface{}, len(i.Fields))
+	for _, f := range c.Fields {
+		raw, ok := i.Fields[f.Name]
+		if !ok || raw == nil {
+			if f.Required {
+				return InvalidField(ErrorCodeFieldRequired, "fields."+f.Name, "field %q is required", f.Name)
+			}
+
+			continue
+		}
+
+		v, err := fieldValue(f.Type, raw)
+		if err != nil {
+			return InvalidField(ErrorCodeFieldInvalid, "fields."+f.Name, "field %q: %v", f.Name, err)
+		}
+
+		fields[f.Name] = v
+	}
+
+	i.Fields = fields
+	return nil
+}
+
+func fieldValue(t FieldType, raw interface{}) (interface{}, error) {
+	switch t {
+	case FieldString:
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+
+	case FieldNumber:
+		if n, ok := number(raw); ok {
+			return n, nil
+		}
+
+	case FieldBoolean:
+		if b, ok := raw.(bool); ok {
+			return b, nil
+		}
+
+	case FieldTime:
+		if s, ok := raw.(string); ok {
+			tm, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return nil, fmt.Errorf("expected epoch seconds or an RFC 3339 time")
+			}
+
+			return tm.Unix(), nil
+		} else if n, ok := number(raw); ok && n == math.Trunc(n) {
+			return int64(n), nil
+		}
+
+	case FieldList:
+		switch l := raw.(type) {
+		case []string:
+			return l, nil
+		case []interface{}:
+			list := make([]string, len(l))
+			for j, v := range l {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected a list of strings")
+				}
+
+				list[j] = s
+			}
+
+			return list, nil
+		}
+	}
+
+	return nil, fmt.Errorf("expected a %s", t)
+}
+
+func number(raw interface{}) (float64, bool) {
+	switch n := raw.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+
+	return 0, false
+}
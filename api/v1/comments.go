@@ -0,0 +1,45 @@
+package v1
+
+type CommentState string
+
+const (
+	CommentPending  CommentState = "pending"
+	CommentApproved CommentState = "approved"
+	CommentSpam     CommentState = "spam"
+)
+
+func (s CommentState) Valid() bool {
+	switch s {
+	case CommentPending, CommentApproved, CommentSpam:
+		return true
+	}
+
+	return false
+}
+
+// Comment is a reader's response to a post. Comments are threaded by
+// replying to another comment on the same post.
+type Comment struct {
+	ID   string `json:"id"`
+	Post string `json:"post"`
+	// Parent is the ID of the comment this one replies to, if any.
+	Parent  string         `json:"parent,omitempty"`
+	Author  *CommentAuthor `json:"author"`
+	Body    string         `json:"body"`
+	Created int64          `json:"created"`
+	State   CommentState   `json:"state"`
+}
+
+type CommentAuthor struct {
+	Name string `json:"name"`
+	// User is set when the comment was written by a logged in user.
+	User string `json:"user,omitempty"`
+	// Email is only shown to moderators.
+	Email string `json:"email,omitempty"`
+}
+
+// CommentModeration is the body of a bulk moderation request.
+type CommentModeration struct {
+	IDs   []string     `json:"ids"`
+	State CommentState `json:"state"`
+}
@@ -11,6 +11,16 @@ import (
 var (
 	IDRegex = regexp.MustCompile(`(?i)[0-9A-F]{8}-[0-9A-F]{4}-[4][0-9A-F]{3}-[89AB][0-9A-F]{3}-[0-9A-F]{12}`)
 
+	BlobNameRegex = regexp.MustCompile(`[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+	ClaimCodeRegex = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+	blobNameRegex = regexp.MustCompile(`^` + BlobNameRegex.String() + `$`)
+
+	RevisionRegex = regexp.MustCompile(`[0-9]+`)
+
+	FeedFormatRegex = regexp.MustCompile(`rss|atom|json`)
+
 	versionHeader = describe.Parameter{
 		Name:        "TinkersNest-Version",
 		Type:        "string",
@@ -19,6 +29,28 @@ var (
 		Examples:    []string{"0.0.0-dev"},
 	}
 
+	etagHeader = describe.Parameter{
+		Name:        "ETag",
+		Type:        "string",
+		Description: "The version of the resource, for If-Match and If-None-Match. The rendered HTML of a post and the public profile of a user are tagged \"<version>-html\" and \"<version>-profile\" instead.",
+		Format:      `"<version>"`,
+		Examples:    []string{`"3"`},
+	}
+
+	ifMatchHeader = describe.Parameter{
+		Name:        "If-Match",
+		Type:        "string",
+		Description: "Only make the change if the resource is still at this version, as given by its ETag.",
+		Format:      `"<version>"`,
+	}
+
+	ifNoneMatchHeader = describe.Parameter{
+		Name:        "If-None-Match",
+		Type:        "string",
+		Description: "Answer 304 Not Modified instead of returning the resource if it's still at this version, as given by its ETag.",
+		Format:      `"<version>"`,
+	}
+
 	hostHeader = describe.Parameter{
 		Name:        "Host",
 		Type:        "string",
@@ -41,6 +73,248 @@ var (
 		Required:    true,
 	}
 
+	linkHeader = describe.Parameter{
+		Name:        "Link",
+		Type:        "link",
+		Description: "RFC5988 compliant rel='next' with URL to next result set, if available",
+		Format:      `<<url>?cursor=<cursor>&limit=<limit>>; rel="next"`,
+	}
+
+	postSearchQueryParameters = []describe.Parameter{
+		{
+			Name:        "q",
+			Type:        "string",
+			Description: "Only return posts whose title, tags or content contain at least one of these words, best matches first. Can't be combined with `sort`.",
+			Format:      "<words>",
+		},
+		{
+			Name:        "author",
+			Type:        "string",
+			Description: "Only return posts authored by this user.",
+			Format:      "<user_name>",
+		},
+		{
+			Name:        "tag",
+			Type:        "string",
+			Description: "Only return posts with this tag. May be repeated, posts must have every tag given.",
+			Format:      "<tag>",
+		},
+		{
+			Name:        "category",
+			Type:        "string",
+			Description: "Only return posts in this category or any of its subcategories.",
+			Format:      "<category>",
+		},
+		{
+			Name:        "published",
+			Type:        "boolean",
+			Description: "Only return published (`true`) or unpublished (`false`) posts.",
+			Format:      "true|false",
+		},
+		{
+			Name:        "created_after",
+			Type:        "integer",
+			Description: "Only return posts created after this time.",
+			Format:      "<epoch seconds>",
+		},
+		{
+			Name:        "created_before",
+			Type:        "integer",
+			Description: "Only return posts created before this time.",
+			Format:      "<epoch seconds>",
+		},
+		{
+			Name:        "sort",
+			Type:        "string",
+			Description: "Sort order of the results, newest first by default. Prefix with `-` for descending order.",
+			Format:      "created|-created|title|-title",
+		},
+		{
+			Name:        "limit",
+			Type:        "integer",
+			Description: "Maximum number of posts to return. When there are more results a `Link` header to the next page is set.",
+			Format:      "<integer>",
+		},
+		{
+			Name:        "cursor",
+			Type:        "string",
+			Description: "Opaque cursor from a previous response's `Link` header to resume from.",
+			Format:      "<cursor>",
+		},
+		renderQueryParameter,
+	}
+
+	renderQueryParameter = describe.Parameter{
+		Name:        "render",
+		Type:        "string",
+		Description: "Return every content block as sanitized HTML instead of its stored type.",
+		Format:      "html",
+	}
+
+	revisionParameter = describe.Parameter{
+		Name:        "revision",
+		Type:        "integer",
+		Description: "Number of a post revision, starting at 1",
+		Required:    true,
+		Regexp:      RevisionRegex,
+	}
+
+	feedResponses = []describe.Response{
+		{
+			Description: "RSS 2.0 feed of the latest published posts",
+			StatusCode:  http.StatusOK,
+			Body: describe.Body{
+				ContentType: "application/rss+xml; charset=utf-8",
+				Format:      "<rss version=\"2.0\">...</rss>",
+			},
+		},
+		{
+			Description: "Atom 1.0 feed of the latest published posts",
+			StatusCode:  http.StatusOK,
+			Body: describe.Body{
+				ContentType: "application/atom+xml; charset=utf-8",
+				Format:      "<feed xmlns=\"http://www.w3.org/2005/Atom\">...</feed>",
+			},
+		},
+		{
+			Description: "JSON Feed 1.1 of the latest published posts",
+			StatusCode:  http.StatusOK,
+			Body: describe.Body{
+				ContentType: "application/feed+json; charset=utf-8",
+				Format:      `{"version": "https://jsonfeed.org/version/1.1", "items": [...], ...}`,
+			},
+		},
+	}
+
+	diffQueryParameters = []describe.Parameter{
+		{
+			Name:        "from",
+			Type:        "integer",
+			Description: "Revision to compare from. Defaults to the revision before `to`, 0 compares against an empty post.",
+			Format:      "<revision>",
+		},
+		{
+			Name:        "to",
+			Type:        "integer",
+			Description: "Revision to compare to. Defaults to the latest revision.",
+			Format:      "<revision>",
+		},
+	}
+
+	sessionIDParameter = describe.Parameter{
+		Name:        "session_id",
+		Type:        "string",
+		Description: "Identifier for a login session",
+		Required:    true,
+	}
+
+	keyIDParameter = describe.Parameter{
+		Name:        "key_id",
+		Type:        "string",
+		Description: "Identifier for an API key",
+		Required:    true,
+	}
+
+	feedFormatParameter = describe.Parameter{
+		Name:        "format",
+		Type:        "string",
+		Description: "Feed format: RSS 2.0, Atom 1.0 or JSON Feed 1.1",
+		Required:    true,
+		Regexp:      FeedFormatRegex,
+	}
+
+	tagParameter = describe.Parameter{
+		Name:        "tag",
+		Type:        "string",
+		Description: "A post tag",
+		Required:    true,
+	}
+
+	categoryParameter = describe.Parameter{
+		Name:        "category",
+		Type:        "string",
+		Description: "Slug of a post category",
+		Required:    true,
+	}
+
+	commentIDParameter = describe.Parameter{
+		Name:        "comment_id",
+		Type:        "string",
+		Description: "Identifier for a comment",
+		Required:    true,
+		Regexp:      IDRegex,
+	}
+
+	commentStateQueryParameter = describe.Parameter{
+		Name:        "state",
+		Type:        "string",
+		Description: "Only list comments in this moderation state: pending, approved or spam. Ignored for readers who can't moderate.",
+		Format:      "<state>",
+	}
+
+	collectionParameter = describe.Parameter{
+		Name:        "collection",
+		Type:        "string",
+		Description: "Name of a content collection",
+		Required:    true,
+		Regexp:      CollectionNameRegex,
+	}
+
+	itemNameParameter = describe.Parameter{
+		Name:        "item_name",
+		Type:        "string",
+		Description: "Name of an item in a collection",
+		Required:    true,
+	}
+
+	webhookIDParameter = describe.Parameter{
+		Name:        "webhook_id",
+		Type:        "string",
+		Description: "Identifier for a webhook",
+		Required:    true,
+		Regexp:      IDRegex,
+	}
+
+	claimCodeParameter = describe.Parameter{
+		Name:        "claim_code",
+		Type:        "string",
+		Description: "Code of a claim",
+		Required:    true,
+		Regexp:      ClaimCodeRegex,
+	}
+
+	claimQueryParameters = []describe.Parameter{
+		{
+			Name:        "state",
+			Type:        "string",
+			Description: "Only return claims in this state: active, expired, redeemed or revoked.",
+			Format:      "<state>",
+		},
+		{
+			Name:        "resource_type",
+			Type:        "string",
+			Description: "Only return claims for creating this kind of resource, post or user.",
+			Format:      "<resource_type>",
+		},
+	}
+
+	claimPurgeQueryParameters = []describe.Parameter{
+		{
+			Name:        "state",
+			Type:        "string",
+			Description: "Only purge claims in this state: expired, redeemed or revoked. May be given more than once, defaults to all three. Active claims can't be purged.",
+			Format:      "<state>",
+		},
+	}
+
+	blobNameParameter = describe.Parameter{
+		Name:        "blob_name",
+		Type:        "string",
+		Description: "Name of an uploaded media blob",
+		Required:    true,
+		Regexp:      BlobNameRegex,
+	}
+
 	jsonContentLengthHeader = describe.Parameter{
 		Name:        "Content-Length",
 		Type:        "integer",
@@ -55,6 +329,195 @@ var (
 		Format:      "0",
 	}
 
+	parameterInvalidResp = describe.Response{
+		Name:        "Parameter Invalid Error",
+		StatusCode:  http.StatusBadRequest,
+		Description: "A query parameter was malformed or has an unsupported value.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      errorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeParameterInvalid,
+		},
+	}
+
+	unauthorizedResp = describe.Response{
+		Name:        "Unauthorized Error",
+		StatusCode:  http.StatusUnauthorized,
+		Description: "The bearer token is missing, expired or invalid.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      errorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeUnauthorized,
+		},
+	}
+
+	forbiddenResp = describe.Response{
+		Name:        "Forbidden Error",
+		StatusCode:  http.StatusForbidden,
+		Description: "The user's roles do not allow the operation.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      errorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeForbidden,
+		},
+	}
+
+	invalidBodyResp = describe.Response{
+		Name:        "Invalid Body Error",
+		StatusCode:  http.StatusBadRequest,
+		Description: "The request body couldn't be decoded or a field in it was rejected. The detail names the field and why.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      fieldErrorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeBodyInvalid,
+			ErrorCodeFieldRequired,
+			ErrorCodeFieldInvalid,
+			ErrorCodeNameInvalid,
+			ErrorCodeContentTypeUnsupported,
+		},
+	}
+
+	nameTakenResp = describe.Response{
+		Name:        "Name Taken Error",
+		StatusCode:  http.StatusConflict,
+		Description: "A resource with the same name already exists.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      fieldErrorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeNameTaken,
+		},
+	}
+
+	claimInvalidResp = describe.Response{
+		Name:        "Claim Invalid Error",
+		StatusCode:  http.StatusForbidden,
+		Description: "The claim in the TINKERSNEST-CLAIM header is unknown, expired, revoked, used up or for another kind of resource, or the request doesn't match the email the claim was issued for.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      fieldErrorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeClaimInvalid,
+		},
+	}
+
+	notModifiedResp = describe.Response{
+		Description: "The resource is still at the version given in If-None-Match",
+		StatusCode:  http.StatusNotModified,
+		Headers: []describe.Parameter{
+			versionHeader,
+			etagHeader,
+		},
+	}
+
+	preconditionFailedResp = describe.Response{
+		Name:        "Precondition Failed Error",
+		StatusCode:  http.StatusPreconditionFailed,
+		Description: "The resource was changed since the version given in If-Match, or while it was being updated.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      errorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodePreconditionFailed,
+		},
+	}
+
+	unsupportedMediaTypeResp = describe.Response{
+		Name:        "Unsupported Media Type Error",
+		StatusCode:  http.StatusUnsupportedMediaType,
+		Description: "The patch is neither a JSON Merge Patch nor a JSON Patch.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+			{
+				Name:        "Accept-Patch",
+				Type:        "string",
+				Description: "The patch formats the route accepts.",
+				Format:      "application/merge-patch+json, application/json-patch+json",
+			},
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      errorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeUnsupportedMediaType,
+		},
+	}
+
+	mediaTypeUnsupportedResp = describe.Response{
+		Name:        "Unsupported Media Type Error",
+		StatusCode:  http.StatusUnsupportedMediaType,
+		Description: "The media's content type isn't one the server accepts for uploads.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      errorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeUnsupportedMediaType,
+		},
+	}
+
+	mediaTooLargeResp = describe.Response{
+		Name:        "Media Too Large Error",
+		StatusCode:  http.StatusRequestEntityTooLarge,
+		Description: "The media is larger than the server's limit.",
+		Headers: []describe.Parameter{
+			versionHeader,
+			jsonContentLengthHeader,
+		},
+		Body: describe.Body{
+			ContentType: "application/json; charset=utf-8",
+			Format:      errorsBody,
+		},
+		ErrorCodes: []errcode.ErrorCode{
+			ErrorCodeMediaTooLarge,
+		},
+	}
+
 	resourceNotFoundResp = describe.Response{
 		Name:        "Resource Unknown Error",
 		StatusCode:  http.StatusNotFound,
@@ -85,12 +548,31 @@ var (
     ]
 }`
 
+	fieldErrorsBody = `{
+	"errors": [
+	    {
+            "code": <error code>,
+            "message": <error message>,
+            "detail": {
+                "field": <path of the field, like "content[0].type">,
+                "reason": <why it was rejected>
+            }
+        }
+    ]
+}`
+
 	blogPostBody = `{
 	"name": ...,
 	"created": <epoch seconds>,
 	"publish": true|false,
+	"publish_at": <epoch seconds>,
+	"unpublish_at": <epoch seconds>,
+	"state": "draft"|"scheduled"|"published"|"archived",
 	"title": ...,
-	"content": ...
+	"tags": [...],
+	"categories": [<category slug>, ...],
+	"content": ...,
+	"version": <version>
 }`
 
 	blogPostListBody = `[
@@ -100,79 +582,3145 @@ var (
 	userBody = `{
 	"name": ...,
 	"full_name": "John Doe",
-	"email": "j.doe@example.org"
+	"email": "j.doe@example.org",
+	"roles": ["admin"|"editor"|"author"|"viewer", ...],
+	"version": <version>
+}`
+
+	mergePatchBody = `{
+	"<field>": <new value>|null,
+	...
 }`
 
+	jsonPatchBody = `[
+	{
+		"op": "add"|"remove"|"replace"|"move"|"copy"|"test",
+		"path": "/<field>[/<index or field>...]",
+		"from": ...,
+		"value": ...
+	},
+	...
+]`
+
 	userListBody = `[
 ` + userBody + `, ...
 ]`
-)
 
-var API = struct {
-	Routes []describe.Route `json:"routes"`
-}{
-	Routes: routeDescriptors,
-}
+	revisionBody = `{
+	"number": <revision>,
+	"user": ...,
+	"created": <epoch seconds>,
+	"restored_from": <revision>,
+	"post": ` + blogPostBody + `
+}`
+
+	revisionListBody = `[
+` + revisionBody + `, ...
+]`
+
+	revisionDiffBody = `{
+	"post": ...,
+	"from": <revision>,
+	"to": <revision>,
+	"diff": "--- revision 1\n+++ revision 2\n@@ -1,3 +1,3 @@\n..."
+}`
+
+	authRequestBody = `{
+	"name": ...,
+	"password": ...
+}|{
+	"refresh_token": ...
+}`
+
+	authTokensBody = `{
+	"access_token": ...,
+	"refresh_token": ...,
+	"token_type": "Bearer",
+	"expires_in": <seconds>
+}`
+
+	sessionBody = `{
+	"id": ...,
+	"user": ...,
+	"created": <epoch seconds>,
+	"refreshed": <epoch seconds>,
+	"expires": <epoch seconds>,
+	"user_agent": ...
+}`
+
+	sessionListBody = `[
+` + sessionBody + `, ...
+]`
+
+	apiKeyBody = `{
+	"id": ...,
+	"name": "deploy pipeline",
+	"user": ...,
+	"scopes": ["posts.read", "posts.write", ...],
+	"created": <epoch seconds>,
+	"expires": <epoch seconds>
+}`
+
+	apiKeyListBody = `[
+` + apiKeyBody + `, ...
+]`
+
+	createAPIKeyBody = `{
+	"name": "deploy pipeline",
+	"scopes": ["posts.read", "posts.write", ...],
+	"expires": <epoch seconds>
+}`
+
+	createdAPIKeyBody = `{
+	"id": ...,
+	"name": "deploy pipeline",
+	"user": ...,
+	"scopes": ["posts.read", "posts.write", ...],
+	"created": <epoch seconds>,
+	"expires": <epoch seconds>,
+	"key": "tnk_..."
+}`
+
+	mediaBody = `{
+	"name": ...,
+	"meta": {
+		"content-type": "image/png",
+		"size": "<bytes>",
+		"created": "<epoch seconds>"
+	}
+}`
+
+	jwksBody = `{
+	"keys": [
+		{
+			"kty": "RSA"|"EC",
+			"kid": ...,
+			"alg": "RS256"|"ES256",
+			"use": "sig",
+			...
+		},
+		...
+	]
+}`
+
+	mediaListBody = `[
+` + mediaBody + `, ...
+]`
+
+	tagBody = `{
+	"slug": ...,
+	"title": ...,
+	"description": ...,
+	"count": <number of posts>
+}`
+
+	tagListBody = `[
+` + tagBody + `, ...
+]`
+
+	categoryBody = `{
+	"slug": ...,
+	"title": ...,
+	"description": ...,
+	"parent": <parent slug>,
+	"count": <number of posts>
+}`
+
+	categoryListBody = `[
+` + categoryBody + `, ...
+]`
+
+	termTargetBody = `{
+	"to": <slug>
+}`
+
+	commentBody = `{
+	"id": <uuid>,
+	"post": <post name>,
+	"parent": <id of the comment replied to>,
+	"author": {
+		"name": ...,
+		"user": <user name>,
+		"email": ...
+	},
+	"body": ...,
+	"created": <unix timestamp>,
+	"state": "pending" | "approved" | "spam"
+}`
+
+	commentListBody = `[
+` + commentBody + `, ...
+]`
+
+	collectionBody = `{
+	"name": ...,
+	"title": ...,
+	"description": ...,
+	"fields": [
+		{
+			"name": ...,
+			"type": "string"|"number"|"boolean"|"time"|"list",
+			"required": true|false,
+			"description": ...
+		},
+		...
+	],
+	"configured": true|false
+}`
+
+	collectionListBody = `[
+` + collectionBody + `, ...
+]`
+
+	itemBody = `{
+	"collection": ...,
+	"name": ...,
+	"title": ...,
+	"publish": true|false,
+	"fields": {
+		<field name>: <value>,
+		...
+	},
+	"content": ...,
+	"created": <epoch seconds>,
+	"updated": <epoch seconds>
+}`
+
+	itemListBody = `[
+` + itemBody + `, ...
+]`
+
+	commentModerationBody = `{
+	"ids": [<comment id>, ...],
+	"state": "pending" | "approved" | "spam"
+}`
+
+	webhookBody = `{
+	"id": <uuid>,
+	"url": <http or https url>,
+	"events": ["post.created" | "post.updated" | "post.deleted" | "post.published" | "user.created" | "user.updated" | "user.deleted" | "comment.created" | "comment.updated" | "comment.deleted" | "comment.published" | "media.created" | "media.updated" | "media.deleted", ...],
+	"user": <user name>,
+	"created": <unix timestamp>,
+	"secret": <only returned on creation>
+}`
+
+	webhookListBody = `[
+` + webhookBody + `, ...
+]`
+
+	eventBody = `{
+	"id": <uuid>,
+	"type": <event type>,
+	"resource": <name or id of the resource>,
+	"created": <unix timestamp>,
+	"data": <the resource, left out for deletions>
+}`
+
+	eventStreamBody = `id: <event id>
+event: <event type>
+data: ` + eventBody + `
+
+...`
+
+	deliveryListBody = `[
+	{
+		"id": <uuid, the same for every attempt>,
+		"webhook": <webhook id>,
+		"event": <event id>,
+		"type": <event type>,
+		"attempt": <number>,
+		"created": <unix timestamp>,
+		"status": <response status code>,
+		"error": ...,
+		"duration": <milliseconds>
+	}, ...
+]`
+
+	claimBody = `{
+	"code": <code>,
+	"resource_type": "post" | "user",
+	"email": <email the user must have, optional>,
+	"role": <role the user is given, optional>,
+	"max_uses": <number>,
+	"uses": <number>,
+	"user": <user name of the issuer>,
+	"created": <unix timestamp>,
+	"expires": <unix timestamp>,
+	"redeemed": <unix timestamp of the last use>,
+	"revoked": <unix timestamp>,
+	"state": "active" | "expired" | "redeemed" | "revoked"
+}`
+
+	claimListBody = `[
+	` + claimBody + `, ...
+]`
+
+	claimCreateBody = `{
+	"resource_type": "post" | "user",
+	"email": <email the user must have, optional>,
+	"role": <role the user is given, optional>,
+	"max_uses": <number, defaults to 1>,
+	"expires": <unix timestamp, defaults to a week from now>
+}`
+
+	openAPIBody = `{
+	"openapi": "3.0.3",
+	"info": {
+		"title": "TinkersNest API",
+		"version": <server version>
+	},
+	"paths": {
+		<path>: {
+			<method>: <operation>, ...
+		}, ...
+	},
+	"components": {
+		"schemas": {
+			<entity>: <schema>, ...
+		}, ...
+	}
+}`
+)
+
+var API = struct {
+	Routes []describe.Route `json:"routes"`
+}{
+	Routes: routeDescriptors,
+}
+
+var routeDescriptors = []describe.Route{
+	{
+		Name:        RouteNameBase,
+		Path:        "/v1",
+		Entity:      "Base",
+		Description: "Base V1 API route, can be used for lightweight health and version check.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Check that the server supports the TinkersNest V1 API.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "The API implements the V1 protocol and is accessible.",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							{
+								Description: "The API does not support the V1 protocol.",
+								StatusCode:  http.StatusNotFound,
+								Headers: []describe.Parameter{
+									versionHeader,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameAuth,
+		Path:        "/v1/auth",
+		Entity:      "JWT",
+		Description: "Route to log in, refresh a session and log out.",
+		Methods: []describe.Method{
+			{
+				Method:      "POST",
+				Description: "Start a session with a user's name and password, or refresh one with its refresh token. Refresh tokens can only be used once, each refresh returns a new one.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      authRequestBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Tokens returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      authTokensBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Log out, revoking the session of the bearer token.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Session revoked",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameAuthKeys,
+		Path:        "/v1/auth/keys",
+		Entity:      "JWKS",
+		Description: "Route to fetch the public keys bearer tokens are signed with, so other services can verify them without a shared secret.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get the public token signing keys as a JSON Web Key Set. HS256 secrets are never included.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Key set returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      jwksBody,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostByName,
+		Path:        "/v1/blog/posts/{post_name}",
+		Entity:      "Post",
+		Description: "Route to retrieve, update, and delete a single post by name.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a post by name",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifNoneMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						QueryParameters: []describe.Parameter{
+							renderQueryParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "post returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostBody,
+								},
+							},
+							notModifiedResp,
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PUT",
+				Description: "Modify a single blog post",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "user returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							invalidBodyResp,
+							preconditionFailedResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PATCH",
+				Description: "Partially update a single blog post",
+				Requests: []describe.Request{
+					{
+						Name:        "Merge Patch",
+						Description: "Merge the fields into the post. Null removes a field, so tags, categories and schedules can be cleared.",
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/merge-patch+json",
+							Format:      mergePatchBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "patched post returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+							invalidBodyResp,
+							preconditionFailedResp,
+							unsupportedMediaTypeResp,
+						},
+					},
+					{
+						Name:        "JSON Patch",
+						Description: "Apply the operations to the post in order. A failed test operation fails the whole patch with 412, so testing /version guards against concurrent changes.",
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json-patch+json",
+							Format:      jsonPatchBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "patched post returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+							invalidBodyResp,
+							preconditionFailedResp,
+							unsupportedMediaTypeResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete a post by name",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "post removed",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameBlog,
+		Path:        "/v1/blog/posts",
+		Entity:      "[]Post",
+		Description: "Route to retrieve the list of posts and create new ones.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all posts",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						QueryParameters: postSearchQueryParameters,
+
+						Successes: []describe.Response{
+							{
+								Description: "All posts returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+									linkHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							parameterInvalidResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "POST",
+				Description: "Create a blog post",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Post created",
+								StatusCode:  http.StatusCreated,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							invalidBodyResp,
+							nameTakenResp,
+							claimInvalidResp,
+						},
+					},
+				},
+			},
+		},
+	},
+
+	{
+		Name:        RouteNameUserByName,
+		Path:        "/v1/users/{user_name}",
+		Entity:      "User",
+		Description: "Route to retrieve, update, and delete a single user by name.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Retrieve a single user",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifNoneMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "user returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      userBody,
+								},
+							},
+							notModifiedResp,
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PUT",
+				Description: "Modify a single user",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "user returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      userBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							invalidBodyResp,
+							preconditionFailedResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PATCH",
+				Description: "Partially update a single user",
+				Requests: []describe.Request{
+					{
+						Name:        "Merge Patch",
+						Description: "Merge the fields into the user. Setting password changes it.",
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/merge-patch+json",
+							Format:      mergePatchBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "patched user returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      userBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+							invalidBodyResp,
+							preconditionFailedResp,
+							unsupportedMediaTypeResp,
+						},
+					},
+					{
+						Name:        "JSON Patch",
+						Description: "Apply the operations to the user in order. A failed test operation fails the whole patch with 412.",
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json-patch+json",
+							Format:      jsonPatchBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "patched user returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      userBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+							invalidBodyResp,
+							preconditionFailedResp,
+							unsupportedMediaTypeResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete a user",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "user deleted",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostsByUser,
+		Path:        "/v1/users/{user_name}/posts",
+		Entity:      "[]Post",
+		Description: "Route to retrieve the list of posts from a user.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all posts authored by a user",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						QueryParameters: withoutParameter(postSearchQueryParameters, "author"),
+
+						Successes: []describe.Response{
+							{
+								Description: "All posts returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+									linkHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							parameterInvalidResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameUserRegistry,
+		Path:        "/v1/users",
+		Entity:      "[]User",
+		Description: "Route to retrieve the list of users and create new ones.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all users",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "All users returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      userListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "POST",
+				Description: "Create a user",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "User created",
+								StatusCode:  http.StatusCreated,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      userBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							invalidBodyResp,
+							nameTakenResp,
+							claimInvalidResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameMedia,
+		Path:        "/v1/media",
+		Entity:      "[]Blob",
+		Description: "Route to list uploaded media.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get metadata for all uploaded media",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "All media returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      mediaListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameMediaByName,
+		Path:        "/v1/media/{blob_name:" + BlobNameRegex.String() + "}",
+		Entity:      "Blob",
+		Description: "Route to upload, download, and delete a single media blob by name.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Download the contents of a media blob. Anonymous readers can download media too, so the images in rendered posts load.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							blobNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "media contents returned with the content type it was uploaded with",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									{
+										Name:        "Content-Length",
+										Type:        "integer",
+										Description: "Size of the media in bytes.",
+										Format:      "<length>",
+									},
+									{
+										Name:        "X-Content-Type-Options",
+										Type:        "string",
+										Description: "Always set so browsers don't guess another content type.",
+										Format:      "nosniff",
+									},
+									{
+										Name:        "Content-Disposition",
+										Type:        "string",
+										Description: "Set for media other than images and video so that it's downloaded instead of shown.",
+										Format:      "attachment; filename=\"<blob_name>\"",
+									},
+								},
+
+								Body: describe.Body{
+									ContentType: "<media content type>",
+									Format:      "<binary data>",
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PUT",
+				Description: "Upload a media blob, replacing any existing blob with the same name",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+							{
+								Name:        "Content-Type",
+								Type:        "string",
+								Description: "Content type of the media, kept in the blob metadata and used when downloading. It must be one the server accepts, and is detected from the data when it's left out or `application/octet-stream`.",
+								Format:      "<media type>",
+							},
+						},
+
+						PathParameters: []describe.Parameter{
+							blobNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "<media content type>",
+							Format:      "<binary data>",
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "media uploaded",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+									{
+										Name:        "Location",
+										Type:        "url",
+										Description: "URL to download the uploaded media.",
+										Format:      "<url>",
+									},
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      mediaBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							mediaTooLargeResp,
+							mediaTypeUnsupportedResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete a media blob",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							blobNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "media deleted",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameMediaMeta,
+		Path:        "/v1/media/{blob_name:" + BlobNameRegex.String() + "}/meta",
+		Entity:      "Blob",
+		Description: "Route to inspect the metadata of a single media blob.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get the metadata of a media blob",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							blobNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "media metadata returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      mediaBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameUserSessions,
+		Path:        "/v1/users/{user_name}/sessions",
+		Entity:      "[]Session",
+		Description: "Route to list and revoke the login sessions of a user.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get the user's active sessions",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Sessions returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      sessionListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Revoke all of the user's sessions",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Sessions revoked",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameUserSessionByID,
+		Path:        "/v1/users/{user_name}/sessions/{session_id}",
+		Entity:      "Session",
+		Description: "Route to revoke a single login session.",
+		Methods: []describe.Method{
+			{
+				Method:      "DELETE",
+				Description: "Revoke a session",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+							sessionIDParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Session revoked",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameUserKeys,
+		Path:        "/v1/users/{user_name}/keys",
+		Entity:      "[]APIKey",
+		Description: "Route to list and create a user's API keys. Users can manage their own keys, admins can manage anyone's.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get the user's API keys. The keys themselves are never returned.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Keys returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      apiKeyListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "POST",
+				Description: "Create an API key. The key is only included in this response, store it somewhere safe. Scopes must be permissions the user's roles grant. Keys can't be created with another API key.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      createAPIKeyBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Key created",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      createdAPIKeyBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							invalidBodyResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameUserKeyByID,
+		Path:        "/v1/users/{user_name}/keys/{key_id}",
+		Entity:      "APIKey",
+		Description: "Route to revoke a single API key.",
+		Methods: []describe.Method{
+			{
+				Method:      "DELETE",
+				Description: "Revoke an API key",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+							keyIDParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Key revoked",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostRevisions,
+		Path:        "/v1/blog/posts/{post_name}/revisions",
+		Entity:      "[]Revision",
+		Description: "Route to retrieve the history of a post. A revision is recorded every time the post is created, updated or restored.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get every revision of a post, oldest first",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Revisions returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      revisionListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostRevision,
+		Path:        "/v1/blog/posts/{post_name}/revisions/{revision:" + RevisionRegex.String() + "}",
+		Entity:      "Revision",
+		Description: "Route to retrieve a single revision of a post.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a revision",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+							revisionParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Revision returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      revisionBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostRevisionRestore,
+		Path:        "/v1/blog/posts/{post_name}/revisions/{revision:" + RevisionRegex.String() + "}/restore",
+		Entity:      "Post",
+		Description: "Route to roll a post back to an older revision.",
+		Methods: []describe.Method{
+			{
+				Method:      "POST",
+				Description: "Make the revision the current version of the post. The restore is recorded as a new revision, so no history is lost.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+							ifMatchHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+							revisionParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Post restored and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									etagHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+							preconditionFailedResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostDiff,
+		Path:        "/v1/blog/posts/{post_name}/diff",
+		Entity:      "RevisionDiff",
+		Description: "Route to compare two revisions of a post.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a unified diff between two revisions. Without parameters the latest change is shown.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						QueryParameters: diffQueryParameters,
+
+						Successes: []describe.Response{
+							{
+								Description: "Diff returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      revisionDiffBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameBlogFeed,
+		Path:        "/v1/blog/feeds/{format:" + FeedFormatRegex.String() + "}",
+		Entity:      "Feed",
+		Description: "Route to the feed of every published post.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get the blog feed",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							feedFormatParameter,
+						},
+
+						Successes: feedResponses,
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameUserFeed,
+		Path:        "/v1/users/{user_name}/feeds/{format:" + FeedFormatRegex.String() + "}",
+		Entity:      "Feed",
+		Description: "Route to the feed of a single author's published posts.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get an author's feed",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							userNameParameter,
+							feedFormatParameter,
+						},
+
+						Successes: feedResponses,
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameTagFeed,
+		Path:        "/v1/blog/tags/{tag}/feeds/{format:" + FeedFormatRegex.String() + "}",
+		Entity:      "Feed",
+		Description: "Route to the feed of published posts with a tag.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a tag's feed",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							tagParameter,
+							feedFormatParameter,
+						},
+
+						Successes: feedResponses,
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameTags,
+		Path:        "/v1/tags",
+		Entity:      "[]Tag",
+		Description: "Route to list every tag in use along with the number of posts carrying it. Tags exist as soon as a post uses them.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all tags, including those only described and not used by any post yet",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Tags returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      tagListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameTagBySlug,
+		Path:        "/v1/tags/{tag}",
+		Entity:      "Tag",
+		Description: "Route to describe, update and delete a single tag.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a tag",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							tagParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Tag returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      tagBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PUT",
+				Description: "Set the title and description of a tag",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							tagParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      tagBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Tag stored and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      tagBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete a tag, removing it from every post that carries it",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							tagParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Tag deleted",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameTagPosts,
+		Path:        "/v1/tags/{tag}/posts",
+		Entity:      "[]Post",
+		Description: "Route to retrieve the list of posts with a tag.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all posts with the tag",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							tagParameter,
+						},
+
+						QueryParameters: postSearchQueryParameters,
+
+						Successes: []describe.Response{
+							{
+								Description: "Posts returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+									linkHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							parameterInvalidResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameTagRename,
+		Path:        "/v1/tags/{tag}/rename",
+		Entity:      "Tag",
+		Description: "Route to give a tag a new slug, retagging every post that carries it.",
+		Methods: []describe.Method{
+			{
+				Method:      "POST",
+				Description: "Rename the tag. The new slug must not be in use. Every post that changes gets a new revision.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							tagParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      termTargetBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Tag renamed and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      tagBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameTagMerge,
+		Path:        "/v1/tags/{tag}/merge",
+		Entity:      "Tag",
+		Description: "Route to merge a tag into another existing tag, retagging every post that carries it.",
+		Methods: []describe.Method{
+			{
+				Method:      "POST",
+				Description: "Merge the tag into the one named by `to`, which keeps its own title and description. Every post that changes gets a new revision.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							tagParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      termTargetBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Merged tag returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      tagBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCategories,
+		Path:        "/v1/categories",
+		Entity:      "[]Category",
+		Description: "Route to list the category hierarchy along with the number of posts in each category and its subcategories.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all categories. Subcategories name their `parent`.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Categories returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      categoryListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCategoryBySlug,
+		Path:        "/v1/categories/{category}",
+		Entity:      "Category",
+		Description: "Route to describe, create, update and delete a single category.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a category",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							categoryParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Category returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      categoryBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PUT",
+				Description: "Create or update a category. A parent must already exist and can't be one of the category's own subcategories.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							categoryParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      categoryBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Category stored and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      categoryBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete a category. Its posts are taken out of it and its subcategories move up to its parent.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							categoryParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Category deleted",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCategoryPosts,
+		Path:        "/v1/categories/{category}/posts",
+		Entity:      "[]Post",
+		Description: "Route to retrieve the list of posts in a category or any of its subcategories.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all posts in the category or its subcategories",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							categoryParameter,
+						},
+
+						QueryParameters: postSearchQueryParameters,
+
+						Successes: []describe.Response{
+							{
+								Description: "Posts returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+									linkHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      blogPostListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							parameterInvalidResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCategoryRename,
+		Path:        "/v1/categories/{category}/rename",
+		Entity:      "Category",
+		Description: "Route to give a category a new slug, refiling its posts and subcategories.",
+		Methods: []describe.Method{
+			{
+				Method:      "POST",
+				Description: "Rename the category. The new slug must not be in use. Every post that changes gets a new revision.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							categoryParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      termTargetBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Category renamed and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      categoryBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCategoryMerge,
+		Path:        "/v1/categories/{category}/merge",
+		Entity:      "Category",
+		Description: "Route to merge a category into another existing category, which takes over its posts and subcategories.",
+		Methods: []describe.Method{
+			{
+				Method:      "POST",
+				Description: "Merge the category into the one named by `to`, which keeps its own title and description. Every post that changes gets a new revision.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							categoryParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      termTargetBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Merged category returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      categoryBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostComments,
+		Path:        "/v1/blog/posts/{post_name}/comments",
+		Entity:      "[]Comment",
+		Description: "Route to read and write the comments on a post. Anonymous readers only see approved comments and can only comment on published posts.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get the comments on a post, oldest first. Replies name their `parent`. Moderators see every comment and can filter them by `state`.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						QueryParameters: []describe.Parameter{commentStateQueryParameter},
+
+						Successes: []describe.Response{
+							{
+								Description: "Comments returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      commentListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "POST",
+				Description: "Comment on a post or reply to one of its comments. Anonymous comments must give an author name and are held for moderation.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      commentBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Comment created and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      commentBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNamePostComment,
+		Path:        "/v1/blog/posts/{post_name}/comments/{comment_id:" + IDRegex.String() + "}",
+		Entity:      "Comment",
+		Description: "Route to read and delete a single comment.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a comment",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+							commentIDParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Comment returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      commentBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete a comment and every reply below it. Authors can delete their own comments.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							postNameParameter,
+							commentIDParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Comment deleted",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameComments,
+		Path:        "/v1/comments",
+		Entity:      "[]Comment",
+		Description: "Route to the moderation queue of comments across every post.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get comments awaiting moderation, oldest first. Pass `state` to list approved or spam comments instead.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						QueryParameters: []describe.Parameter{commentStateQueryParameter},
+
+						Successes: []describe.Response{
+							{
+								Description: "Comments returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      commentListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCommentModeration,
+		Path:        "/v1/comments/moderate",
+		Entity:      "CommentModeration",
+		Description: "Route to approve or reject comments in bulk.",
+		Methods: []describe.Method{
+			{
+				Method:      "POST",
+				Description: "Move every listed comment into the state. Nothing changes if any of the comments is unknown.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      commentModerationBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Comments moderated",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCollections,
+		Path:        "/v1/collections",
+		Entity:      "[]Collection",
+		Description: "Route to list the collections of content beyond the blog, such as pages, projects or recipes.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get every collection, including those declared in the configuration and the built-in `pages` collection",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Collections returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      collectionListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCollection,
+		Path:        "/v1/collections/{collection:" + CollectionNameRegex.String() + "}",
+		Entity:      "Collection",
+		Description: "Route to describe, define and delete a collection and the schema of its fields.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get a collection",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Collection returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      collectionBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "PUT",
+				Description: "Define or redefine a collection. Collections declared in the configuration can't be changed.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      collectionBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Collection stored and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      collectionBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							invalidBodyResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete a collection along with all of its items",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Collection deleted",
+								StatusCode:  http.StatusNoContent,
+								Headers: []describe.Parameter{
+									versionHeader,
+									zeroContentLengthHeader,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCollectionItems,
+		Path:        "/v1/collections/{collection:" + CollectionNameRegex.String() + "}/items",
+		Entity:      "[]Item",
+		Description: "Route to list and create the items of a collection. Anonymous readers only see published items.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get the items of a collection, ordered by name",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Items returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      itemListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "POST",
+				Description: "Create an item. Its fields are checked against the collection's schema and its name defaults to a slug of its title.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      itemBody,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Item created and returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      itemBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							invalidBodyResp,
+							nameTakenResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        RouteNameCollectionItem,
+		Path:        "/v1/collections/{collection:" + CollectionNameRegex.String() + "}/items/{item_name}",
+		Entity:      "Item",
+		Description: "Route to read, update and delete a single item of a collection.",
+		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get an item",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+							itemNameParameter,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Item returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      itemBody,
+								},
+							},
+						},
 
-var routeDescriptors = []describe.Route{
-	{
-		Name:        RouteNameBase,
-		Path:        "/v1",
-		Entity:      "Base",
-		Description: "Base V1 API route, can be used for lightweight health and version check.",
-		Methods: []describe.Method{
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
 			{
-				Method:      "GET",
-				Description: "Check that the server supports the TinkersNest V1 API.",
+				Method:      "PUT",
+				Description: "Replace an item. Its fields are checked against the collection's schema.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
 						},
 
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+							itemNameParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      itemBody,
+						},
+
 						Successes: []describe.Response{
 							{
-								Description: "The API implements the V1 protocol and is accessible.",
+								Description: "Item updated and returned",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
-									zeroContentLengthHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      itemBody,
 								},
 							},
 						},
 
 						Failures: []describe.Response{
+							parameterInvalidResp,
+							invalidBodyResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
+					},
+				},
+			},
+			{
+				Method:      "DELETE",
+				Description: "Delete an item",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						PathParameters: []describe.Parameter{
+							collectionParameter,
+							itemNameParameter,
+						},
+
+						Successes: []describe.Response{
 							{
-								Description: "The API does not support the V1 protocol.",
-								StatusCode:  http.StatusNotFound,
+								Description: "Item deleted",
+								StatusCode:  http.StatusNoContent,
 								Headers: []describe.Parameter{
 									versionHeader,
+									zeroContentLengthHeader,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
 					},
 				},
 			},
 		},
 	},
 	{
-		Name:        RouteNameAuth,
-		Path:        "/v1/auth",
-		Entity:      "JWT",
-		Description: "",
+		Name:        RouteNameWebhooks,
+		Path:        "/v1/webhooks",
+		Entity:      "[]Webhook",
+		Description: "Route to list and register webhooks, which are sent the events they subscribe to as they happen.",
 		Methods: []describe.Method{
+			{
+				Method:      "GET",
+				Description: "Get all webhooks. Their secrets are left out.",
+				Requests: []describe.Request{
+					{
+						Headers: []describe.Parameter{
+							hostHeader,
+						},
+
+						Successes: []describe.Response{
+							{
+								Description: "Webhooks returned",
+								StatusCode:  http.StatusOK,
+								Headers: []describe.Parameter{
+									versionHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      webhookListBody,
+								},
+							},
+						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+						},
+					},
+				},
+			},
 			{
 				Method:      "POST",
-				Description: "",
+				Description: "Register a webhook. Without any `events` it is sent all of them. A `secret` is generated unless one is given and is only returned in this response.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
 						},
 
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      webhookBody,
+						},
+
 						Successes: []describe.Response{
 							{
-								Description: "All posts returned",
+								Description: "Webhook registered and returned with its secret",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -181,23 +3729,30 @@ var routeDescriptors = []describe.Route{
 
 								Body: describe.Body{
 									ContentType: "application/json; charset=utf-8",
+									Format:      webhookBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
 					},
 				},
 			},
 		},
 	},
 	{
-		Name:        RouteNamePostByName,
-		Path:        "/v1/blog/posts/{post_name}",
-		Entity:      "Post",
-		Description: "Route to retrieve, update, and delete a single post by name.",
+		Name:        RouteNameWebhook,
+		Path:        "/v1/webhooks/{webhook_id:" + IDRegex.String() + "}",
+		Entity:      "Webhook",
+		Description: "Route to describe, update and delete a single webhook.",
 		Methods: []describe.Method{
 			{
 				Method:      "GET",
-				Description: "Get a post by name",
+				Description: "Get a webhook. Its secret is left out.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
@@ -205,12 +3760,12 @@ var routeDescriptors = []describe.Route{
 						},
 
 						PathParameters: []describe.Parameter{
-							postNameParameter,
+							webhookIDParameter,
 						},
 
 						Successes: []describe.Response{
 							{
-								Description: "post returned",
+								Description: "Webhook returned",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -219,16 +3774,22 @@ var routeDescriptors = []describe.Route{
 
 								Body: describe.Body{
 									ContentType: "application/json; charset=utf-8",
-									Format:      blogPostBody,
+									Format:      webhookBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
 					},
 				},
 			},
 			{
 				Method:      "PUT",
-				Description: "Modify a single blog post",
+				Description: "Change the url and events of a webhook. The secret is only replaced when one is given.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
@@ -236,12 +3797,17 @@ var routeDescriptors = []describe.Route{
 						},
 
 						PathParameters: []describe.Parameter{
-							postNameParameter,
+							webhookIDParameter,
+						},
+
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      webhookBody,
 						},
 
 						Successes: []describe.Response{
 							{
-								Description: "user returned",
+								Description: "Webhook updated and returned",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -250,16 +3816,23 @@ var routeDescriptors = []describe.Route{
 
 								Body: describe.Body{
 									ContentType: "application/json; charset=utf-8",
-									Format:      blogPostBody,
+									Format:      webhookBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
 					},
 				},
 			},
 			{
 				Method:      "DELETE",
-				Description: "Delete a post by name",
+				Description: "Delete a webhook along with its delivery log. Deliveries waiting to be retried are abandoned.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
@@ -267,12 +3840,12 @@ var routeDescriptors = []describe.Route{
 						},
 
 						PathParameters: []describe.Parameter{
-							postNameParameter,
+							webhookIDParameter,
 						},
 
 						Successes: []describe.Response{
 							{
-								Description: "post removed",
+								Description: "Webhook deleted",
 								StatusCode:  http.StatusNoContent,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -280,29 +3853,39 @@ var routeDescriptors = []describe.Route{
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
 					},
 				},
 			},
 		},
 	},
 	{
-		Name:        RouteNameBlog,
-		Path:        "/v1/blog/posts",
-		Entity:      "[]Post",
-		Description: "Route to retrieve the list of posts and create new ones.",
+		Name:        RouteNameWebhookDeliveries,
+		Path:        "/v1/webhooks/{webhook_id:" + IDRegex.String() + "}/deliveries",
+		Entity:      "[]Delivery",
+		Description: "Route to retrieve the delivery log of a webhook.",
 		Methods: []describe.Method{
 			{
 				Method:      "GET",
-				Description: "Get all posts",
+				Description: "Get the most recent delivery attempts to the webhook, newest first",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
 						},
 
+						PathParameters: []describe.Parameter{
+							webhookIDParameter,
+						},
+
 						Successes: []describe.Response{
 							{
-								Description: "All posts returned",
+								Description: "Deliveries returned",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -311,67 +3894,95 @@ var routeDescriptors = []describe.Route{
 
 								Body: describe.Body{
 									ContentType: "application/json; charset=utf-8",
-									Format:      blogPostListBody,
+									Format:      deliveryListBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
 					},
 				},
 			},
+		},
+	},
+	{
+		Name:        RouteNameEvents,
+		Path:        "/v1/events",
+		Entity:      "[]Event",
+		Description: "Route to stream changes to posts, users, comments and media as they happen, using Server-Sent Events.",
+		Methods: []describe.Method{
 			{
-				Method:      "POST",
-				Description: "Create a blog post",
+				Method:      "GET",
+				Description: "Stream events as they happen. Callers only receive events about resources they could read, so anonymous callers only hear about published posts, public profiles and approved comments on published posts.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
+							{
+								Name:        "Last-Event-ID",
+								Type:        "string",
+								Description: "Id of the last event received before reconnecting. The events published since then are sent first, as far as the server still remembers them.",
+								Format:      "<event id>",
+							},
+						},
+
+						QueryParameters: []describe.Parameter{
+							{
+								Name:        "resource",
+								Type:        "string",
+								Description: "Only stream events about a kind of resource, post, user, comment or media, or about a single one given as kind/name. May be given more than once.",
+								Format:      "<kind>[/<name>]",
+							},
 						},
 
 						Successes: []describe.Response{
 							{
-								Description: "Post created",
-								StatusCode:  http.StatusCreated,
+								Description: "Stream of events that stays open until the caller disconnects",
+								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
-									jsonContentLengthHeader,
 								},
 
 								Body: describe.Body{
-									ContentType: "application/json; charset=utf-8",
-									Format:      blogPostBody,
+									ContentType: "text/event-stream",
+									Format:      eventStreamBody,
 								},
 							},
 						},
 
-						Failures: []describe.Response{},
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+						},
 					},
 				},
 			},
 		},
 	},
-
 	{
-		Name:        RouteNameUserByName,
-		Path:        "/v1/users/{user_name}",
-		Entity:      "User",
-		Description: "Route to retrieve, update, and delete a single user by name.",
+		Name:        RouteNameClaims,
+		Path:        "/v1/claims",
+		Entity:      "[]Claim",
+		Description: "Route to list, issue and purge claims, the invitations that let someone without an account create a user or post.",
 		Methods: []describe.Method{
 			{
 				Method:      "GET",
-				Description: "Retrieve a single user",
+				Description: "Get claims, oldest first, including expired, redeemed and revoked ones until they are purged.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
 						},
 
-						PathParameters: []describe.Parameter{
-							userNameParameter,
-						},
+						QueryParameters: claimQueryParameters,
 
 						Successes: []describe.Response{
 							{
-								Description: "user returned",
+								Description: "Claims returned",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -380,29 +3991,36 @@ var routeDescriptors = []describe.Route{
 
 								Body: describe.Body{
 									ContentType: "application/json; charset=utf-8",
-									Format:      userBody,
+									Format:      claimListBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
 					},
 				},
 			},
 			{
-				Method:      "PUT",
-				Description: "Modify a single user",
+				Method:      "POST",
+				Description: "Issue a claim. The code is generated and is sent as the TINKERSNEST-CLAIM header to redeem it. A user claim with an `email` can only create a user with that email, and one with a `role` gives the user that role.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
 						},
 
-						PathParameters: []describe.Parameter{
-							userNameParameter,
+						Body: describe.Body{
+							ContentType: "application/json; charset=utf-8",
+							Format:      claimCreateBody,
 						},
 
 						Successes: []describe.Response{
 							{
-								Description: "user returned",
+								Description: "Claim issued and returned with its code",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -411,50 +4029,65 @@ var routeDescriptors = []describe.Route{
 
 								Body: describe.Body{
 									ContentType: "application/json; charset=utf-8",
-									Format:      userBody,
+									Format:      claimBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							invalidBodyResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
 					},
 				},
 			},
 			{
 				Method:      "DELETE",
-				Description: "Delete a user",
+				Description: "Purge claims that can no longer be redeemed.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
 						},
 
-						PathParameters: []describe.Parameter{
-							postNameParameter,
-						},
+						QueryParameters: claimPurgeQueryParameters,
 
 						Successes: []describe.Response{
 							{
-								Description: "user deleted",
-								StatusCode:  http.StatusNoContent,
+								Description: "Claims purged and returned",
+								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
-									zeroContentLengthHeader,
+									jsonContentLengthHeader,
+								},
+
+								Body: describe.Body{
+									ContentType: "application/json; charset=utf-8",
+									Format:      claimListBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							parameterInvalidResp,
+							unauthorizedResp,
+							forbiddenResp,
+						},
 					},
 				},
 			},
 		},
 	},
 	{
-		Name:        RouteNamePostsByUser,
-		Path:        "/v1/users/{user_name}/posts",
-		Entity:      "[]Post",
-		Description: "Route to retrieve the list of posts from a user.",
+		Name:        RouteNameClaim,
+		Path:        "/v1/claims/{claim_code:" + ClaimCodeRegex.String() + "}",
+		Entity:      "Claim",
+		Description: "Route to describe and revoke a single claim.",
 		Methods: []describe.Method{
 			{
 				Method:      "GET",
-				Description: "Get all posts authored by a user",
+				Description: "Get a claim.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
@@ -462,12 +4095,12 @@ var routeDescriptors = []describe.Route{
 						},
 
 						PathParameters: []describe.Parameter{
-							userNameParameter,
+							claimCodeParameter,
 						},
 
 						Successes: []describe.Response{
 							{
-								Description: "All posts returned",
+								Description: "Claim returned",
 								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
@@ -476,51 +4109,62 @@ var routeDescriptors = []describe.Route{
 
 								Body: describe.Body{
 									ContentType: "application/json; charset=utf-8",
-									Format:      blogPostListBody,
+									Format:      claimBody,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
 					},
 				},
 			},
-		},
-	},
-	{
-		Name:        RouteNameUserRegistry,
-		Path:        "/v1/users",
-		Entity:      "[]User",
-		Description: "Route to retrieve the list of users and create new ones.",
-		Methods: []describe.Method{
 			{
-				Method:      "GET",
-				Description: "Get all users",
+				Method:      "DELETE",
+				Description: "Revoke a claim so it can't be redeemed anymore. It is still listed until it is purged.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
 							hostHeader,
 						},
 
+						PathParameters: []describe.Parameter{
+							claimCodeParameter,
+						},
+
 						Successes: []describe.Response{
 							{
-								Description: "All users returned",
-								StatusCode:  http.StatusOK,
+								Description: "Claim revoked",
+								StatusCode:  http.StatusNoContent,
 								Headers: []describe.Parameter{
 									versionHeader,
-									jsonContentLengthHeader,
-								},
-
-								Body: describe.Body{
-									ContentType: "application/json; charset=utf-8",
-									Format:      userListBody,
+									zeroContentLengthHeader,
 								},
 							},
 						},
+
+						Failures: []describe.Response{
+							unauthorizedResp,
+							forbiddenResp,
+							resourceNotFoundResp,
+						},
 					},
 				},
 			},
+		},
+	},
+	{
+		Name:        RouteNameOpenAPI,
+		Path:        "/v1/openapi.json",
+		Entity:      "OpenAPI",
+		Description: "Route to describe the V1 API as an OpenAPI 3 document generated from these route descriptors.",
+		Methods: []describe.Method{
 			{
-				Method:      "PUT",
-				Description: "Create a user",
+				Method:      "GET",
+				Description: "Get the OpenAPI document for the API.",
 				Requests: []describe.Request{
 					{
 						Headers: []describe.Parameter{
@@ -529,21 +4173,18 @@ var routeDescriptors = []describe.Route{
 
 						Successes: []describe.Response{
 							{
-								Description: "User created",
-								StatusCode:  http.StatusCreated,
+								Description: "The OpenAPI document.",
+								StatusCode:  http.StatusOK,
 								Headers: []describe.Parameter{
 									versionHeader,
-									jsonContentLengthHeader,
 								},
 
 								Body: describe.Body{
-									ContentType: "application/json; charset=utf-8",
-									Format:      userBody,
+									ContentType: "application/json",
+									Format:      openAPIBody,
 								},
 							},
 						},
-
-						Failures: []describe.Response{},
 					},
 				},
 			},
@@ -553,6 +4194,19 @@ var routeDescriptors = []describe.Route{
 
 var routeDescriptorsMap map[string]describe.Route
 
+// withoutParameter returns a copy of the parameters without the named one,
+// for routes where it's given some other way.
+func withoutParameter(params []describe.Parameter, name string) []describe.Parameter {
+	result := make([]describe.Parameter, 0, len(params))
+	for _, p := range params {
+		if p.Name != name {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
+
 func init() {
 	routeDescriptorsMap = make(map[string]describe.Route, len(routeDescriptors))
 	for _, descriptor := range routeDescriptors {
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/danielkrainas/gobag/api/errcode"
@@ -15,4 +16,126 @@ var (
 		Description:    "This is returned if the resource name used during an operation is unknown to the server.",
 		HTTPStatusCode: http.StatusNotFound,
 	})
+
+	ErrorCodeParameterInvalid = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "PARAMETER_INVALID",
+		Message:        "request parameter invalid",
+		Description:    "This is returned if a query or path parameter could not be parsed or has an unsupported value.",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	ErrorCodeUnauthorized = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "UNAUTHORIZED",
+		Message:        "authentication required",
+		Description:    "This is returned if the request needs a valid bearer token and none was provided or it could not be verified.",
+		HTTPStatusCode: http.StatusUnauthorized,
+	})
+
+	ErrorCodeForbidden = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "FORBIDDEN",
+		Message:        "requested access to the resource is denied",
+		Description:    "This is returned if the authenticated user's roles do not allow the operation on the resource.",
+		HTTPStatusCode: http.StatusForbidden,
+	})
+
+	ErrorCodePreconditionFailed = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "PRECONDITION_FAILED",
+		Message:        "resource was changed",
+		Description:    "This is returned if the If-Match header of an update doesn't match the current version of the resource, or it was changed by someone else while being updated.",
+		HTTPStatusCode: http.StatusPreconditionFailed,
+	})
+
+	ErrorCodeUnsupportedMediaType = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "UNSUPPORTED_MEDIA_TYPE",
+		Message:        "request body has an unsupported content type",
+		Description:    "This is returned if the Content-Type of the request body isn't one the operation accepts, such as a PATCH that isn't a JSON Merge Patch or JSON Patch, or media of a type the server doesn't accept.",
+		HTTPStatusCode: http.StatusUnsupportedMediaType,
+	})
+
+	ErrorCodeMediaTooLarge = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "MEDIA_TOO_LARGE",
+		Message:        "media too large",
+		Description:    "This is returned if an uploaded media blob is larger than the server allows.",
+		HTTPStatusCode: http.StatusRequestEntityTooLarge,
+	})
+
+	ErrorCodeBodyInvalid = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "BODY_INVALID",
+		Message:        "request body invalid",
+		Description:    "This is returned if the request body isn't valid JSON or a value in it has the wrong type.",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	ErrorCodeFieldRequired = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "FIELD_REQUIRED",
+		Message:        "required field missing",
+		Description:    "This is returned if a field the resource can't do without, such as the title of a post or the password of a new user, is missing or empty.",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	ErrorCodeFieldInvalid = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "FIELD_INVALID",
+		Message:        "field invalid",
+		Description:    "This is returned if a field of the request body has a value the server doesn't accept.",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	ErrorCodeNameInvalid = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "NAME_INVALID",
+		Message:        "invalid resource name",
+		Description:    "This is returned if the name of a new post, user, collection or item, given or made from its title or full name, can't be used in a URL.",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	ErrorCodeNameTaken = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "NAME_TAKEN",
+		Message:        "resource name already in use",
+		Description:    "This is returned if a post, user or collection item is created with the name of one that already exists.",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	ErrorCodeContentTypeUnsupported = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "CONTENT_TYPE_UNSUPPORTED",
+		Message:        "content type unsupported",
+		Description:    "This is returned if an inline content block has a type other than markdown, text or html.",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	ErrorCodeClaimInvalid = errcode.Register(ErrorGroup, errcode.ErrorDescriptor{
+		Value:          "CLAIM_INVALID",
+		Message:        "claim invalid",
+		Description:    "This is returned if a claim code is unknown, has already been redeemed or is for another kind of resource.",
+		HTTPStatusCode: http.StatusForbidden,
+	})
 )
+
+// FieldError is the detail of validation errors. Field is the path of the
+// rejected value in the request body, like "content[1].type", or the header
+// it came from.
+type FieldError struct {
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ErrorReason describes an error returned by InvalidField with its field and
+// reason, which its Error method leaves out, and any other error with its
+// message.
+func ErrorReason(err error) string {
+	if e, ok := err.(errcode.Error); ok {
+		if fe, ok := e.Detail.(*FieldError); ok {
+			return fmt.Sprintf("%s: %s", fe.Field, fe.Reason)
+		}
+	}
+
+	return err.Error()
+}
+
+// InvalidField returns an error with the code and a FieldError detail.
+func InvalidField(code errcode.ErrorCode, field string, format string, args ...interface{}) error {
+	// ErrorCode.WithDetail loses the detail, so the error is put together here
+	return errcode.Error{
+		Code:    code,
+		Message: code.Message(),
+		Detail:  &FieldError{Field: field, Reason: fmt.Sprintf(format, args...)},
+	}
+}
@@ -0,0 +1,26 @@
+package v1
+
+import "time"
+
+// APIKey is a long-lived credential for automation. It acts as its user but
+// only with the permissions listed in Scopes.
+type APIKey struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	User    string       `json:"user"`
+	Scopes  []Permission `json:"scopes"`
+	Created int64        `json:"created"`
+	Expires int64        `json:"expires,omitempty"`
+
+	// Key is the secret itself. It is only set in the response to creating
+	// the key and is never stored.
+	Key string `json:"key,omitempty"`
+
+	Hash string `json:"-"`
+}
+
+// Expired reports whether the key has passed its expiry time. Keys without
+// one never expire.
+func (k *APIKey) Expired() bool {
+	return k.Expires != 0 && k.Expires <= time.Now().Unix()
+}
@@ -0,0 +1,306 @@
+package v1
+
+type Permission string
+
+var (
+	NoPermission Permission
+
+	PermissionReadPosts   Permission = "posts.read"
+	PermissionWritePosts  Permission = "posts.write"
+	PermissionEditAnyPost Permission = "posts.edit_any"
+	PermissionReadUsers   Permission = "users.read"
+	PermissionEditProfile Permission = "users.edit_self"
+	PermissionManageUsers Permission = "users.manage"
+	PermissionReadMedia   Permission = "media.read"
+	PermissionWriteMedia  Permission = "media.write"
+	PermissionDeleteMedia Permission = "media.delete"
+
+	PermissionWriteComments    Permission = "comments.write"
+	PermissionModerateComments Permission = "comments.moderate"
+
+	PermissionWriteCollections  Permission = "collections.write"
+	PermissionManageCollections Permission = "collections.manage"
+
+	PermissionManageWebhooks Permission = "webhooks.manage"
+
+	PermissionManageClaims Permission = "claims.manage"
+)
+
+var (
+	viewerPermissions = []Permission{
+		PermissionReadPosts,
+		PermissionReadUsers,
+		PermissionEditProfile,
+		PermissionReadMedia,
+		PermissionWriteComments,
+	}
+
+	authorPermissions = append([]Permission{
+		PermissionWritePosts,
+		PermissionWriteMedia,
+	}, viewerPermissions...)
+
+	editorPermissions = append([]Permission{
+		PermissionEditAnyPost,
+		PermissionDeleteMedia,
+		PermissionModerateComments,
+		PermissionWriteCollections,
+	}, authorPermissions...)
+
+	adminPermissions = append([]Permission{
+		PermissionManageUsers,
+		PermissionManageCollections,
+		PermissionManageWebhooks,
+		PermissionManageClaims,
+	}, editorPermissions...)
+
+	rolePermissions = map[Role][]Permission{
+		RoleViewer: viewerPermissions,
+		RoleAuthor: authorPermissions,
+		RoleEditor: editorPermissions,
+		RoleAdmin:  adminPermissions,
+	}
+)
+
+// Valid reports whether the permission is one that roles can grant.
+func (p Permission) Valid() bool {
+	return hasPermission(adminPermissions, p)
+}
+
+func hasPermission(perms []Permission, p Permission) bool {
+	for _, granted := range perms {
+		if granted == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// routePermissions lists the permission needed for each method of a route.
+// Methods that aren't listed can be called without a bearer token.
+//
+// Some routes narrow access further in their handlers. Users holding
+// PermissionWritePosts may only modify posts they authored unless they also
+// have PermissionEditAnyPost, and PermissionEditProfile only covers the
+// user's own account and API keys. Changing tags and categories rewrites
+// other people's posts, so it takes PermissionEditAnyPost. The event stream
+// isn't listed because it checks each event against the permission needed to
+// read what it is about.
+var routePermissions = map[string]map[string]Permission{
+	RouteNameBlog: {
+		"GET":  PermissionReadPosts,
+		"POST": PermissionWritePosts,
+	},
+	RouteNamePostByName: {
+		"GET":    PermissionReadPosts,
+		"PUT":    PermissionWritePosts,
+		"PATCH":  PermissionWritePosts,
+		"DELETE": PermissionWritePosts,
+	},
+	RouteNamePostsByUser: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNamePostRevisions: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNamePostRevision: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNamePostRevisionRestore: {
+		"POST": PermissionWritePosts,
+	},
+	RouteNamePostDiff: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameBlogFeed: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameUserFeed: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameTagFeed: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameTags: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameTagBySlug: {
+		"GET":    PermissionReadPosts,
+		"PUT":    PermissionEditAnyPost,
+		"DELETE": PermissionEditAnyPost,
+	},
+	RouteNameTagPosts: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameTagRename: {
+		"POST": PermissionEditAnyPost,
+	},
+	RouteNameTagMerge: {
+		"POST": PermissionEditAnyPost,
+	},
+	RouteNameCategories: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameCategoryBySlug: {
+		"GET":    PermissionReadPosts,
+		"PUT":    PermissionEditAnyPost,
+		"DELETE": PermissionEditAnyPost,
+	},
+	RouteNameCategoryPosts: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameCategoryRename: {
+		"POST": PermissionEditAnyPost,
+	},
+	RouteNameCategoryMerge: {
+		"POST": PermissionEditAnyPost,
+	},
+	RouteNamePostComments: {
+		"GET":  PermissionReadPosts,
+		"POST": PermissionWriteComments,
+	},
+	RouteNamePostComment: {
+		"GET":    PermissionReadPosts,
+		"DELETE": PermissionWriteComments,
+	},
+	RouteNameComments: {
+		"GET": PermissionModerateComments,
+	},
+	RouteNameCommentModeration: {
+		"POST": PermissionModerateComments,
+	},
+	RouteNameCollections: {
+		"GET": PermissionReadPosts,
+	},
+	RouteNameCollection: {
+		"GET":    PermissionReadPosts,
+		"PUT":    PermissionManageCollections,
+		"DELETE": PermissionManageCollections,
+	},
+	RouteNameCollectionItems: {
+		"GET":  PermissionReadPosts,
+		"POST": PermissionWriteCollections,
+	},
+	RouteNameCollectionItem: {
+		"GET":    PermissionReadPosts,
+		"PUT":    PermissionWriteCollections,
+		"DELETE": PermissionWriteCollections,
+	},
+	RouteNameWebhooks: {
+		"GET":  PermissionManageWebhooks,
+		"POST": PermissionManageWebhooks,
+	},
+	RouteNameWebhook: {
+		"GET":    PermissionManageWebhooks,
+		"PUT":    PermissionManageWebhooks,
+		"DELETE": PermissionManageWebhooks,
+	},
+	RouteNameWebhookDeliveries: {
+		"GET": PermissionManageWebhooks,
+	},
+	RouteNameUserRegistry: {
+		"GET":  PermissionReadUsers,
+		"POST": PermissionManageUsers,
+	},
+	RouteNameUserByName: {
+		"GET":    PermissionReadUsers,
+		"PUT":    PermissionEditProfile,
+		"PATCH":  PermissionEditProfile,
+		"DELETE": PermissionManageUsers,
+	},
+	RouteNameUserSessions: {
+		"GET":    PermissionManageUsers,
+		"DELETE": PermissionManageUsers,
+	},
+	RouteNameUserSessionByID: {
+		"DELETE": PermissionManageUsers,
+	},
+	RouteNameUserKeys: {
+		"GET":  PermissionEditProfile,
+		"POST": PermissionEditProfile,
+	},
+	RouteNameUserKeyByID: {
+		"DELETE": PermissionEditProfile,
+	},
+	RouteNameMedia: {
+		"GET": PermissionReadMedia,
+	},
+	RouteNameMediaByName: {
+		"GET":    PermissionReadMedia,
+		"PUT":    PermissionWriteMedia,
+		"DELETE": PermissionDeleteMedia,
+	},
+	RouteNameMediaMeta: {
+		"GET": PermissionReadMedia,
+	},
+	RouteNameClaims: {
+		"GET":    PermissionManageClaims,
+		"POST":   PermissionManageClaims,
+		"DELETE": PermissionManageClaims,
+	},
+	RouteNameClaim: {
+		"GET":    PermissionManageClaims,
+		"DELETE": PermissionManageClaims,
+	},
+}
+
+// anonymousRoutes lists the methods that still need a permission when called
+// with a bearer token but are also open to anonymous readers. Their handlers
+// only show anonymous callers published posts, items and public profiles, and
+// feeds and taxonomy counts never include anything else. Anonymous comments
+// are held for moderation. Media can be downloaded so that the images in
+// rendered posts and feeds load, but not listed.
+var anonymousRoutes = map[string][]string{
+	RouteNameBlog:        {"GET"},
+	RouteNamePostByName:  {"GET"},
+	RouteNamePostsByUser: {"GET"},
+	RouteNameUserByName:  {"GET"},
+	RouteNameBlogFeed:    {"GET"},
+	RouteNameUserFeed:    {"GET"},
+	RouteNameTagFeed:     {"GET"},
+
+	RouteNameTags:           {"GET"},
+	RouteNameTagBySlug:      {"GET"},
+	RouteNameTagPosts:       {"GET"},
+	RouteNameCategories:     {"GET"},
+	RouteNameCategoryBySlug: {"GET"},
+	RouteNameCategoryPosts:  {"GET"},
+
+	RouteNamePostComments: {"GET", "POST"},
+	RouteNamePostComment:  {"GET"},
+
+	RouteNameCollections:     {"GET"},
+	RouteNameCollection:      {"GET"},
+	RouteNameCollectionItems: {"GET"},
+	RouteNameCollectionItem:  {"GET"},
+
+	RouteNameMediaByName: {"GET"},
+}
+
+// AllowsAnonymous reports whether method can be called on the named route
+// without a bearer token.
+func AllowsAnonymous(routeName string, method string) bool {
+	if method == "HEAD" {
+		method = "GET"
+	}
+
+	for _, m := range anonymousRoutes[routeName] {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RoutePermission returns the permission required to call method on the named
+// route, or NoPermission if the call is open to anyone. HEAD requests are
+// served by the GET handler so they need the same permission.
+func RoutePermission(routeName string, method string) Permission {
+	if method == "HEAD" {
+		method = "GET"
+	}
+
+	return routePermissions[routeName][method]
+}
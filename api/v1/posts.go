@@ -1,5 +1,18 @@
 package v1
 
+import (
+	"fmt"
+)
+
+type PostState string
+
+var (
+	PostDraft     PostState = "draft"
+	PostScheduled PostState = "scheduled"
+	PostPublished PostState = "published"
+	PostArchived  PostState = "archived"
+)
+
 type Post struct {
 	Name    string     `json:"name" yaml:"name"`
 	Title   string     `json:"title" yaml:"title"`
@@ -8,6 +21,60 @@ type Post struct {
 	Created int64      `json:"created" yaml:"created"`
 	Content []*Content `json:"content" yaml:"content"`
 	Tags    []string   `json:"tags" yaml:"tags"`
+
+	// Categories lists the slugs of the categories the post is filed under.
+	Categories []string `json:"categories,omitempty" yaml:"categories,omitempty"`
+
+	// PublishAt embargoes the post until the given unix time, after which
+	// the scheduler publishes it.
+	PublishAt int64 `json:"publish_at,omitempty" yaml:"publish_at,omitempty"`
+	// UnpublishAt archives a published post at the given unix time.
+	UnpublishAt int64 `json:"unpublish_at,omitempty" yaml:"unpublish_at,omitempty"`
+	// State is maintained by the server from Publish and the schedule.
+	State PostState `json:"state,omitempty" yaml:"state,omitempty"`
+	// Version counts the changes to the post and is its ETag.
+	Version int64 `json:"version" yaml:"version,omitempty"`
+}
+
+// ScheduledState works out which state the post should be in at the given
+// unix time. An embargo in the future holds the post back even when Publish
+// is set, and a passed UnpublishAt archives it.
+func (p *Post) ScheduledState(now int64) PostState {
+	switch {
+	case p.PublishAt > now:
+		return PostScheduled
+	case !p.Publish:
+		return PostDraft
+	case p.UnpublishAt > 0 && p.UnpublishAt <= now:
+		return PostArchived
+	}
+
+	return PostPublished
+}
+
+// Validate checks the post's title, schedule and content. It doesn't check
+// the name, which is only validated for new posts, nor anything that has to
+// be looked up, like blobs and categories.
+func (p *Post) Validate() error {
+	if p.Title == "" {
+		return InvalidField(ErrorCodeFieldRequired, "title", "title is required")
+	}
+
+	if p.PublishAt < 0 {
+		return InvalidField(ErrorCodeFieldInvalid, "publish_at", "must be a unix timestamp")
+	} else if p.UnpublishAt < 0 {
+		return InvalidField(ErrorCodeFieldInvalid, "unpublish_at", "must be a unix timestamp")
+	} else if p.PublishAt > 0 && p.UnpublishAt > 0 && p.UnpublishAt <= p.PublishAt {
+		return InvalidField(ErrorCodeFieldInvalid, "unpublish_at", "must be after publish_at")
+	}
+
+	for i, c := range p.Content {
+		if err := c.Validate(fmt.Sprintf("content[%d]", i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 type Author struct {
@@ -27,4 +94,40 @@ type Content struct {
 	Type string `json:"type" yaml:"type"`
 	Data []byte `json:"data" yaml:"data"`
 	Rel  string `json:"rel" yaml:"rel"`
+
+	// Blob names an uploaded media blob to use instead of inline Data.
+	Blob string `json:"blob,omitempty" yaml:"blob,omitempty"`
+}
+
+// Valid reports whether content of the type can be stored inline.
+func (t ContentType) Valid() bool {
+	switch t {
+	case ContentMarkdown, ContentText, ContentHtml:
+		return true
+	}
+
+	return false
+}
+
+// Validate checks a content block, field being its path in the request, like
+// "content[0]". Inline data must be of one of the ContentTypes while a blob
+// can be of any media type.
+func (c *Content) Validate(field string) error {
+	switch {
+	case c == nil:
+		return InvalidField(ErrorCodeFieldRequired, field, "content can't be null")
+	case c.Type == "":
+		return InvalidField(ErrorCodeFieldRequired, field+".type", "type is required")
+	case c.Blob == "":
+		if !ContentType(c.Type).Valid() {
+			return InvalidField(ErrorCodeContentTypeUnsupported, field+".type", "inline content can't be %q, only markdown, text or html", c.Type)
+		}
+
+	case len(c.Data) > 0:
+		return InvalidField(ErrorCodeFieldInvalid, field+".data", "content can't have both data and a blob")
+	case !blobNameRegex.MatchString(c.Blob):
+		return InvalidField(ErrorCodeFieldInvalid, field+".blob", "invalid blob name %q", c.Blob)
+	}
+
+	return nil
 }
@@ -0,0 +1,22 @@
+package v1
+
+// Revision is an immutable snapshot of a post, taken every time the post is
+// stored. Numbers start at 1 and increase with every change.
+type Revision struct {
+	Number  int    `json:"number"`
+	User    string `json:"user"`
+	Created int64  `json:"created"`
+
+	// RestoredFrom is the number of the revision this one restored, if any.
+	RestoredFrom int `json:"restored_from,omitempty"`
+
+	Post *Post `json:"post"`
+}
+
+// RevisionDiff is a unified diff between two revisions of a post.
+type RevisionDiff struct {
+	Post string `json:"post"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
+}
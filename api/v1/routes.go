@@ -10,6 +10,57 @@ const (
 	RouteNameUserRegistry = "users"
 	RouteNameUserByName   = "user-by-name"
 	RouteNameAuth         = "auth"
+	RouteNameAuthKeys     = "auth-keys"
+	RouteNameMedia        = "media"
+	RouteNameMediaByName  = "media-by-name"
+	RouteNameMediaMeta    = "media-meta"
+
+	RouteNameUserSessions    = "user-sessions"
+	RouteNameUserSessionByID = "user-session-by-id"
+	RouteNameUserKeys        = "user-keys"
+	RouteNameUserKeyByID     = "user-key-by-id"
+
+	RouteNamePostRevisions       = "post-revisions"
+	RouteNamePostRevision        = "post-revision"
+	RouteNamePostRevisionRestore = "post-revision-restore"
+	RouteNamePostDiff            = "post-diff"
+
+	RouteNameBlogFeed = "blog-feed"
+	RouteNameUserFeed = "user-feed"
+	RouteNameTagFeed  = "tag-feed"
+
+	RouteNameTags      = "tags"
+	RouteNameTagBySlug = "tag-by-slug"
+	RouteNameTagPosts  = "tag-posts"
+	RouteNameTagRename = "tag-rename"
+	RouteNameTagMerge  = "tag-merge"
+
+	RouteNameCategories     = "categories"
+	RouteNameCategoryBySlug = "category-by-slug"
+	RouteNameCategoryPosts  = "category-posts"
+	RouteNameCategoryRename = "category-rename"
+	RouteNameCategoryMerge  = "category-merge"
+
+	RouteNamePostComments      = "post-comments"
+	RouteNamePostComment       = "post-comment"
+	RouteNameComments          = "comments"
+	RouteNameCommentModeration = "comment-moderation"
+
+	RouteNameCollections     = "collections"
+	RouteNameCollection      = "collection"
+	RouteNameCollectionItems = "collection-items"
+	RouteNameCollectionItem  = "collection-item"
+
+	RouteNameWebhooks          = "webhooks"
+	RouteNameWebhook           = "webhook"
+	RouteNameWebhookDeliveries = "webhook-deliveries"
+
+	RouteNameEvents = "events"
+
+	RouteNameClaims = "claims"
+	RouteNameClaim  = "claim"
+
+	RouteNameOpenAPI = "openapi"
 )
 
 func Router() *mux.Router {
@@ -0,0 +1,35 @@
+package v1
+
+import "time"
+
+// Session is a login that can be renewed with its refresh token until it
+// expires or is revoked. Only a hash of the refresh token is kept.
+type Session struct {
+	ID        string `json:"id"`
+	User      string `json:"user"`
+	Created   int64  `json:"created"`
+	Refreshed int64  `json:"refreshed"`
+	Expires   int64  `json:"expires"`
+	UserAgent string `json:"user_agent"`
+
+	RefreshHash string `json:"-"`
+}
+
+func (s *Session) Expired() bool {
+	return s.Expires <= time.Now().Unix()
+}
+
+// AuthRequest is sent to log in, either with a user's credentials or with the
+// refresh token of an existing session.
+type AuthRequest struct {
+	Name         string `json:"name,omitempty"`
+	Password     string `json:"password,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
@@ -0,0 +1,31 @@
+package v1
+
+// Tag describes a tag that posts can carry. A tag exists as soon as a post
+// uses it; storing one only adds a title and description.
+type Tag struct {
+	Slug        string `json:"slug" yaml:"slug"`
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Count is the number of posts with the tag, filled in by the server.
+	Count int `json:"count" yaml:"-"`
+}
+
+// Category groups posts under a hierarchy. Unlike tags, categories have to
+// be created before posts can be filed under them.
+type Category struct {
+	Slug        string `json:"slug" yaml:"slug"`
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Parent      string `json:"parent,omitempty" yaml:"parent,omitempty"`
+
+	// Count is the number of posts in the category or any of its
+	// subcategories, filled in by the server.
+	Count int `json:"count" yaml:"-"`
+}
+
+// TermTarget is the body of a rename or merge request and names the tag or
+// category that posts are moved to.
+type TermTarget struct {
+	To string `json:"to"`
+}
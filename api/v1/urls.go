@@ -3,6 +3,7 @@ package v1
 import (
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -91,6 +92,17 @@ func (ub *URLBuilder) BuildAuth() (string, error) {
 	return routeUrl.String(), nil
 }
 
+func (ub *URLBuilder) BuildAuthKeys() (string, error) {
+	route := ub.cloneRoute(RouteNameAuthKeys)
+
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
 func (ub *URLBuilder) BuildUserRegistry() (string, error) {
 	route := ub.cloneRoute(RouteNameUserRegistry)
 
@@ -112,7 +124,47 @@ func (ub *URLBuilder) BuildUserByName(name string) (string, error) {
 	return routeUrl.String(), nil
 }
 
-func (ub *URLBuilder) BuildBlog() (string, error) {
+func (ub *URLBuilder) BuildUserSessions(name string) (string, error) {
+	route := ub.cloneRoute(RouteNameUserSessions)
+	routeUrl, err := route.URL("user_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildUserSessionByID(name string, id string) (string, error) {
+	route := ub.cloneRoute(RouteNameUserSessionByID)
+	routeUrl, err := route.URL("user_name", name, "session_id", id)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildUserKeys(name string) (string, error) {
+	route := ub.cloneRoute(RouteNameUserKeys)
+	routeUrl, err := route.URL("user_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildUserKeyByID(name string, id string) (string, error) {
+	route := ub.cloneRoute(RouteNameUserKeyByID)
+	routeUrl, err := route.URL("user_name", name, "key_id", id)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildBlog(values ...url.Values) (string, error) {
 	route := ub.cloneRoute(RouteNameBlog)
 
 	routeUrl, err := route.URL()
@@ -120,7 +172,17 @@ func (ub *URLBuilder) BuildBlog() (string, error) {
 		return "", err
 	}
 
-	return routeUrl.String(), nil
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildPostsByUser(name string, values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNamePostsByUser)
+	routeUrl, err := route.URL("user_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
 }
 
 func (ub *URLBuilder) BuildPostByName(name string) (string, error) {
@@ -133,6 +195,374 @@ func (ub *URLBuilder) BuildPostByName(name string) (string, error) {
 	return routeUrl.String(), nil
 }
 
+func (ub *URLBuilder) BuildPostRevisions(name string) (string, error) {
+	route := ub.cloneRoute(RouteNamePostRevisions)
+	routeUrl, err := route.URL("post_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildPostRevision(name string, revision int) (string, error) {
+	route := ub.cloneRoute(RouteNamePostRevision)
+	routeUrl, err := route.URL("post_name", name, "revision", strconv.Itoa(revision))
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildPostRevisionRestore(name string, revision int) (string, error) {
+	route := ub.cloneRoute(RouteNamePostRevisionRestore)
+	routeUrl, err := route.URL("post_name", name, "revision", strconv.Itoa(revision))
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildPostDiff(name string, values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNamePostDiff)
+	routeUrl, err := route.URL("post_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildBlogFeed(format string) (string, error) {
+	route := ub.cloneRoute(RouteNameBlogFeed)
+	routeUrl, err := route.URL("format", format)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildUserFeed(name string, format string) (string, error) {
+	route := ub.cloneRoute(RouteNameUserFeed)
+	routeUrl, err := route.URL("user_name", name, "format", format)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildTagFeed(tag string, format string) (string, error) {
+	route := ub.cloneRoute(RouteNameTagFeed)
+	routeUrl, err := route.URL("tag", tag, "format", format)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildTags() (string, error) {
+	route := ub.cloneRoute(RouteNameTags)
+
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildTagBySlug(slug string) (string, error) {
+	route := ub.cloneRoute(RouteNameTagBySlug)
+	routeUrl, err := route.URL("tag", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildTagPosts(slug string, values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNameTagPosts)
+	routeUrl, err := route.URL("tag", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildTagRename(slug string) (string, error) {
+	route := ub.cloneRoute(RouteNameTagRename)
+	routeUrl, err := route.URL("tag", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildTagMerge(slug string) (string, error) {
+	route := ub.cloneRoute(RouteNameTagMerge)
+	routeUrl, err := route.URL("tag", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCategories() (string, error) {
+	route := ub.cloneRoute(RouteNameCategories)
+
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCategoryBySlug(slug string) (string, error) {
+	route := ub.cloneRoute(RouteNameCategoryBySlug)
+	routeUrl, err := route.URL("category", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCategoryPosts(slug string, values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNameCategoryPosts)
+	routeUrl, err := route.URL("category", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildCategoryRename(slug string) (string, error) {
+	route := ub.cloneRoute(RouteNameCategoryRename)
+	routeUrl, err := route.URL("category", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCategoryMerge(slug string) (string, error) {
+	route := ub.cloneRoute(RouteNameCategoryMerge)
+	routeUrl, err := route.URL("category", slug)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildPostComments(name string, values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNamePostComments)
+	routeUrl, err := route.URL("post_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildPostComment(name string, id string) (string, error) {
+	route := ub.cloneRoute(RouteNamePostComment)
+	routeUrl, err := route.URL("post_name", name, "comment_id", id)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildComments(values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNameComments)
+
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildCommentModeration() (string, error) {
+	route := ub.cloneRoute(RouteNameCommentModeration)
+
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCollections() (string, error) {
+	route := ub.cloneRoute(RouteNameCollections)
+
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCollection(name string) (string, error) {
+	route := ub.cloneRoute(RouteNameCollection)
+	routeUrl, err := route.URL("collection", name)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCollectionItems(collection string) (string, error) {
+	route := ub.cloneRoute(RouteNameCollectionItems)
+	routeUrl, err := route.URL("collection", collection)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildCollectionItem(collection string, name string) (string, error) {
+	route := ub.cloneRoute(RouteNameCollectionItem)
+	routeUrl, err := route.URL("collection", collection, "item_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildWebhooks() (string, error) {
+	route := ub.cloneRoute(RouteNameWebhooks)
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildWebhook(id string) (string, error) {
+	route := ub.cloneRoute(RouteNameWebhook)
+	routeUrl, err := route.URL("webhook_id", id)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildWebhookDeliveries(id string) (string, error) {
+	route := ub.cloneRoute(RouteNameWebhookDeliveries)
+	routeUrl, err := route.URL("webhook_id", id)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildEvents(values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNameEvents)
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildClaims(values ...url.Values) (string, error) {
+	route := ub.cloneRoute(RouteNameClaims)
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return appendValuesURL(routeUrl, values...).String(), nil
+}
+
+func (ub *URLBuilder) BuildClaim(code string) (string, error) {
+	route := ub.cloneRoute(RouteNameClaim)
+	routeUrl, err := route.URL("claim_code", code)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildOpenAPI() (string, error) {
+	route := ub.cloneRoute(RouteNameOpenAPI)
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func appendValuesURL(u *url.URL, values ...url.Values) *url.URL {
+	merged := u.Query()
+	for _, v := range values {
+		for k, vv := range v {
+			merged[k] = append(merged[k], vv...)
+		}
+	}
+
+	u.RawQuery = merged.Encode()
+	return u
+}
+
+func (ub *URLBuilder) BuildMedia() (string, error) {
+	route := ub.cloneRoute(RouteNameMedia)
+
+	routeUrl, err := route.URL()
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildMediaByName(name string) (string, error) {
+	route := ub.cloneRoute(RouteNameMediaByName)
+	routeUrl, err := route.URL("blob_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
+func (ub *URLBuilder) BuildMediaMeta(name string) (string, error) {
+	route := ub.cloneRoute(RouteNameMediaMeta)
+	routeUrl, err := route.URL("blob_name", name)
+	if err != nil {
+		return "", err
+	}
+
+	return routeUrl.String(), nil
+}
+
 type clonedRoute struct {
 	*mux.Route
 
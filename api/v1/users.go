@@ -1,11 +1,103 @@
 package v1
 
+import (
+	"fmt"
+	"net/mail"
+)
+
+type Role string
+
+var (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleAuthor Role = "author"
+	RoleViewer Role = "viewer"
+
+	// DefaultRole is given to users that are created without any roles.
+	DefaultRole = RoleAuthor
+)
+
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
 type User struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	FullName string `json:"full_name"`
 	Password string `json:"password"`
+	Roles    []Role `json:"roles"`
+	// Version counts the changes to the user and is its ETag.
+	Version int64 `json:"version"`
 
 	Salt           []byte `json:"-"`
 	HashedPassword string `json:"-"`
+
+	// scopes limits the permissions the user's roles grant, when set.
+	scopes []Permission
+}
+
+// Validate checks the user's email and roles. The name is only validated for
+// new users, and the password only needs to be set when the user is created.
+func (u *User) Validate() error {
+	if u.Email != "" {
+		if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+			return InvalidField(ErrorCodeFieldInvalid, "email", "%q isn't an email address", u.Email)
+		}
+	}
+
+	for i, r := range u.Roles {
+		if !r.Valid() {
+			return InvalidField(ErrorCodeFieldInvalid, fmt.Sprintf("roles[%d]", i), "unknown role %q", r)
+		}
+	}
+
+	return nil
+}
+
+// Restrict returns a copy of the user that can only use the scoped
+// permissions, for requests made with an API key.
+func (u *User) Restrict(scopes []Permission) *User {
+	cp := *u
+	cp.scopes = scopes
+	return &cp
+}
+
+// Can reports whether any of the user's roles grant the permission. Users
+// stored before roles existed are treated as having the DefaultRole.
+func (u *User) Can(p Permission) bool {
+	if p == NoPermission {
+		return true
+	}
+
+	if u.scopes != nil && !hasPermission(u.scopes, p) {
+		return false
+	}
+
+	roles := u.Roles
+	if len(roles) == 0 {
+		roles = []Role{DefaultRole}
+	}
+
+	for _, r := range roles {
+		if hasPermission(rolePermissions[r], p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Profile is the part of a user that anonymous readers can see.
+type Profile struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+}
+
+func (u *User) Profile() *Profile {
+	return &Profile{
+		Name:     u.Name,
+		FullName: u.FullName,
+	}
 }
@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"regexp"
+)
+
+// NameRegex matches the names of posts and users, which are used in URLs.
+// Names made from a title or full name start with a dash if it started with
+// a space, so those are allowed too.
+var NameRegex = regexp.MustCompile(`[A-Za-z0-9_-][A-Za-z0-9._-]*`)
+
+var nameRegex = regexp.MustCompile(`^` + NameRegex.String() + `$`)
+
+// ValidateName checks the name of a new post or user.
+func ValidateName(name string) error {
+	if name == "" {
+		return InvalidField(ErrorCodeNameInvalid, "name", "name is empty")
+	} else if !nameRegex.MatchString(name) {
+		return InvalidField(ErrorCodeNameInvalid, "name", "%q may only have letters, digits, '.', '_' and '-'", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package v1
+
+import "strings"
+
+// EventType names a change to a resource, such as "post.published".
+type EventType string
+
+const (
+	EventPostCreated   EventType = "post.created"
+	EventPostUpdated   EventType = "post.updated"
+	EventPostDeleted   EventType = "post.deleted"
+	EventPostPublished EventType = "post.published"
+
+	EventUserCreated EventType = "user.created"
+	EventUserUpdated EventType = "user.updated"
+	EventUserDeleted EventType = "user.deleted"
+
+	EventCommentCreated EventType = "comment.created"
+	EventCommentUpdated EventType = "comment.updated"
+	EventCommentDeleted EventType = "comment.deleted"
+	// EventCommentPublished is sent when a comment is approved, either when
+	// it is created or by a moderator.
+	EventCommentPublished EventType = "comment.published"
+
+	EventMediaCreated EventType = "media.created"
+	EventMediaUpdated EventType = "media.updated"
+	EventMediaDeleted EventType = "media.deleted"
+)
+
+var EventTypes = []EventType{
+	EventPostCreated,
+	EventPostUpdated,
+	EventPostDeleted,
+	EventPostPublished,
+	EventUserCreated,
+	EventUserUpdated,
+	EventUserDeleted,
+	EventCommentCreated,
+	EventCommentUpdated,
+	EventCommentDeleted,
+	EventCommentPublished,
+	EventMediaCreated,
+	EventMediaUpdated,
+	EventMediaDeleted,
+}
+
+func (t EventType) Valid() bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Kind returns the kind of resource the event is about: "post", "user",
+// "comment" or "media".
+func (t EventType) Kind() string {
+	return strings.SplitN(string(t), ".", 2)[0]
+}
+
+// Event describes a change to a resource. Resource is the name or id of what
+// changed and Data holds it as it was after the change. Deletions carry no
+// data.
+type Event struct {
+	ID       string      `json:"id"`
+	Type     EventType   `json:"type"`
+	Resource string      `json:"resource"`
+	Created  int64       `json:"created"`
+	Data     interface{} `json:"data,omitempty"`
+}
+
+// Webhook is a URL that is sent the events it subscribes to. Without any
+// events it is sent all of them.
+type Webhook struct {
+	ID      string      `json:"id"`
+	URL     string      `json:"url"`
+	Events  []EventType `json:"events"`
+	User    string      `json:"user"`
+	Created int64       `json:"created"`
+
+	// Secret signs every delivery. It is only returned in the response to
+	// creating the webhook.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Wants reports whether the webhook subscribes to the event type.
+func (h *Webhook) Wants(t EventType) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+
+	for _, e := range h.Events {
+		if e == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery records one attempt at sending an event to a webhook. Retries of
+// the same event share an ID and count up Attempt.
+type Delivery struct {
+	ID      string    `json:"id"`
+	Webhook string    `json:"webhook"`
+	Event   string    `json:"event"`
+	Type    EventType `json:"type"`
+	Attempt int       `json:"attempt"`
+	Created int64     `json:"created"`
+
+	// Status is the response status code, if there was a response.
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	// Duration is how long the attempt took, in milliseconds.
+	Duration int64 `json:"duration"`
+}
+
+// Succeeded reports whether the webhook accepted the delivery.
+func (d *Delivery) Succeeded() bool {
+	return d.Error == "" && d.Status >= 200 && d.Status < 300
+}
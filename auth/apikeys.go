@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// APIKeyPrefix starts every API key, which tells them apart from bearer
+	// tokens and makes them easy to spot when they leak.
+	APIKeyPrefix = "tnk_"
+
+	API_KEY_ID_SIZE     = 8
+	API_KEY_SECRET_SIZE = 32
+)
+
+var ErrAPIKeyInvalid = errors.New("api key invalid")
+
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// GenerateAPIKey returns the id of a new key, the key itself and the hash
+// that should be stored in its place.
+func GenerateAPIKey() (string, string, string, error) {
+	id := make([]byte, API_KEY_ID_SIZE)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return "", "", "", err
+	}
+
+	secret := make([]byte, API_KEY_SECRET_SIZE)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return "", "", "", err
+	}
+
+	keyID := hex.EncodeToString(id)
+	encoded := hex.EncodeToString(secret)
+	return keyID, APIKeyPrefix + keyID + "_" + encoded, hashSecret(encoded), nil
+}
+
+// ParseAPIKey splits an API key into its id and the hash of its secret.
+func ParseAPIKey(key string) (string, string, error) {
+	if !IsAPIKey(key) {
+		return "", "", ErrAPIKeyInvalid
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(key, APIKeyPrefix), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrAPIKeyInvalid
+	}
+
+	return parts[0], hashSecret(parts[1]), nil
+}
@@ -2,21 +2,14 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/sha512"
-	"errors"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"time"
 
 	"golang.org/x/crypto/pbkdf2"
-	"gopkg.in/square/go-jose.v2"
-	"gopkg.in/square/go-jose.v2/jwt"
-
-	"github.com/danielkrainas/tinkersnest/api/v1"
-)
-
-var (
-	sharedKey = []byte("secret foo")
 )
 
 const (
@@ -40,39 +33,15 @@ func HashPassword(password string, salt []byte) string {
 	return fmt.Sprintf("%x", dk)
 }
 
-func BearerToken(u *v1.User) (string, error) {
-	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: sharedKey}, nil)
-	if err != nil {
-		return "", err
-	}
-
-	c := jwt.Claims{
-		Subject: u.Name,
-		Issuer:  "tinkernest",
-		Expiry:  jwt.NewNumericDate(time.Now().Add(5 * time.Hour)),
-	}
-
-	return jwt.Signed(sig).Claims(c).CompactSerialize()
+// SecretHashEqual compares two hashes of refresh token or API key secrets in
+// constant time.
+func SecretHashEqual(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
 
-func VerifyBearerToken(rawToken string) (string, error) {
-	token, err := jwt.ParseSigned(rawToken)
-	if err != nil {
-		return "", err
-	}
-
-	c := &jwt.Claims{}
-	if err := token.Claims(sharedKey, c); err != nil {
-		return "", err
-	}
-
-	err = c.Validate(jwt.Expected{
-		Issuer: "tinkersnest",
-	})
-
-	if err != nil {
-		return "", errors.New("token invalid")
-	}
-
-	return c.Subject, nil
+// hashSecret hashes a random token secret for storage. Unlike passwords the
+// secrets are long and random, so a fast unsalted hash is enough.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
 }
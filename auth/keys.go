@@ -0,0 +1,380 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/configuration"
+)
+
+const (
+	DefaultIssuer        = "tinkersnest"
+	DefaultTokenLifetime = 5 * time.Hour
+
+	DefaultSessionLifetime = 30 * 24 * time.Hour
+
+	ephemeralKeyID = "ephemeral"
+)
+
+var (
+	ErrTokenInvalid = errors.New("token invalid")
+	ErrUnknownKey   = errors.New("token signed with an unknown key")
+)
+
+type signingKey struct {
+	id        string
+	algorithm jose.SignatureAlgorithm
+
+	// private is nil for keys that can only verify tokens. HS256 keys use the
+	// same secret for both.
+	private interface{}
+	public  interface{}
+}
+
+// KeySet signs new bearer tokens with one key and accepts tokens signed by
+// any of its keys, so keys can be rotated without logging everyone out.
+type KeySet struct {
+	issuer          string
+	lifetime        time.Duration
+	sessionLifetime time.Duration
+	signer          *signingKey
+	keys            map[string]*signingKey
+	ephemeral       bool
+}
+
+func KeySetFromConfig(config *configuration.Config) (*KeySet, error) {
+	ac := config.Auth
+	ks := &KeySet{
+		issuer:          ac.Issuer,
+		lifetime:        ac.TokenLifetime,
+		sessionLifetime: ac.SessionLifetime,
+		keys:            make(map[string]*signingKey),
+	}
+
+	if ks.issuer == "" {
+		ks.issuer = DefaultIssuer
+	}
+
+	if ks.lifetime == 0 {
+		ks.lifetime = DefaultTokenLifetime
+	} else if ks.lifetime < 0 {
+		return nil, fmt.Errorf("auth token lifetime must be positive")
+	}
+
+	if ks.sessionLifetime == 0 {
+		ks.sessionLifetime = DefaultSessionLifetime
+	} else if ks.sessionLifetime < 0 {
+		return nil, fmt.Errorf("auth session lifetime must be positive")
+	}
+
+	if len(ac.Keys) == 0 {
+		secret := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+			return nil, err
+		}
+
+		ks.signer = &signingKey{
+			id:        ephemeralKeyID,
+			algorithm: jose.HS256,
+			private:   secret,
+			public:    secret,
+		}
+
+		ks.keys[ephemeralKeyID] = ks.signer
+		ks.ephemeral = true
+		return ks, nil
+	}
+
+	for _, kc := range ac.Keys {
+		k, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("auth key %q: %v", kc.ID, err)
+		}
+
+		if _, exists := ks.keys[k.id]; exists {
+			return nil, fmt.Errorf("auth key %q declared more than once", k.id)
+		}
+
+		ks.keys[k.id] = k
+	}
+
+	signerID := ac.SigningKey
+	if signerID == "" {
+		for _, kc := range ac.Keys {
+			if ks.keys[kc.ID].private != nil {
+				signerID = kc.ID
+				break
+			}
+		}
+	}
+
+	ks.signer = ks.keys[signerID]
+	if ks.signer == nil {
+		return nil, fmt.Errorf("auth signing key %q not found", signerID)
+	} else if ks.signer.private == nil {
+		return nil, fmt.Errorf("auth signing key %q has no private key", signerID)
+	}
+
+	return ks, nil
+}
+
+func loadKey(kc configuration.AuthKeyConfig) (*signingKey, error) {
+	if kc.ID == "" {
+		return nil, errors.New("id is required")
+	} else if kc.File == "" {
+		return nil, errors.New("file is required")
+	}
+
+	data, err := ioutil.ReadFile(kc.File)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &signingKey{
+		id:        kc.ID,
+		algorithm: jose.SignatureAlgorithm(strings.ToUpper(kc.Algorithm)),
+	}
+
+	switch k.algorithm {
+	case jose.HS256:
+		secret := []byte(strings.TrimSpace(string(data)))
+		if len(secret) < 32 {
+			return nil, errors.New("HS256 secrets must be at least 32 bytes")
+		}
+
+		k.private = secret
+		k.public = secret
+
+	case jose.RS256, jose.ES256:
+		if k.private, k.public, err = parsePEMKey(data); err != nil {
+			return nil, err
+		}
+
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			if k.algorithm != jose.RS256 {
+				return nil, fmt.Errorf("RSA key cannot be used with %s", k.algorithm)
+			}
+
+		case *ecdsa.PublicKey:
+			if k.algorithm != jose.ES256 || pub.Curve != elliptic.P256() {
+				return nil, fmt.Errorf("%s requires a P-256 key", jose.ES256)
+			}
+
+		default:
+			return nil, fmt.Errorf("unsupported key type %T", pub)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, must be HS256, RS256 or ES256", kc.Algorithm)
+	}
+
+	return k, nil
+}
+
+// parsePEMKey reads an RSA or EC private key, or a public key when only
+// verification is needed, in any of the usual PEM encodings.
+func parsePEMKey(data []byte) (interface{}, interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return priv, priv.Public(), nil
+
+	case "EC PRIVATE KEY":
+		priv, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return priv, priv.Public(), nil
+
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		signer, ok := priv.(crypto.Signer)
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported private key type %T", priv)
+		}
+
+		return priv, signer.Public(), nil
+
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, pub, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+// Ephemeral reports whether the key set was generated at startup because no
+// keys were configured. Tokens signed with it don't survive a restart.
+func (ks *KeySet) Ephemeral() bool {
+	return ks.ephemeral
+}
+
+func (ks *KeySet) TokenLifetime() time.Duration {
+	return ks.lifetime
+}
+
+// SessionLifetime is how long a session stays valid after it was last
+// refreshed.
+func (ks *KeySet) SessionLifetime() time.Duration {
+	return ks.sessionLifetime
+}
+
+// BearerToken issues an access token for the user. The session id is carried
+// in the jti claim so revoking the session invalidates the token as well.
+func (ks *KeySet) BearerToken(u *v1.User, sessionID string) (string, error) {
+	now := time.Now()
+	c := jwt.Claims{
+		ID:       sessionID,
+		Subject:  u.Name,
+		Issuer:   ks.issuer,
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ks.lifetime)),
+	}
+
+	if ks.signer.algorithm == jose.HS256 {
+		return signHMAC(ks.signer, c)
+	}
+
+	sig, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: ks.signer.algorithm,
+		Key: jose.JSONWebKey{
+			Key:   ks.signer.private,
+			KeyID: ks.signer.id,
+		},
+	}, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	return jwt.Signed(sig).Claims(c).CompactSerialize()
+}
+
+// signHMAC serializes an HS256 token by hand because the vendored go-jose
+// drops the key id from symmetrically signed headers.
+func signHMAC(k *signingKey, claims interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": string(k.algorithm),
+		"kid": k.id,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, k.private.([]byte))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyBearerToken checks the token's signature, issuer and expiry and
+// returns the name of the user and the id of the session it was issued to.
+func (ks *KeySet) VerifyBearerToken(rawToken string) (string, string, error) {
+	token, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(token.Headers) != 1 {
+		return "", "", ErrTokenInvalid
+	}
+
+	header := token.Headers[0]
+	k, ok := ks.keys[header.KeyID]
+	if !ok {
+		return "", "", ErrUnknownKey
+	} else if header.Algorithm != string(k.algorithm) {
+		return "", "", ErrTokenInvalid
+	}
+
+	c := &jwt.Claims{}
+	if err := token.Claims(k.public, c); err != nil {
+		return "", "", err
+	}
+
+	err = c.Validate(jwt.Expected{
+		Issuer: ks.issuer,
+		Time:   time.Now(),
+	})
+
+	if err != nil || c.Subject == "" || c.ID == "" {
+		return "", "", ErrTokenInvalid
+	}
+
+	return c.Subject, c.ID, nil
+}
+
+// PublicKeys returns the asymmetric keys of the set as a JWKS document. HS256
+// secrets are never published.
+func (ks *KeySet) PublicKeys() *jose.JSONWebKeySet {
+	set := &jose.JSONWebKeySet{
+		Keys: make([]jose.JSONWebKey, 0),
+	}
+
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		k := ks.keys[id]
+		if k.algorithm == jose.HS256 {
+			continue
+		}
+
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       k.public,
+			KeyID:     k.id,
+			Algorithm: string(k.algorithm),
+			Use:       "sig",
+		})
+	}
+
+	return set
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	SESSION_ID_SIZE     = 16
+	REFRESH_SECRET_SIZE = 32
+)
+
+var ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+
+func GenerateSessionID() (string, error) {
+	id := make([]byte, SESSION_ID_SIZE)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+// GenerateRefreshToken returns a new refresh token for the session and the
+// hash that should be stored in its place. The token is the session id
+// followed by a random secret.
+func GenerateRefreshToken(sessionID string) (string, string, error) {
+	secret := make([]byte, REFRESH_SECRET_SIZE)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return "", "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	return sessionID + "." + encoded, hashSecret(encoded), nil
+}
+
+// ParseRefreshToken splits a refresh token into the id of its session and the
+// hash of its secret.
+func ParseRefreshToken(token string) (string, string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrRefreshTokenInvalid
+	}
+
+	return parts[0], hashSecret(parts[1]), nil
+}
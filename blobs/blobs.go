@@ -4,9 +4,18 @@ import (
 	"errors"
 )
 
-var ErrUnknown = errors.New("blob unknown")
+var (
+	ErrUnknown     = errors.New("blob unknown")
+	ErrInvalidName = errors.New("blob name invalid")
+)
+
+const (
+	MetaContentType = "content-type"
+	MetaSize        = "size"
+	MetaCreated     = "created"
+)
 
 type Blob struct {
-	Name string
-	Meta map[string]string
+	Name string            `json:"name"`
+	Meta map[string]string `json:"meta"`
 }
@@ -8,11 +8,19 @@ import (
 	"github.com/danielkrainas/tinkersnest/blobs"
 )
 
+// Writer writes the data of a blob. Close makes it the blob's data, and
+// Cancel throws it away and leaves the blob as it was.
+type Writer interface {
+	io.WriteCloser
+	Cancel() error
+}
+
 type Driver interface {
 	drivers.DriverBase
 
+	List() ([]string, error)
 	Inspect(name string) (*blobs.Blob, error)
-	Writer(name string) (io.WriteCloser, error)
+	Writer(name string) (Writer, error)
 	Reader(name string) (io.ReadCloser, error)
 	WriteMeta(name string, b *blobs.Blob) error
 	Drop(name string) (bool, error)
@@ -0,0 +1,276 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/danielkrainas/gobag/decouple/drivers"
+
+	"github.com/danielkrainas/tinkersnest/blobs"
+	"github.com/danielkrainas/tinkersnest/blobs/driver"
+	"github.com/danielkrainas/tinkersnest/blobs/driver/factory"
+)
+
+const (
+	metaSuffix = ".meta"
+	tempSuffix = ".tmp"
+)
+
+type driverFactory struct{}
+
+func (df *driverFactory) Create(parameters map[string]interface{}) (drivers.DriverBase, error) {
+	root, ok := parameters["rootdirectory"].(string)
+	if !ok || root == "" {
+		return nil, errors.New("rootdirectory parameter invalid or missing")
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	return &fsDriver{
+		root: root,
+	}, nil
+}
+
+func init() {
+	factory.Register("filesystem", &driverFactory{})
+}
+
+// fsDriver keeps each blob in a file named after it under the root directory.
+// Metadata lives in a hidden sidecar file next to it and uploads are staged in
+// hidden temp files, neither of which can collide with a valid blob name.
+type fsDriver struct {
+	m    sync.Mutex
+	root string
+}
+
+var _ driver.Driver = &fsDriver{}
+
+func (d *fsDriver) dataPath(name string) string {
+	return filepath.Join(d.root, name)
+}
+
+func (d *fsDriver) metaPath(name string) string {
+	return filepath.Join(d.root, "."+name+metaSuffix)
+}
+
+func checkName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return blobs.ErrInvalidName
+	}
+
+	return nil
+}
+
+func (d *fsDriver) List() ([]string, error) {
+	entries, err := ioutil.ReadDir(d.root)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, fi := range entries {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+
+		names = append(names, fi.Name())
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (d *fsDriver) Inspect(name string) (*blobs.Blob, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.inspect(name)
+}
+
+func (d *fsDriver) inspect(name string) (*blobs.Blob, error) {
+	if _, err := os.Stat(d.dataPath(name)); os.IsNotExist(err) {
+		return nil, blobs.ErrUnknown
+	} else if err != nil {
+		return nil, err
+	}
+
+	b := &blobs.Blob{
+		Name: name,
+		Meta: make(map[string]string),
+	}
+
+	buf, err := ioutil.ReadFile(d.metaPath(name))
+	if os.IsNotExist(err) {
+		return b, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(buf, &b.Meta); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (d *fsDriver) WriteMeta(name string, b *blobs.Blob) error {
+	if err := checkName(name); err != nil {
+		return err
+	} else if err := checkName(b.Name); err != nil {
+		return err
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if _, err := os.Stat(d.dataPath(name)); os.IsNotExist(err) {
+		return blobs.ErrUnknown
+	} else if err != nil {
+		return err
+	}
+
+	if name != b.Name {
+		if err := os.Rename(d.dataPath(name), d.dataPath(b.Name)); err != nil {
+			return err
+		}
+
+		os.Remove(d.metaPath(name))
+	}
+
+	buf, err := json.Marshal(b.Meta)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(d.metaPath(b.Name), buf)
+}
+
+func (d *fsDriver) Writer(name string) (driver.Writer, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile(d.root, "."+name+tempSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileWriter{
+		f:    f,
+		path: d.dataPath(name),
+	}, nil
+}
+
+func (d *fsDriver) Reader(name string) (io.ReadCloser, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(d.dataPath(name))
+	if os.IsNotExist(err) {
+		return nil, blobs.ErrUnknown
+	} else if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (d *fsDriver) Drop(name string) (bool, error) {
+	if err := checkName(name); err != nil {
+		return false, err
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	err := os.Remove(d.dataPath(name))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := os.Remove(d.metaPath(name)); err != nil && !os.IsNotExist(err) {
+		return true, err
+	}
+
+	return true, nil
+}
+
+// fileWriter stages writes in a temp file and only moves it into place when
+// closed, so readers never see a partially written blob. Cancel removes the
+// temp file instead.
+type fileWriter struct {
+	f      *os.File
+	path   string
+	closed bool
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	return w.f.Write(p)
+}
+
+func (w *fileWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	tmpPath := w.f.Name()
+	if err := w.f.Sync(); err != nil {
+		w.f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := w.f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+func (w *fileWriter) Cancel() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	w.f.Close()
+	return os.Remove(w.f.Name())
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	w := &fileWriter{path: path}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+tempSuffix)
+	if err != nil {
+		return err
+	}
+
+	w.f = f
+	if _, err := w.Write(data); err != nil {
+		w.Cancel()
+		return err
+	}
+
+	return w.Close()
+}
@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
+	"sort"
 	"sync"
 
 	"github.com/danielkrainas/gobag/decouple/drivers"
-	"github.com/danielkrainas/gobag/util/bagio"
 
 	"github.com/danielkrainas/tinkersnest/blobs"
+	blobdriver "github.com/danielkrainas/tinkersnest/blobs/driver"
 	"github.com/danielkrainas/tinkersnest/blobs/driver/factory"
 )
 
@@ -35,6 +36,19 @@ type driver struct {
 	blobs map[string]*blobDescriptor
 }
 
+func (d *driver) List() ([]string, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	names := make([]string, 0, len(d.blobs))
+	for name := range d.blobs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
 func (d *driver) Inspect(name string) (*blobs.Blob, error) {
 	d.m.Lock()
 	defer d.m.Unlock()
@@ -65,18 +79,41 @@ func (d *driver) WriteMeta(name string, b *blobs.Blob) error {
 	return nil
 }
 
-func (d *driver) Writer(name string) (io.WriteCloser, error) {
-	d.m.Lock()
-	defer d.m.Unlock()
+func (d *driver) Writer(name string) (blobdriver.Writer, error) {
+	return &blobWriter{d: d, name: name}, nil
+}
 
-	desc, ok := d.blobs[name]
+// blobWriter buffers the data privately so readers keep seeing the old blob,
+// if any, until the writer is closed.
+type blobWriter struct {
+	bytes.Buffer
+	d    *driver
+	name string
+}
+
+func (w *blobWriter) Close() error {
+	w.d.m.Lock()
+	defer w.d.m.Unlock()
+
+	desc, ok := w.d.blobs[w.name]
 	if !ok {
-		return nil, blobs.ErrUnknown
+		desc = &blobDescriptor{
+			blob: &blobs.Blob{
+				Name: w.name,
+				Meta: make(map[string]string),
+			},
+		}
+
+		w.d.blobs[w.name] = desc
 	}
 
-	w := bytes.NewBuffer(make([]byte, 0, 0))
-	desc.data = w
-	return bagio.NopWriteCloser(w), nil
+	desc.data = &w.Buffer
+	return nil
+}
+
+func (w *blobWriter) Cancel() error {
+	w.Reset()
+	return nil
 }
 
 func (d *driver) Reader(name string) (io.ReadCloser, error) {
@@ -1,19 +1,30 @@
-package loader
+package blobsloader
 
 import (
-	"errors"
+	"context"
 
+	cfg "github.com/danielkrainas/gobag/configuration"
+	"github.com/danielkrainas/gobag/context"
+
+	"github.com/danielkrainas/tinkersnest/blobs/driver"
+	"github.com/danielkrainas/tinkersnest/blobs/driver/factory"
 	"github.com/danielkrainas/tinkersnest/configuration"
-	"github.com/danielkrainas/tinkersnest/driver"
 )
 
+const defaultDriver = "inmemory"
+
 func FromConfig(config *configuration.Config) (driver.Driver, error) {
+	driverType := config.Blobs.Type()
+	if driverType == "" {
+		driverType = defaultDriver
+	}
+
 	params := config.Blobs.Parameters()
 	if params == nil {
-		params = make(configuration.Parameters)
+		params = make(cfg.Parameters)
 	}
 
-	d, err := factory.Create(config.Blobs.Type(), params)
+	d, err := factory.Create(driverType, params)
 	if err != nil {
 		return nil, err
 	}
@@ -22,5 +33,11 @@ func FromConfig(config *configuration.Config) (driver.Driver, error) {
 }
 
 func LogSummary(ctx context.Context, config *configuration.Config) {
-	acontext.GetLogger(ctx).Infof("using %q blobs driver", config.Blobs.Type())
+	driverType := config.Blobs.Type()
+	if driverType == "" {
+		acontext.GetLogger(ctx).Warnf("no blobs driver configured, using %q", defaultDriver)
+		driverType = defaultDriver
+	}
+
+	acontext.GetLogger(ctx).Infof("using %q blobs driver", driverType)
 }
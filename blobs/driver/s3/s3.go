@@ -0,0 +1,407 @@
+package s3
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielkrainas/gobag/decouple/drivers"
+
+	"github.com/danielkrainas/tinkersnest/blobs"
+	"github.com/danielkrainas/tinkersnest/blobs/driver"
+	"github.com/danielkrainas/tinkersnest/blobs/driver/factory"
+)
+
+const metaHeaderPrefix = "X-Amz-Meta-"
+
+type driverFactory struct{}
+
+func (df *driverFactory) Create(parameters map[string]interface{}) (drivers.DriverBase, error) {
+	bucket, ok := parameters["bucket"].(string)
+	if !ok || bucket == "" {
+		return nil, errors.New("bucket parameter invalid or missing")
+	}
+
+	region, ok := parameters["region"].(string)
+	if !ok || region == "" {
+		return nil, errors.New("region parameter invalid or missing")
+	}
+
+	endpoint, _ := parameters["endpoint"].(string)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint parameter invalid: %v", err)
+	}
+
+	pathStyle, err := boolParameter(parameters, "pathstyle")
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, _ := parameters["accesskey"].(string)
+	secretKey, _ := parameters["secretkey"].(string)
+	if (accessKey == "") != (secretKey == "") {
+		return nil, errors.New("accesskey and secretkey parameters must be provided together")
+	}
+
+	return &s3Driver{
+		client:    http.DefaultClient,
+		endpoint:  endpointURL,
+		bucket:    bucket,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		pathStyle: pathStyle,
+	}, nil
+}
+
+func boolParameter(parameters map[string]interface{}, name string) (bool, error) {
+	switch v := parameters[name].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("%s parameter invalid: %v", name, err)
+		}
+
+		return b, nil
+	}
+
+	return false, fmt.Errorf("%s parameter invalid", name)
+}
+
+func init() {
+	factory.Register("s3", &driverFactory{})
+}
+
+// s3Driver talks to any S3 compatible object store over its REST API. Blob
+// metadata is kept in the object's user metadata headers.
+type s3Driver struct {
+	client    *http.Client
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+}
+
+var _ driver.Driver = &s3Driver{}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (d *s3Driver) objectURL(key string, query url.Values) *url.URL {
+	u := *d.endpoint
+	path := "/"
+	if d.pathStyle {
+		path += d.bucket + "/"
+	} else {
+		u.Host = d.bucket + "." + u.Host
+	}
+
+	path += key
+	u.Path = path
+	u.RawPath = uriEncode(path, false)
+	u.RawQuery = ""
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+
+	return &u
+}
+
+func (d *s3Driver) do(method string, key string, query url.Values, header http.Header, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	u := d.objectURL(key, query)
+	r, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.URL = u
+	if body != nil {
+		r.ContentLength = size
+	}
+
+	for k, v := range header {
+		r.Header[k] = v
+	}
+
+	if payloadHash == "" {
+		payloadHash = emptyPayload
+	}
+
+	signRequest(r, d.region, d.accessKey, d.secretKey, payloadHash, time.Now())
+	return d.client.Do(r)
+}
+
+func responseError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return blobs.ErrUnknown
+	}
+
+	buf, _ := ioutil.ReadAll(resp.Body)
+	e := &s3Error{}
+	if err := xml.Unmarshal(buf, e); err != nil || e.Code == "" {
+		return fmt.Errorf("s3: unexpected status %s", resp.Status)
+	}
+
+	return fmt.Errorf("s3: %s: %s", e.Code, e.Message)
+}
+
+func checkName(name string) error {
+	if name == "" || strings.HasPrefix(name, "/") {
+		return blobs.ErrInvalidName
+	}
+
+	return nil
+}
+
+func metaHeaders(b *blobs.Blob) http.Header {
+	header := http.Header{}
+	for k, v := range b.Meta {
+		header.Set(metaHeaderPrefix+k, v)
+	}
+
+	if contentType := b.Meta[blobs.MetaContentType]; contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	return header
+}
+
+func (d *s3Driver) List() ([]string, error) {
+	names := make([]string, 0)
+	query := url.Values{"list-type": []string{"2"}}
+	for {
+		resp, err := d.do(http.MethodGet, "", query, nil, nil, 0, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := responseError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		result := &listBucketResult{}
+		err = xml.NewDecoder(resp.Body).Decode(result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range result.Contents {
+			names = append(names, c.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+
+	return names, nil
+}
+
+func (d *s3Driver) Inspect(name string) (*blobs.Blob, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	resp, err := d.do(http.MethodHead, name, nil, nil, nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	b := &blobs.Blob{
+		Name: name,
+		Meta: make(map[string]string),
+	}
+
+	for k, v := range resp.Header {
+		if strings.HasPrefix(k, metaHeaderPrefix) && len(v) > 0 {
+			b.Meta[strings.ToLower(strings.TrimPrefix(k, metaHeaderPrefix))] = v[0]
+		}
+	}
+
+	return b, nil
+}
+
+// WriteMeta replaces the object's metadata with a server side copy onto
+// itself, or onto the new key when the blob is being renamed.
+func (d *s3Driver) WriteMeta(name string, b *blobs.Blob) error {
+	if err := checkName(name); err != nil {
+		return err
+	} else if err := checkName(b.Name); err != nil {
+		return err
+	}
+
+	header := metaHeaders(b)
+	header.Set("X-Amz-Copy-Source", "/"+d.bucket+"/"+uriEncode(name, false))
+	header.Set("X-Amz-Metadata-Directive", "REPLACE")
+	resp, err := d.do(http.MethodPut, b.Name, nil, header, nil, 0, "")
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	if name != b.Name {
+		if _, err := d.Drop(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *s3Driver) Writer(name string) (driver.Writer, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile("", "tinkersnest-s3-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectWriter{
+		d:    d,
+		name: name,
+		f:    f,
+		hash: sha256.New(),
+	}, nil
+}
+
+func (d *s3Driver) Reader(name string) (io.ReadCloser, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	resp, err := d.do(http.MethodGet, name, nil, nil, nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+func (d *s3Driver) Drop(name string) (bool, error) {
+	if _, err := d.Inspect(name); err == blobs.ErrUnknown {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	resp, err := d.do(http.MethodDelete, name, nil, nil, nil, 0, "")
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return false, responseError(resp)
+	}
+
+	return true, nil
+}
+
+// objectWriter spools the blob to a temp file because a signed PUT needs the
+// payload hash and length up front. The upload happens on Close, and Cancel
+// throws the spool away without uploading anything.
+type objectWriter struct {
+	d      *s3Driver
+	name   string
+	f      *os.File
+	hash   hash.Hash
+	size   int64
+	closed bool
+}
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	w.hash.Write(p[:n])
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *objectWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	defer os.Remove(w.f.Name())
+	defer w.f.Close()
+
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	payloadHash := hex.EncodeToString(w.hash.Sum(nil))
+	resp, err := w.d.do(http.MethodPut, w.name, nil, nil, w.f, w.size, payloadHash)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	return nil
+}
+
+func (w *objectWriter) Cancel() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	w.f.Close()
+	return os.Remove(w.f.Name())
+}
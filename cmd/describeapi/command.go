@@ -0,0 +1,33 @@
+package describeapi
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/danielkrainas/gobag/cmd"
+	"github.com/danielkrainas/gobag/context"
+
+	"github.com/danielkrainas/tinkersnest/api/openapi"
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+func init() {
+	cmd.Register("describe-api", Info)
+}
+
+func run(ctx context.Context, args []string) error {
+	doc := openapi.Generate(v1.API.Routes, acontext.GetVersion(ctx))
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+var (
+	Info = &cmd.Info{
+		Use:   "describe-api",
+		Short: "print the OpenAPI document for the api",
+		Long:  "print the OpenAPI 3 document generated from the api route descriptors",
+		Run:   cmd.ExecutorFunc(run),
+	}
+)
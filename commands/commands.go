@@ -1,25 +1,48 @@
 package commands
 
 import (
+	"io"
+
 	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/blobs"
 )
 
 type StorePost struct {
 	New  bool
 	Post *v1.Post
+
+	// User made the change and is recorded in the post's new revision.
+	User string
+	// RestoredFrom is set when the post is being rolled back to an older
+	// revision.
+	RestoredFrom int
+
+	// Published is set once the post is stored if the change made it
+	// public.
+	Published bool
 }
 
 type DeletePost struct {
 	Name string
 }
 
+// TransitionPost moves a post to another state for the scheduler. It fails
+// with storage.ErrConflict if the post has changed since it was found due at
+// Version, whether by another transition or an edit.
+type TransitionPost struct {
+	Name    string
+	Version int64
+	To      v1.PostState
+}
+
 type DeleteUser struct {
 	Name string
 }
 
+// CreateClaim generates the code and creation time of a new claim, and
+// gives it the default expiry and uses when it has none.
 type CreateClaim struct {
-	Code         string
-	ResourceType v1.ResourceType
+	Claim *v1.Claim
 }
 
 type RedeemClaim struct {
@@ -27,7 +50,171 @@ type RedeemClaim struct {
 	ResourceType v1.ResourceType
 }
 
+// ReleaseClaim gives back a redemption of the claim when the resource it was
+// redeemed for couldn't be stored.
+type ReleaseClaim struct {
+	Code string
+}
+
+// RevokeClaim stops a claim from being redeemed. It is kept until purged.
+type RevokeClaim struct {
+	Code string
+}
+
+// PurgeClaims deletes the claims in any of the States. Active claims are
+// never purged.
+type PurgeClaims struct {
+	States []v1.ClaimState
+
+	// Purged is set once the claims are deleted to the ones that were.
+	Purged []*v1.Claim
+}
+
 type StoreUser struct {
 	New  bool
 	User *v1.User
 }
+
+type StoreSession struct {
+	New     bool
+	Session *v1.Session
+}
+
+type DeleteSession struct {
+	ID string
+}
+
+type DeleteUserSessions struct {
+	User string
+}
+
+type StoreAPIKey struct {
+	New bool
+	Key *v1.APIKey
+}
+
+type DeleteAPIKey struct {
+	ID string
+}
+
+type StoreBlob struct {
+	Blob *blobs.Blob
+	Data io.Reader
+
+	// Replaced is set once the blob is stored if it took the place of one
+	// with the same name.
+	Replaced bool
+}
+
+type DeleteBlob struct {
+	Name string
+}
+
+type StoreTag struct {
+	Tag *v1.Tag
+}
+
+// DeleteTag removes the tag from every post carrying it. User is recorded in
+// the new revisions of those posts.
+type DeleteTag struct {
+	Slug string
+	User string
+}
+
+// RenameTag moves the tag and its posts to a slug that isn't in use yet.
+type RenameTag struct {
+	From string
+	To   string
+	User string
+}
+
+// MergeTags retags the posts of one tag with another existing tag, which
+// keeps its own title and description.
+type MergeTags struct {
+	From string
+	Into string
+	User string
+}
+
+type StoreCategory struct {
+	Category *v1.Category
+}
+
+// DeleteCategory takes the category off its posts and moves its
+// subcategories up to its parent.
+type DeleteCategory struct {
+	Slug string
+	User string
+}
+
+type RenameCategory struct {
+	From string
+	To   string
+	User string
+}
+
+// MergeCategories moves the posts and subcategories of one category into
+// another.
+type MergeCategories struct {
+	From string
+	Into string
+	User string
+}
+
+type StoreComment struct {
+	New     bool
+	Comment *v1.Comment
+}
+
+// DeleteComment removes the comment along with every reply below it.
+type DeleteComment struct {
+	ID string
+}
+
+// ModerateComments moves every listed comment into the state. It fails with
+// storage.ErrNotFound without changing anything if any of them is unknown.
+type ModerateComments struct {
+	IDs   []string
+	State v1.CommentState
+}
+
+// StoreCollection defines or redefines a collection. Items already in it
+// aren't checked against the new schema until they are next stored.
+type StoreCollection struct {
+	Collection *v1.Collection
+}
+
+// DeleteCollection removes the collection along with all of its items.
+type DeleteCollection struct {
+	Name string
+}
+
+// StoreItem fails with storage.ErrConflict when a new item would replace one
+// with the same name.
+type StoreItem struct {
+	New  bool
+	Item *v1.Item
+}
+
+type DeleteItem struct {
+	Collection string
+	Name       string
+}
+
+// StoreWebhook generates the ID and creation time of new webhooks.
+type StoreWebhook struct {
+	New     bool
+	Webhook *v1.Webhook
+}
+
+// DeleteWebhook removes the webhook along with its delivery log.
+type DeleteWebhook struct {
+	ID string
+}
+
+// AppendDelivery adds to the delivery log of a webhook, which only keeps the
+// newest Keep deliveries.
+type AppendDelivery struct {
+	Delivery *v1.Delivery
+	Keep     int
+}
@@ -5,8 +5,11 @@ import (
 	"io"
 	"io/ioutil"
 	"reflect"
+	"time"
 
 	cfg "github.com/danielkrainas/gobag/configuration"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
 )
 
 type LogConfig struct {
@@ -28,11 +31,64 @@ type HTTPConfig struct {
 	CORS CORSConfig `yaml:"cors"`
 }
 
+// AuthKeyConfig describes a key used to sign or verify bearer tokens. HS256
+// keys are read from a file holding the raw secret, RS256 and ES256 keys from
+// a PEM encoded private key, or a public key if the key is only used to
+// verify tokens signed elsewhere.
+type AuthKeyConfig struct {
+	ID        string `yaml:"id"`
+	Algorithm string `yaml:"algorithm"`
+	File      string `yaml:"file"`
+}
+
+type AuthConfig struct {
+	Issuer          string          `yaml:"issuer,omitempty"`
+	TokenLifetime   time.Duration   `yaml:"tokenlifetime,omitempty"`
+	SessionLifetime time.Duration   `yaml:"sessionlifetime,omitempty"`
+	SigningKey      string          `yaml:"signingkey,omitempty"`
+	Keys            []AuthKeyConfig `yaml:"keys,omitempty"`
+}
+
+type FeedConfig struct {
+	Title       string `yaml:"title,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Limit       int    `yaml:"limit,omitempty"`
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// WebhooksConfig controls how events are delivered to webhooks. A failed
+// delivery is retried up to Retries times, waiting Backoff before the first
+// retry and twice as long before each one after it.
+type WebhooksConfig struct {
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	Retries int           `yaml:"retries,omitempty"`
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+}
+
+// MediaConfig limits what can be uploaded as media. Uploads larger than
+// MaxSize bytes are refused, as are content types not in Types.
+type MediaConfig struct {
+	MaxSize int64    `yaml:"maxsize,omitempty"`
+	Types   []string `yaml:"types,omitempty"`
+}
+
 type Config struct {
-	Log     LogConfig  `yaml:"log"`
-	HTTP    HTTPConfig `yaml:"http"`
-	Storage cfg.Driver `yaml:"storage"`
-	Blobs   cfg.Driver `yaml:"blobs"`
+	Log       LogConfig       `yaml:"log"`
+	HTTP      HTTPConfig      `yaml:"http"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Feed      FeedConfig      `yaml:"feed"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	Media     MediaConfig     `yaml:"media"`
+	Storage   cfg.Driver      `yaml:"storage"`
+	Blobs     cfg.Driver      `yaml:"blobs"`
+
+	// Collections declares content types up front. They can't be changed
+	// through the API.
+	Collections []*v1.Collection `yaml:"collections,omitempty"`
 }
 
 type v1_0Config Config
@@ -49,6 +105,27 @@ func newConfig() *Config {
 			Addr: ":9240",
 			Host: "localhost",
 		},
+
+		Auth: AuthConfig{
+			Issuer:          "tinkersnest",
+			TokenLifetime:   5 * time.Hour,
+			SessionLifetime: 30 * 24 * time.Hour,
+		},
+
+		Scheduler: SchedulerConfig{
+			Interval: 30 * time.Second,
+		},
+
+		Feed: FeedConfig{
+			Title: "tinkersnest",
+			Limit: 20,
+		},
+
+		Webhooks: WebhooksConfig{
+			Timeout: 10 * time.Second,
+			Retries: 5,
+			Backoff: time.Second,
+		},
 	}
 
 	return config
@@ -0,0 +1,205 @@
+// Package diff computes line based diffs for comparing post revisions.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Insert Op = '+'
+	Delete Op = '-'
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// maxCells bounds the size of the LCS table. Inputs bigger than that are
+// diffed as one deletion followed by one insertion.
+const maxCells = 16 * 1024 * 1024
+
+// Lines returns the edits that turn a into b, using the longest common
+// subsequence of their lines.
+func Lines(a []string, b []string) []Line {
+	// trim the common prefix and suffix, which is most of a typical edit
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]Line, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		result = append(result, Line{Equal, l})
+	}
+
+	result = append(result, lcs(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		result = append(result, Line{Equal, l})
+	}
+
+	return result
+}
+
+func lcs(a []string, b []string) []Line {
+	n, m := len(a), len(b)
+	result := make([]Line, 0, n+m)
+	if (n+1)*(m+1) > maxCells {
+		for _, l := range a {
+			result = append(result, Line{Delete, l})
+		}
+
+		for _, l := range b {
+			result = append(result, Line{Insert, l})
+		}
+
+		return result
+	}
+
+	// table[i][j] is the LCS length of a[i:] and b[j:]
+	table := make([][]int32, n+1)
+	for i := range table {
+		table[i] = make([]int32, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, Line{Equal, a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			result = append(result, Line{Delete, a[i]})
+			i++
+		default:
+			result = append(result, Line{Insert, b[j]})
+			j++
+		}
+	}
+
+	for ; i < n; i++ {
+		result = append(result, Line{Delete, a[i]})
+	}
+
+	for ; j < m; j++ {
+		result = append(result, Line{Insert, b[j]})
+	}
+
+	return result
+}
+
+// Unified formats the differences between a and b as a unified diff with
+// the given number of context lines. It returns an empty string if a and b
+// are the same.
+func Unified(fromName string, toName string, a []string, b []string, context int) string {
+	lines := Lines(a, b)
+
+	// aPos[k] and bPos[k] are the number of lines of a and b before lines[k]
+	aPos := make([]int, len(lines)+1)
+	bPos := make([]int, len(lines)+1)
+	changed := false
+	for k, l := range lines {
+		aPos[k+1], bPos[k+1] = aPos[k], bPos[k]
+		if l.Op != Insert {
+			aPos[k+1]++
+		}
+
+		if l.Op != Delete {
+			bPos[k+1]++
+		}
+
+		if l.Op != Equal {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return ""
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].Op == Equal {
+			i++
+		}
+
+		if i == len(lines) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// grow the hunk until the next change is too far away to share context
+		end := i
+		for {
+			for end < len(lines) && lines[end].Op != Equal {
+				end++
+			}
+
+			next := end
+			for next < len(lines) && lines[next].Op == Equal {
+				next++
+			}
+
+			if next < len(lines) && next-end <= 2*context {
+				end = next
+				continue
+			}
+
+			end += context
+			if end > len(lines) {
+				end = len(lines)
+			}
+
+			break
+		}
+
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(aPos[start], aPos[end]), hunkRange(bPos[start], bPos[end]))
+		for _, l := range lines[start:end] {
+			buf.WriteByte(byte(l.Op))
+			buf.WriteString(l.Text)
+			buf.WriteByte('\n')
+		}
+
+		i = end
+	}
+
+	return buf.String()
+}
+
+func hunkRange(from int, to int) string {
+	count := to - from
+	if count == 0 {
+		return fmt.Sprintf("%d,0", from)
+	} else if count == 1 {
+		return fmt.Sprintf("%d", from+1)
+	}
+
+	return fmt.Sprintf("%d,%d", from+1, count)
+}
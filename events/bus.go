@@ -0,0 +1,123 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// ReplaySize is how many of the latest events the bus keeps for subscribers
+// catching up on what they missed.
+const ReplaySize = 1000
+
+// Bus passes events on to every subscriber. Publishing never blocks: a
+// subscriber that falls behind by more than its buffer is dropped and its
+// channel closed, after which it can catch up with SubscribeSince as long as
+// the events it missed are still in the replay buffer.
+type Bus struct {
+	m       sync.Mutex
+	subs    map[*Subscription]bool
+	history []*v1.Event
+	size    int
+}
+
+// NewBus returns a bus that keeps the last size events for replay.
+func NewBus(size int) *Bus {
+	return &Bus{
+		subs: make(map[*Subscription]bool),
+		size: size,
+	}
+}
+
+type Subscription struct {
+	C <-chan *v1.Event
+
+	c   chan *v1.Event
+	bus *Bus
+}
+
+// Subscribe returns a subscription to every event published from now on,
+// buffering up to size of them.
+func (b *Bus) Subscribe(size int) *Subscription {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.subscribe(size)
+}
+
+// SubscribeSince subscribes like Subscribe and also returns the buffered
+// events published after the one with the id, oldest first. If that event
+// isn't in the buffer anymore, or never was, every buffered event is
+// returned since any of them may have been missed. An empty id replays
+// nothing.
+func (b *Bus) SubscribeSince(id string, size int) (*Subscription, []*v1.Event) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	var missed []*v1.Event
+	if id != "" {
+		start := 0
+		for i, e := range b.history {
+			if e.ID == id {
+				start = i + 1
+				break
+			}
+		}
+
+		missed = append(missed, b.history[start:]...)
+	}
+
+	return b.subscribe(size), missed
+}
+
+func (b *Bus) subscribe(size int) *Subscription {
+	c := make(chan *v1.Event, size)
+	s := &Subscription{
+		C:   c,
+		c:   c,
+		bus: b,
+	}
+
+	b.subs[s] = true
+	return s
+}
+
+// Close ends the subscription and closes its channel.
+func (s *Subscription) Close() {
+	b := s.bus
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.drop(s)
+}
+
+func (b *Bus) drop(s *Subscription) {
+	if b.subs[s] {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// Publish sends the event to every subscriber and returns how many of them
+// were dropped for having no room left for it.
+func (b *Bus) Publish(e *v1.Event) int {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if b.size > 0 {
+		if len(b.history) >= b.size {
+			n := copy(b.history, b.history[len(b.history)-b.size+1:])
+			b.history = b.history[:n]
+		}
+
+		b.history = append(b.history, e)
+	}
+
+	dropped := 0
+	for s := range b.subs {
+		select {
+		case s.c <- e:
+		default:
+			b.drop(s)
+			dropped++
+		}
+	}
+
+	return dropped
+}
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/satori/go.uuid"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/queries"
+)
+
+// Handler passes commands on to Inner and publishes an event for every post,
+// user, comment and media change once a command succeeds.
+type Handler struct {
+	Bus   *Bus
+	Inner cqrs.CommandHandler
+}
+
+var _ cqrs.CommandHandler = &Handler{}
+
+func (h *Handler) Handle(ctx context.Context, c cqrs.Command) error {
+	if err := h.Inner.Handle(ctx, c); err != nil {
+		return err
+	}
+
+	for _, e := range changes(ctx, c) {
+		e.ID = uuid.NewV4().String()
+		e.Created = time.Now().Unix()
+		if dropped := h.Bus.Publish(e); dropped > 0 {
+			acontext.GetLogger(ctx).Warnf("dropped %d event subscribers that fell behind", dropped)
+		}
+	}
+
+	return nil
+}
+
+// changes describes what a successful command did. Looking up the resources
+// a command only names can fail, in which case their events carry no data.
+func changes(ctx context.Context, c cqrs.Command) []*v1.Event {
+	var events []*v1.Event
+	switch c := c.(type) {
+	case *commands.StorePost:
+		typ := v1.EventPostUpdated
+		if c.New {
+			typ = v1.EventPostCreated
+		}
+
+		events = append(events, postEvent(typ, c.Post.Name, c.Post))
+		if c.Published {
+			events = append(events, postEvent(v1.EventPostPublished, c.Post.Name, c.Post))
+		}
+
+	case *commands.TransitionPost:
+		p := findPost(ctx, c.Name)
+		events = append(events, postEvent(v1.EventPostUpdated, c.Name, p))
+		if c.To == v1.PostPublished {
+			events = append(events, postEvent(v1.EventPostPublished, c.Name, p))
+		}
+
+	case *commands.DeletePost:
+		events = append(events, &v1.Event{Type: v1.EventPostDeleted, Resource: c.Name})
+
+	case *commands.StoreUser:
+		typ := v1.EventUserUpdated
+		if c.New {
+			typ = v1.EventUserCreated
+		}
+
+		events = append(events, &v1.Event{Type: typ, Resource: c.User.Name, Data: publicUser(c.User)})
+
+	case *commands.DeleteUser:
+		events = append(events, &v1.Event{Type: v1.EventUserDeleted, Resource: c.Name})
+
+	case *commands.StoreComment:
+		typ := v1.EventCommentUpdated
+		if c.New {
+			typ = v1.EventCommentCreated
+		}
+
+		comment := *c.Comment
+		events = append(events, &v1.Event{Type: typ, Resource: comment.ID, Data: &comment})
+		if c.New && comment.State == v1.CommentApproved {
+			events = append(events, &v1.Event{Type: v1.EventCommentPublished, Resource: comment.ID, Data: &comment})
+		}
+
+	case *commands.ModerateComments:
+		for _, id := range c.IDs {
+			var data interface{}
+			if comment := findComment(ctx, id); comment != nil {
+				data = comment
+			}
+
+			events = append(events, &v1.Event{Type: v1.EventCommentUpdated, Resource: id, Data: data})
+			if c.State == v1.CommentApproved {
+				events = append(events, &v1.Event{Type: v1.EventCommentPublished, Resource: id, Data: data})
+			}
+		}
+
+	case *commands.DeleteComment:
+		events = append(events, &v1.Event{Type: v1.EventCommentDeleted, Resource: c.ID})
+
+	case *commands.StoreBlob:
+		typ := v1.EventMediaCreated
+		if c.Replaced {
+			typ = v1.EventMediaUpdated
+		}
+
+		blob := *c.Blob
+		events = append(events, &v1.Event{Type: typ, Resource: blob.Name, Data: &blob})
+
+	case *commands.DeleteBlob:
+		events = append(events, &v1.Event{Type: v1.EventMediaDeleted, Resource: c.Name})
+	}
+
+	return events
+}
+
+// postEvent copies the post, which may still be changed by whoever stored it
+// after the event is published.
+func postEvent(typ v1.EventType, name string, p *v1.Post) *v1.Event {
+	e := &v1.Event{Type: typ, Resource: name}
+	if p != nil {
+		cp := *p
+		e.Data = &cp
+	}
+
+	return e
+}
+
+// publicUser copies the user without the password it may have been stored
+// with.
+func publicUser(u *v1.User) *v1.User {
+	cp := *u
+	cp.Password = ""
+	return &cp
+}
+
+func findPost(ctx context.Context, name string) *v1.Post {
+	p, err := cqrs.DispatchQuery(ctx, &queries.FindPost{Name: name})
+	if err != nil {
+		acontext.GetLogger(ctx).Errorf("error finding post %q for its event: %v", name, err)
+		return nil
+	}
+
+	post, _ := p.(*v1.Post)
+	return post
+}
+
+func findComment(ctx context.Context, id string) *v1.Comment {
+	c, err := cqrs.DispatchQuery(ctx, &queries.FindComment{ID: id})
+	if err != nil {
+		acontext.GetLogger(ctx).Errorf("error finding comment %q for its event: %v", id, err)
+		return nil
+	}
+
+	comment, _ := c.(*v1.Comment)
+	return comment
+}
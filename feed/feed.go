@@ -0,0 +1,75 @@
+// Package feed writes lists of posts as RSS 2.0, Atom 1.0 or JSON Feed
+// documents.
+package feed
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type Format string
+
+var (
+	RSS  Format = "rss"
+	Atom Format = "atom"
+	JSON Format = "json"
+)
+
+func (f Format) Valid() bool {
+	switch f {
+	case RSS, Atom, JSON:
+		return true
+	}
+
+	return false
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case RSS:
+		return "application/rss+xml; charset=utf-8"
+	case Atom:
+		return "application/atom+xml; charset=utf-8"
+	case JSON:
+		return "application/feed+json; charset=utf-8"
+	}
+
+	return ""
+}
+
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the page the feed mirrors and FeedURL the feed itself, both
+	// absolute.
+	Link    string
+	FeedURL string
+	Updated time.Time
+	Items   []*Item
+}
+
+type Item struct {
+	// ID is permanent and unique, the post's URL is used.
+	ID        string
+	Title     string
+	Link      string
+	Author    string
+	Published time.Time
+	Tags      []string
+	// ContentHTML must already be sanitized.
+	ContentHTML string
+}
+
+func Encode(w io.Writer, format Format, f *Feed) error {
+	switch format {
+	case RSS:
+		return encodeRSS(w, f)
+	case Atom:
+		return encodeAtom(w, f)
+	case JSON:
+		return encodeJSON(w, f)
+	}
+
+	return fmt.Errorf("unsupported feed format %q", format)
+}
@@ -8,10 +8,14 @@ import (
 	"github.com/danielkrainas/gobag/cmd"
 	"github.com/danielkrainas/gobag/context"
 
+	_ "github.com/danielkrainas/tinkersnest/blobs/driver/filesystem"
 	_ "github.com/danielkrainas/tinkersnest/blobs/driver/inmemory"
+	_ "github.com/danielkrainas/tinkersnest/blobs/driver/s3"
+	_ "github.com/danielkrainas/tinkersnest/cmd/describeapi"
 	"github.com/danielkrainas/tinkersnest/cmd/root"
 	_ "github.com/danielkrainas/tinkersnest/cmd/serve"
 	_ "github.com/danielkrainas/tinkersnest/cmd/version"
+	_ "github.com/danielkrainas/tinkersnest/storage/driver/file"
 	_ "github.com/danielkrainas/tinkersnest/storage/driver/inmemory"
 	_ "github.com/danielkrainas/tinkersnest/storage/driver/mongodb"
 )
@@ -0,0 +1,423 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON encoded resources.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Types lists the patch media types Apply understands.
+var Types = []string{MergePatchType, JSONPatchType}
+
+var (
+	ErrUnsupportedType = errors.New("unsupported patch type")
+
+	// ErrTestFailed is returned when a JSON Patch test operation doesn't
+	// match the document.
+	ErrTestFailed = errors.New("test failed")
+)
+
+// Apply patches the JSON document doc with p, which is of the media type
+// typ. The document is left untouched if any part of the patch fails.
+func Apply(typ string, doc []byte, p []byte) ([]byte, error) {
+	switch typ {
+	case MergePatchType:
+		return Merge(doc, p)
+	case JSONPatchType:
+		return JSONPatch(doc, p)
+	}
+
+	return nil, ErrUnsupportedType
+}
+
+// Merge applies a JSON Merge Patch: objects in the patch are merged into the
+// document, nulls remove members and everything else replaces what was there.
+func Merge(doc []byte, p []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := decode(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %v", err)
+	}
+
+	return json.Marshal(merge(target, patch))
+}
+
+func merge(target interface{}, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = merge(t[k], v)
+		}
+	}
+
+	return t
+}
+
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies a JSON Patch, a list of add, remove, replace, move, copy
+// and test operations, in order.
+func JSONPatch(doc []byte, p []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []*operation
+	if err := json.Unmarshal(p, &ops); err != nil {
+		return nil, fmt.Errorf("invalid patch: %v", err)
+	}
+
+	for i, op := range ops {
+		if target, err = op.apply(target); err != nil {
+			if err == ErrTestFailed {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("operation %d: %v", i, err)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func (op *operation) apply(doc interface{}) (interface{}, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%s is missing a path", op.Op)
+	}
+
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("%s is missing a value", op.Op)
+		}
+
+		if value, err = decode(op.Value); err != nil {
+			return nil, err
+		}
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%s is missing from", op.Op)
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, path, value)
+
+	case "remove":
+		return remove(doc, path)
+
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+
+		if doc, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+
+	case "move", "copy":
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if value, err = get(doc, from); err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			value = clone(value)
+		} else {
+			if strings.HasPrefix(*op.Path, *op.From+"/") {
+				return nil, fmt.Errorf("can't move %q into itself", *op.From)
+			}
+
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		}
+
+		return add(doc, path, value)
+
+	case "test":
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equal(current, value) {
+			return nil, ErrTestFailed
+		}
+
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens.
+// The empty pointer refers to the whole document.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	} else if p[0] != '/' {
+		return nil, fmt.Errorf("path %q must start with /", p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, t := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[t]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", t)
+			}
+
+			doc = v
+
+		case []interface{}:
+			i, err := index(t, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = node[i]
+
+		default:
+			return nil, fmt.Errorf("%q not found", t)
+		}
+	}
+
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+			return node, nil
+
+		case []interface{}:
+			if key == "-" {
+				return append(node, value), nil
+			}
+
+			i, err := index(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+
+		return nil, fmt.Errorf("can't add %q to a value", key)
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("can't remove the whole document")
+	}
+
+	return update(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("%q not found", key)
+			}
+
+			delete(node, key)
+			return node, nil
+
+		case []interface{}:
+			i, err := index(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(node[:i], node[i+1:]...), nil
+		}
+
+		return nil, fmt.Errorf("%q not found", key)
+	})
+}
+
+// update walks to the parent of the last token of the path and replaces it
+// with what fn returns, since adding to or removing from an array makes a
+// new one.
+func update(doc interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	if child, err = update(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		i, _ := index(path[0], len(node)-1)
+		node[i] = child
+	}
+
+	return doc, nil
+}
+
+// index parses an array index no bigger than max.
+func index(t string, max int) (int, error) {
+	i, err := strconv.Atoi(t)
+	if err != nil || i < 0 || (len(t) > 1 && t[0] == '0') {
+		return 0, fmt.Errorf("%q isn't an array index", t)
+	} else if i > max {
+		return 0, fmt.Errorf("index %d is out of range", i)
+	}
+
+	return i, nil
+}
+
+func decode(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func clone(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		cp := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			cp[k] = clone(e)
+		}
+
+		return cp
+
+	case []interface{}:
+		cp := make([]interface{}, len(v))
+		for i, e := range v {
+			cp[i] = clone(e)
+		}
+
+		return cp
+	}
+
+	return v
+}
+
+// equal compares decoded JSON values, treating numbers as equal when they
+// have the same value however they were written.
+func equal(a interface{}, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+
+		return true
+
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		if a == b {
+			return true
+		}
+
+		x, err1 := a.Float64()
+		y, err2 := b.Float64()
+		return err1 == nil && err2 == nil && x == y
+	}
+
+	return a == b
+}
@@ -5,21 +5,152 @@ import (
 )
 
 type SearchPosts struct {
-	Author *v1.Author
+	Author        *v1.Author
+	Tags          []string
+	Published     *bool
+	CreatedAfter  int64
+	CreatedBefore int64
+	Sort          string
+	Limit         int
+	Cursor        string
+
+	// Category limits the results to posts in the category or any of its
+	// subcategories.
+	Category string
+
+	// Text searches the title, tags and content of posts and ranks the
+	// results by relevance.
+	Text string
 }
 
 type FindPost struct {
 	Name string
 }
 
+// FindDuePosts lists the posts with a scheduled transition at or before Now.
+type FindDuePosts struct {
+	Now int64
+}
+
+type SearchRevisions struct {
+	Post string
+}
+
+// FindRevision finds a revision of a post, or its latest when Number is zero.
+type FindRevision struct {
+	Post   string
+	Number int
+}
+
+// DiffRevisions compares two revisions of a post. When To is zero the latest
+// revision is used and when From is zero the one before To.
+type DiffRevisions struct {
+	Post string
+	From int
+	To   int
+}
+
 type CountUsers struct{}
 
 type FindClaim struct {
 	Code string
 }
 
+// SearchClaims finds the claims in the State and for the ResourceType, when
+// they are set.
+type SearchClaims struct {
+	State        v1.ClaimState
+	ResourceType v1.ResourceType
+}
+
 type FindUser struct {
 	Name string
 }
 
 type SearchUsers struct{}
+
+type FindSession struct {
+	ID string
+}
+
+type SearchSessions struct {
+	User string
+}
+
+type FindAPIKey struct {
+	ID string
+}
+
+type SearchAPIKeys struct {
+	User string
+}
+
+type FindBlob struct {
+	Name string
+}
+
+type OpenBlob struct {
+	Name string
+}
+
+type SearchBlobs struct{}
+
+// SearchTags lists every tag that is either stored or used by a post, with
+// the number of posts carrying it.
+type SearchTags struct {
+	Published *bool
+}
+
+type FindTag struct {
+	Slug      string
+	Published *bool
+}
+
+type SearchCategories struct {
+	Published *bool
+}
+
+type FindCategory struct {
+	Slug      string
+	Published *bool
+}
+
+type FindComment struct {
+	ID string
+}
+
+// SearchComments lists comments oldest first. Empty fields match any post or
+// state.
+type SearchComments struct {
+	Post  string
+	State v1.CommentState
+}
+
+type SearchCollections struct{}
+
+type FindCollection struct {
+	Name string
+}
+
+// SearchItems lists the items of a collection by name. Published limits them
+// to published or unpublished items when set.
+type SearchItems struct {
+	Collection string
+	Published  *bool
+}
+
+type FindItem struct {
+	Collection string
+	Name       string
+}
+
+type SearchWebhooks struct{}
+
+type FindWebhook struct {
+	ID string
+}
+
+// SearchDeliveries lists the deliveries to a webhook, newest first.
+type SearchDeliveries struct {
+	Webhook string
+}
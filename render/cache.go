@@ -0,0 +1,92 @@
+package render
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+const DefaultCacheSize = 256
+
+// Cache keeps the rendered content of recently viewed post revisions,
+// dropping the least recently used ones once it is full.
+type Cache struct {
+	m       sync.Mutex
+	size    int
+	entries *list.List
+	index   map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	source  string
+	content []*v1.Content
+}
+
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+
+	return &Cache{
+		size:    size,
+		entries: list.New(),
+		index:   make(map[string]*list.Element),
+	}
+}
+
+// Get returns the rendered content cached under key, provided it was
+// rendered from the same source content. The result must not be modified.
+func (c *Cache) Get(key string, source []*v1.Content) ([]*v1.Content, bool) {
+	fp := fingerprint(source)
+	c.m.Lock()
+	defer c.m.Unlock()
+	el, ok := c.index[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if entry.source != fp {
+		return nil, false
+	}
+
+	c.entries.MoveToFront(el)
+	return entry.content, true
+}
+
+func (c *Cache) Add(key string, source []*v1.Content, rendered []*v1.Content) {
+	entry := &cacheEntry{
+		key:     key,
+		source:  fingerprint(source),
+		content: rendered,
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	if el, ok := c.index[key]; ok {
+		el.Value = entry
+		c.entries.MoveToFront(el)
+		return
+	}
+
+	c.index[key] = c.entries.PushFront(entry)
+	for c.entries.Len() > c.size {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.index, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// fingerprint identifies the content a cache entry was rendered from, so a
+// post that was deleted and recreated can't be served its predecessor's
+// output.
+func fingerprint(content []*v1.Content) string {
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,157 @@
+// Package render turns post content into HTML that is safe to embed in a
+// page, whatever the author put in it.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/russross/blackfriday"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+const (
+	// PostScheme links to another post by name, e.g. [see also](post:hello-world).
+	PostScheme = "post:"
+	// MediaScheme links to uploaded media by name, e.g. ![cat](media:cat.png).
+	MediaScheme = "media:"
+)
+
+const (
+	markdownFlags = blackfriday.HTML_SAFELINK |
+		blackfriday.HTML_NOFOLLOW_LINKS |
+		blackfriday.HTML_USE_SMARTYPANTS |
+		blackfriday.HTML_SMARTYPANTS_DASHES
+
+	markdownExtensions = blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
+		blackfriday.EXTENSION_TABLES |
+		blackfriday.EXTENSION_FENCED_CODE |
+		blackfriday.EXTENSION_AUTOLINK |
+		blackfriday.EXTENSION_STRIKETHROUGH |
+		blackfriday.EXTENSION_SPACE_HEADERS |
+		blackfriday.EXTENSION_HEADER_IDS |
+		blackfriday.EXTENSION_AUTO_HEADER_IDS |
+		blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
+		blackfriday.EXTENSION_DEFINITION_LISTS
+)
+
+// Resolver builds the URLs that post: and media: links point to.
+// *v1.URLBuilder satisfies it.
+type Resolver interface {
+	BuildPostByName(name string) (string, error)
+	BuildMediaByName(name string) (string, error)
+}
+
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// fenced code keeps its language so pages can hook up a highlighter
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	return p
+}
+
+// HTML renders the inline data of a content block as sanitized HTML.
+// Content types the server doesn't know are treated as plain text.
+func HTML(c *v1.Content, links Resolver) []byte {
+	var out []byte
+	switch v1.ContentType(c.Type) {
+	case v1.ContentMarkdown:
+		out = markdown(c.Data, links)
+	case v1.ContentHtml:
+		out = c.Data
+	default:
+		out = text(c.Data)
+	}
+
+	return policy.SanitizeBytes(out)
+}
+
+// Media renders a link to a blob that isn't text, such as an image.
+func Media(c *v1.Content, links Resolver) ([]byte, error) {
+	u, err := links.BuildMediaByName(c.Blob)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if strings.HasPrefix(c.Type, "image") {
+		fmt.Fprintf(&buf, `<img src="%s" alt="%s">`, html.EscapeString(u), html.EscapeString(c.Rel))
+	} else {
+		fmt.Fprintf(&buf, `<a href="%s">%s</a>`, html.EscapeString(u), html.EscapeString(c.Blob))
+	}
+
+	return policy.SanitizeBytes(buf.Bytes()), nil
+}
+
+func markdown(data []byte, links Resolver) []byte {
+	r := &markdownRenderer{
+		Html:  blackfriday.HtmlRenderer(markdownFlags, "", "").(*blackfriday.Html),
+		links: links,
+	}
+
+	return blackfriday.Markdown(data, r, markdownExtensions)
+}
+
+// markdownRenderer resolves post: and media: links before handing them to
+// the standard renderer.
+type markdownRenderer struct {
+	*blackfriday.Html
+	links Resolver
+}
+
+func (r *markdownRenderer) Link(out *bytes.Buffer, link []byte, title []byte, content []byte) {
+	r.Html.Link(out, r.resolve(link), title, content)
+}
+
+func (r *markdownRenderer) Image(out *bytes.Buffer, link []byte, title []byte, alt []byte) {
+	r.Html.Image(out, r.resolve(link), title, alt)
+}
+
+func (r *markdownRenderer) resolve(link []byte) []byte {
+	dest := string(link)
+	var u string
+	var err error
+	switch {
+	case strings.HasPrefix(dest, PostScheme):
+		u, err = r.links.BuildPostByName(strings.TrimPrefix(dest, PostScheme))
+	case strings.HasPrefix(dest, MediaScheme):
+		u, err = r.links.BuildMediaByName(strings.TrimPrefix(dest, MediaScheme))
+	default:
+		return link
+	}
+
+	if err != nil {
+		return link
+	}
+
+	return []byte(u)
+}
+
+// text escapes plain text and keeps its paragraphs and line breaks.
+func text(data []byte) []byte {
+	var buf bytes.Buffer
+	normalized := strings.Replace(string(data), "\r\n", "\n", -1)
+	for _, para := range strings.Split(normalized, "\n\n") {
+		para = strings.Trim(para, "\n")
+		if para == "" {
+			continue
+		}
+
+		lines := strings.Split(para, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+
+		buf.WriteString("<p>")
+		buf.WriteString(strings.Join(lines, "<br>\n"))
+		buf.WriteString("</p>\n")
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/configuration"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+const DefaultInterval = 30 * time.Second
+
+// Scheduler publishes and archives posts when their publish_at and
+// unpublish_at times come around. It keeps no state of its own, so anything
+// that fell due while the server was down is handled on the first run.
+type Scheduler struct {
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func New(config *configuration.Config) *Scheduler {
+	interval := config.Scheduler.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Scheduler{
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until Stop is called or the
+// context is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	acontext.GetLogger(ctx).Infof("post scheduler running every %v", s.interval)
+	go s.loop(ctx)
+}
+
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Run(ctx); err != nil {
+			acontext.GetLogger(ctx).Errorf("error running post scheduler: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Run makes every transition that is due now. Losing a transition to
+// another server sharing the same storage isn't an error.
+func (s *Scheduler) Run(ctx context.Context) error {
+	now := time.Now().Unix()
+	postsRaw, err := cqrs.DispatchQuery(ctx, &queries.FindDuePosts{Now: now})
+	if err != nil {
+		return err
+	}
+
+	posts, ok := postsRaw.([]*v1.Post)
+	if !ok {
+		return fmt.Errorf("couldn't convert raw value (%#+v) to posts", postsRaw)
+	}
+
+	for _, p := range posts {
+		to := nextState(p, now)
+		log := acontext.GetLoggerWithField(ctx, "post.name", p.Name)
+		err := cqrs.DispatchCommand(ctx, &commands.TransitionPost{
+			Name:    p.Name,
+			Version: p.Version,
+			To:      to,
+		})
+
+		switch err {
+		case nil:
+			log.Infof("blog post %q is now %s", p.Name, to)
+		case storage.ErrConflict, storage.ErrNotFound:
+			log.Debugf("blog post %q changed before it could be %s", p.Name, to)
+		default:
+			log.Errorf("error moving blog post %q to %s: %v", p.Name, to, err)
+		}
+	}
+
+	return nil
+}
+
+func nextState(p *v1.Post, now int64) v1.PostState {
+	if p.UnpublishAt > 0 && p.UnpublishAt <= now {
+		return v1.PostArchived
+	}
+
+	return v1.PostPublished
+}
@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+
+	"github.com/danielkrainas/tinkersnest/actions"
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/configuration"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+	"github.com/danielkrainas/tinkersnest/storage/driver/factory"
+	_ "github.com/danielkrainas/tinkersnest/storage/driver/inmemory"
+)
+
+// runner runs schedulers against in-memory storage, calling found after a
+// run has found the due posts and before it moves them.
+type runner struct {
+	posts     storage.PostStore
+	revisions storage.RevisionStore
+	found     func()
+
+	m           sync.Mutex
+	transitions []error
+}
+
+func newRunner(t *testing.T, posts ...*v1.Post) *runner {
+	store, err := factory.Create("inmemory", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the stores are made up front, since the driver makes them lazily
+	rn := &runner{posts: store.Posts(), revisions: store.Revisions()}
+	for _, p := range posts {
+		if err := rn.posts.Store(p, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return rn
+}
+
+func (rn *runner) Execute(ctx context.Context, q cqrs.Query) (interface{}, error) {
+	switch qt := q.(type) {
+	case *queries.FindDuePosts:
+		posts, err := actions.FindDuePosts(ctx, qt, rn.posts)
+		if rn.found != nil {
+			rn.found()
+		}
+
+		return posts, err
+	}
+
+	return nil, cqrs.ErrNoExecutor
+}
+
+func (rn *runner) Handle(ctx context.Context, cmd cqrs.Command) error {
+	switch c := cmd.(type) {
+	case *commands.TransitionPost:
+		err := actions.TransitionPost(ctx, c, rn.posts, rn.revisions)
+		rn.m.Lock()
+		defer rn.m.Unlock()
+		rn.transitions = append(rn.transitions, err)
+		return err
+	}
+
+	return cqrs.ErrNoHandler
+}
+
+func (rn *runner) run(t *testing.T) {
+	ctx := cqrs.WithCommandDispatch(acontext.Background(), &cqrs.CommandDispatcher{
+		Handlers: []cqrs.CommandHandler{rn},
+	})
+
+	ctx = cqrs.WithQueryDispatch(ctx, &cqrs.QueryDispatcher{
+		Executors: []cqrs.QueryExecutor{rn},
+	})
+
+	if err := New(&configuration.Config{}).Run(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func (rn *runner) post(t *testing.T, name string) *v1.Post {
+	p, err := rn.posts.Find(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func duePost() *v1.Post {
+	return &v1.Post{
+		Name:      "due",
+		Title:     "Due",
+		State:     v1.PostScheduled,
+		PublishAt: time.Now().Unix() - 60,
+	}
+}
+
+func TestConcurrentRunsTransitionOnce(t *testing.T) {
+	rn := newRunner(t, duePost())
+
+	// neither run moves the post until both have found it due
+	found := &sync.WaitGroup{}
+	found.Add(2)
+	rn.found = func() {
+		found.Done()
+		found.Wait()
+	}
+
+	done := &sync.WaitGroup{}
+	for i := 0; i < 2; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			rn.run(t)
+		}()
+	}
+
+	done.Wait()
+	won := 0
+	for _, err := range rn.transitions {
+		if err == nil {
+			won++
+		} else if err != storage.ErrConflict {
+			t.Errorf("losing transition failed with %v, want %v", err, storage.ErrConflict)
+		}
+	}
+
+	if len(rn.transitions) != 2 || won != 1 {
+		t.Fatalf("transitions = %v, want one to win out of two", rn.transitions)
+	}
+
+	if p := rn.post(t, "due"); p.State != v1.PostPublished || !p.Publish || p.Version != 2 {
+		t.Errorf("post is %s, publish %v at version %d, want published once", p.State, p.Publish, p.Version)
+	}
+
+	if revisions, err := rn.revisions.FindMany("due"); err != nil {
+		t.Fatal(err)
+	} else if len(revisions) != 1 {
+		t.Errorf("post has %d revisions, want 1 for the transition", len(revisions))
+	}
+}
+
+func TestEditedPostNotTransitioned(t *testing.T) {
+	rn := newRunner(t, duePost())
+	later := time.Now().Unix() + 3600
+
+	// the author puts the post off after the run found it due
+	rn.found = func() {
+		p := rn.post(t, "due")
+		p.Title = "Edited"
+		p.PublishAt = later
+		if err := rn.posts.Store(p, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rn.run(t)
+	if len(rn.transitions) != 1 || rn.transitions[0] != storage.ErrConflict {
+		t.Fatalf("transitions = %v, want one conflict", rn.transitions)
+	}
+
+	p := rn.post(t, "due")
+	if p.State != v1.PostScheduled || p.Publish || p.Title != "Edited" || p.PublishAt != later {
+		t.Errorf("edited post = %+v, want it kept as edited", p)
+	}
+
+	// the next run leaves it alone until its new time
+	rn.found = nil
+	rn.run(t)
+	if len(rn.transitions) != 1 {
+		t.Errorf("post was moved again before it was due: %v", rn.transitions)
+	}
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"sync"
+)
+
+// Index is an inverted index from terms to the posts containing them. It is
+// kept up to date one post at a time as posts are stored and deleted.
+type Index struct {
+	m        sync.RWMutex
+	postings map[string]map[string]float64
+	terms    map[string][]string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]float64),
+		terms:    make(map[string][]string),
+	}
+}
+
+// Add indexes the document under name, replacing anything previously indexed
+// under it.
+func (i *Index) Add(name string, d *Document) {
+	i.m.Lock()
+	defer i.m.Unlock()
+	i.remove(name)
+
+	counts := d.weightedTerms()
+	terms := make([]string, 0, len(counts))
+	for t, n := range counts {
+		docs, ok := i.postings[t]
+		if !ok {
+			docs = make(map[string]float64)
+			i.postings[t] = docs
+		}
+
+		docs[name] = n
+		terms = append(terms, t)
+	}
+
+	i.terms[name] = terms
+}
+
+func (i *Index) Remove(name string) {
+	i.m.Lock()
+	defer i.m.Unlock()
+	i.remove(name)
+}
+
+func (i *Index) remove(name string) {
+	for _, t := range i.terms[name] {
+		docs := i.postings[t]
+		delete(docs, name)
+		if len(docs) == 0 {
+			delete(i.postings, t)
+		}
+	}
+
+	delete(i.terms, name)
+}
+
+// Search scores every indexed post that contains at least one of the query
+// terms. Posts score higher the more often the terms appear in them, and
+// matches in the title and tags count for more than ones in the body.
+func (i *Index) Search(query string) map[string]float64 {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, t := range Terms(query) {
+		if seen[t] {
+			continue
+		}
+
+		seen[t] = true
+		for name, n := range i.postings[t] {
+			scores[name] += n
+		}
+	}
+
+	return scores
+}
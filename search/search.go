@@ -0,0 +1,147 @@
+// Package search extracts the searchable text of posts and ranks them
+// against free-text queries.
+package search
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/russross/blackfriday"
+	"golang.org/x/net/html"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// Weights of each field when ranking, so a word in the title counts for more
+// than the same word buried in the body.
+const (
+	TitleWeight = 10
+	TagsWeight  = 5
+	BodyWeight  = 1
+)
+
+// Document is the searchable text of a post with Markdown and HTML stripped.
+type Document struct {
+	Title string   `bson:"title"`
+	Tags  []string `bson:"tags"`
+	Body  string   `bson:"body"`
+}
+
+func NewDocument(p *v1.Post) *Document {
+	parts := make([]string, 0, len(p.Content))
+	for _, c := range p.Content {
+		if text := PlainText(c); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	return &Document{
+		Title: p.Title,
+		Tags:  p.Tags,
+		Body:  strings.Join(parts, "\n"),
+	}
+}
+
+// PlainText returns the words a reader would see in a content block. Blobs
+// aren't inlined so they have no text.
+func PlainText(c *v1.Content) string {
+	switch v1.ContentType(c.Type) {
+	case v1.ContentMarkdown:
+		return htmlText(blackfriday.MarkdownCommon(c.Data))
+	case v1.ContentHtml:
+		return htmlText(c.Data)
+	}
+
+	return string(c.Data)
+}
+
+func htmlText(data []byte) string {
+	var buf bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(data))
+	skip := 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(buf.String())
+
+		case html.StartTagToken:
+			if name, _ := z.TagName(); skipped(name) {
+				skip++
+			}
+
+		case html.EndTagToken:
+			if name, _ := z.TagName(); skipped(name) && skip > 0 {
+				skip--
+			}
+
+		case html.TextToken:
+			if skip == 0 {
+				buf.Write(z.Text())
+				buf.WriteByte(' ')
+			}
+		}
+	}
+}
+
+func skipped(tag []byte) bool {
+	switch string(tag) {
+	case "script", "style":
+		return true
+	}
+
+	return false
+}
+
+// Terms splits text into lowercase words, folding plurals into their
+// singular form so "post" also finds "posts".
+func Terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+
+	return words
+}
+
+func singular(w string) string {
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+
+	return w
+}
+
+// weightedTerms counts every term in the document, scaled by the weight of
+// the field it was found in.
+func (d *Document) weightedTerms() map[string]float64 {
+	counts := make(map[string]float64)
+	for _, t := range Terms(d.Title) {
+		counts[t] += TitleWeight
+	}
+
+	for _, tag := range d.Tags {
+		for _, t := range Terms(tag) {
+			counts[t] += TagsWeight
+		}
+	}
+
+	for _, t := range Terms(d.Body) {
+		counts[t] += BodyWeight
+	}
+
+	return counts
+}
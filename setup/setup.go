@@ -17,7 +17,10 @@ import (
 
 type SetupManager struct {
 	firstUserClaim *v1.Claim
-	userMutex      sync.Mutex
+	// redeemedClaim is the first user claim once redeemed, kept so it can be
+	// given back if the first user couldn't be created.
+	redeemedClaim *v1.Claim
+	userMutex     sync.Mutex
 }
 
 func (m *SetupManager) Bootstrap(ctx context.Context) error {
@@ -30,8 +33,8 @@ func (m *SetupManager) Bootstrap(ctx context.Context) error {
 	if !ok {
 		return fmt.Errorf("couldn't convert raw value (%#+v) to user count", countRaw)
 	} else if count > 0 {
-		// user exists so we don't need to do anything
-		return nil
+		// user exists so we don't need a claim for the first one
+		return m.migrateRoles(ctx)
 	}
 
 	claim := m.AddFirstUserClaim(ctx)
@@ -39,6 +42,38 @@ func (m *SetupManager) Bootstrap(ctx context.Context) error {
 	return nil
 }
 
+// migrateRoles makes the oldest user an admin when no user has any roles, as
+// with users stored before roles existed. Users without roles are treated as
+// authors, so otherwise nobody could manage users.
+func (m *SetupManager) migrateRoles(ctx context.Context) error {
+	usersRaw, err := cqrs.DispatchQuery(ctx, &queries.SearchUsers{})
+	if err != nil {
+		return err
+	}
+
+	users, ok := usersRaw.([]*v1.User)
+	if !ok {
+		return fmt.Errorf("couldn't convert raw value (%#+v) to users", usersRaw)
+	} else if len(users) == 0 {
+		return nil
+	}
+
+	for _, u := range users {
+		if len(u.Roles) > 0 {
+			return nil
+		}
+	}
+
+	admin := users[0]
+	admin.Roles = []v1.Role{v1.RoleAdmin}
+	if err := cqrs.DispatchCommand(ctx, &commands.StoreUser{User: admin}); err != nil {
+		return err
+	}
+
+	acontext.GetLogger(ctx).Warnf("no user has any roles, made the oldest user %q an admin", admin.Name)
+	return nil
+}
+
 func (m *SetupManager) AddFirstUserClaim(ctx context.Context) *v1.Claim {
 	m.userMutex.Lock()
 	defer m.userMutex.Unlock()
@@ -55,6 +90,8 @@ func (m *SetupManager) Handle(ctx context.Context, cmd cqrs.Command) error {
 	switch ct := cmd.(type) {
 	case *commands.RedeemClaim:
 		return m.handleFirstUserClaim(ctx, ct)
+	case *commands.ReleaseClaim:
+		return m.handleReleaseFirstUserClaim(ctx, ct)
 	}
 
 	return cqrs.ErrNoHandler
@@ -65,6 +102,7 @@ func (m *SetupManager) handleFirstUserClaim(ctx context.Context, cmd *commands.R
 	defer m.userMutex.Unlock()
 	if m.firstUserClaim != nil {
 		if cmd.Code == m.firstUserClaim.Code {
+			m.redeemedClaim = m.firstUserClaim
 			m.firstUserClaim = nil
 			acontext.GetLogger(ctx).Warnf("first user claim %s redeemed", cmd.Code)
 			return nil
@@ -74,6 +112,19 @@ func (m *SetupManager) handleFirstUserClaim(ctx context.Context, cmd *commands.R
 	return cqrs.ErrNoHandler
 }
 
+func (m *SetupManager) handleReleaseFirstUserClaim(ctx context.Context, cmd *commands.ReleaseClaim) error {
+	m.userMutex.Lock()
+	defer m.userMutex.Unlock()
+	if m.redeemedClaim != nil && m.redeemedClaim.Code == cmd.Code {
+		m.firstUserClaim = m.redeemedClaim
+		m.redeemedClaim = nil
+		acontext.GetLogger(ctx).Warnf("first user claim %s released, use it to create the first user", cmd.Code)
+		return nil
+	}
+
+	return cqrs.ErrNoHandler
+}
+
 func (m *SetupManager) Execute(ctx context.Context, q cqrs.Query) (interface{}, error) {
 	switch qt := q.(type) {
 	case *queries.FindClaim:
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// SortClaims orders claims from the oldest to the newest.
+func SortClaims(claims []*v1.Claim) {
+	sort.SliceStable(claims, func(i, j int) bool {
+		if claims[i].Created != claims[j].Created {
+			return claims[i].Created < claims[j].Created
+		}
+
+		return claims[i].Code < claims[j].Code
+	})
+}
+
+// RedeemClaim uses up one redemption of the claim if it can still be redeemed
+// at the given unix time, and reports whether it could. Stores call it while
+// holding whatever lock guards the claim.
+func RedeemClaim(c *v1.Claim, now int64) bool {
+	if c.Revoked != 0 || c.UsesLeft() == 0 || (c.Expires != 0 && c.Expires <= now) {
+		return false
+	}
+
+	c.Uses++
+	c.Redeemed = now
+	return true
+}
+
+// ReleaseClaim gives back one redemption of the claim and reports whether it
+// had one to give back. A claim with no uses left is no longer marked as
+// redeemed.
+func ReleaseClaim(c *v1.Claim) bool {
+	if c.Uses <= 0 {
+		return false
+	}
+
+	c.Uses--
+	if c.Uses == 0 {
+		c.Redeemed = 0
+	}
+
+	return true
+}
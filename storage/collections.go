@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+type ItemFilters struct {
+	Collection string
+	Published  *bool
+}
+
+func (f *ItemFilters) Match(i *v1.Item) bool {
+	return (f.Collection == "" || i.Collection == f.Collection) &&
+		(f.Published == nil || i.Publish == *f.Published)
+}
+
+// SortItems orders items by collection and then by name.
+func SortItems(items []*v1.Item) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Collection != items[j].Collection {
+			return items[i].Collection < items[j].Collection
+		}
+
+		return items[i].Name < items[j].Name
+	})
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+type CommentFilters struct {
+	Post  string
+	State v1.CommentState
+}
+
+func (f *CommentFilters) Match(c *v1.Comment) bool {
+	return (f.Post == "" || c.Post == f.Post) && (f.State == "" || c.State == f.State)
+}
+
+// SortComments orders comments oldest first so threads read top to bottom.
+func SortComments(comments []*v1.Comment) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].Created != comments[j].Created {
+			return comments[i].Created < comments[j].Created
+		}
+
+		return comments[i].ID < comments[j].ID
+	})
+}
+
+// Replies returns the ids of every comment below the one with the id,
+// however deeply nested.
+func Replies(comments []*v1.Comment, id string) []string {
+	children := make(map[string][]string)
+	for _, c := range comments {
+		if c.Parent != "" {
+			children[c.Parent] = append(children[c.Parent], c.ID)
+		}
+	}
+
+	var result []string
+	queue := children[id]
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		result = append(result, next)
+		queue = append(queue, children[next]...)
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+package file
+
+import (
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type apiKeyStore struct {
+	d *driver
+}
+
+var _ storage.APIKeyStore = &apiKeyStore{}
+
+func (s *apiKeyStore) Delete(id string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.APIKeys[id]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.APIKeys, id)
+		return nil
+	})
+}
+
+func (s *apiKeyStore) Store(key *v1.APIKey, isNew bool) error {
+	return s.d.update(func(db *database) error {
+		cp := *key
+		db.APIKeys[key.ID] = &cp
+		return nil
+	})
+}
+
+func (s *apiKeyStore) Find(id string) (*v1.APIKey, error) {
+	var key *v1.APIKey
+	err := s.d.view(func(db *database) error {
+		found, ok := db.APIKeys[id]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *found
+		key = &cp
+		return nil
+	})
+
+	return key, err
+}
+
+func (s *apiKeyStore) FindMany(f *storage.APIKeyFilters) ([]*v1.APIKey, error) {
+	keys := make([]*v1.APIKey, 0)
+	err := s.d.view(func(db *database) error {
+		for _, key := range db.APIKeys {
+			if f.User != "" && key.User != f.User {
+				continue
+			}
+
+			cp := *key
+			keys = append(keys, &cp)
+		}
+
+		return nil
+	})
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Created < keys[j].Created
+	})
+
+	return keys, err
+}
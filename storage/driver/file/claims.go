@@ -0,0 +1,84 @@
+package file
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type claimStore struct {
+	d *driver
+}
+
+var _ storage.ClaimStore = &claimStore{}
+
+func (s *claimStore) Delete(code string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Claims[code]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Claims, code)
+		return nil
+	})
+}
+
+func (s *claimStore) Store(c *v1.Claim, isNew bool) error {
+	return s.d.update(func(db *database) error {
+		cp := *c
+		db.Claims[c.Code] = &cp
+		return nil
+	})
+}
+
+func (s *claimStore) Find(code string) (*v1.Claim, error) {
+	var claim *v1.Claim
+	err := s.d.view(func(db *database) error {
+		c, ok := db.Claims[code]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *c
+		claim = &cp
+		return nil
+	})
+
+	return claim, err
+}
+
+func (s *claimStore) FindAll() ([]*v1.Claim, error) {
+	claims := make([]*v1.Claim, 0)
+	err := s.d.view(func(db *database) error {
+		for _, c := range db.Claims {
+			cp := *c
+			claims = append(claims, &cp)
+		}
+
+		return nil
+	})
+
+	storage.SortClaims(claims)
+	return claims, err
+}
+
+func (s *claimStore) Redeem(code string, now int64) error {
+	return s.d.update(func(db *database) error {
+		c, ok := db.Claims[code]
+		if !ok || !storage.RedeemClaim(c, now) {
+			return storage.ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *claimStore) Release(code string) error {
+	return s.d.update(func(db *database) error {
+		c, ok := db.Claims[code]
+		if !ok || !storage.ReleaseClaim(c) {
+			return storage.ErrNotFound
+		}
+
+		return nil
+	})
+}
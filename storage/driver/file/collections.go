@@ -0,0 +1,134 @@
+package file
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type collectionStore struct {
+	d *driver
+}
+
+var _ storage.CollectionStore = &collectionStore{}
+
+func (s *collectionStore) Delete(name string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Collections[name]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Collections, name)
+		return nil
+	})
+}
+
+func (s *collectionStore) Store(c *v1.Collection) error {
+	return s.d.update(func(db *database) error {
+		cp := *c
+		db.Collections[c.Name] = &cp
+		return nil
+	})
+}
+
+func (s *collectionStore) Find(name string) (*v1.Collection, error) {
+	var collection *v1.Collection
+	err := s.d.view(func(db *database) error {
+		c, ok := db.Collections[name]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *c
+		collection = &cp
+		return nil
+	})
+
+	return collection, err
+}
+
+func (s *collectionStore) FindAll() ([]*v1.Collection, error) {
+	collections := make([]*v1.Collection, 0)
+	err := s.d.view(func(db *database) error {
+		for _, c := range db.Collections {
+			cp := *c
+			collections = append(collections, &cp)
+		}
+
+		return nil
+	})
+
+	return collections, err
+}
+
+type itemStore struct {
+	d *driver
+}
+
+var _ storage.ItemStore = &itemStore{}
+
+func (s *itemStore) Delete(collection string, name string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Items[collection][name]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Items[collection], name)
+		return nil
+	})
+}
+
+func (s *itemStore) DeleteAll(collection string) error {
+	return s.d.update(func(db *database) error {
+		delete(db.Items, collection)
+		return nil
+	})
+}
+
+func (s *itemStore) Store(i *v1.Item) error {
+	return s.d.update(func(db *database) error {
+		items, ok := db.Items[i.Collection]
+		if !ok {
+			items = make(map[string]*v1.Item)
+			db.Items[i.Collection] = items
+		}
+
+		cp := *i
+		items[i.Name] = &cp
+		return nil
+	})
+}
+
+func (s *itemStore) Find(collection string, name string) (*v1.Item, error) {
+	var item *v1.Item
+	err := s.d.view(func(db *database) error {
+		i, ok := db.Items[collection][name]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *i
+		item = &cp
+		return nil
+	})
+
+	return item, err
+}
+
+func (s *itemStore) FindMany(f *storage.ItemFilters) ([]*v1.Item, error) {
+	items := make([]*v1.Item, 0)
+	err := s.d.view(func(db *database) error {
+		for _, collection := range db.Items {
+			for _, i := range collection {
+				if f.Match(i) {
+					cp := *i
+					items = append(items, &cp)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	storage.SortItems(items)
+	return items, err
+}
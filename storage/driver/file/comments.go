@@ -0,0 +1,97 @@
+package file
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type commentStore struct {
+	d *driver
+}
+
+var _ storage.CommentStore = &commentStore{}
+
+func (s *commentStore) Store(c *v1.Comment) error {
+	return s.d.update(func(db *database) error {
+		cp := *c
+		db.Comments[c.ID] = &cp
+		return nil
+	})
+}
+
+func (s *commentStore) Find(id string) (*v1.Comment, error) {
+	var c *v1.Comment
+	err := s.d.view(func(db *database) error {
+		found, ok := db.Comments[id]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *found
+		c = &cp
+		return nil
+	})
+
+	return c, err
+}
+
+func (s *commentStore) FindMany(f *storage.CommentFilters) ([]*v1.Comment, error) {
+	comments := make([]*v1.Comment, 0)
+	err := s.d.view(func(db *database) error {
+		for _, c := range db.Comments {
+			if f.Match(c) {
+				cp := *c
+				comments = append(comments, &cp)
+			}
+		}
+
+		return nil
+	})
+
+	storage.SortComments(comments)
+	return comments, err
+}
+
+func (s *commentStore) SetState(ids []string, state v1.CommentState) error {
+	return s.d.update(func(db *database) error {
+		for _, id := range ids {
+			if _, ok := db.Comments[id]; !ok {
+				return storage.ErrNotFound
+			}
+		}
+
+		for _, id := range ids {
+			db.Comments[id].State = state
+		}
+
+		return nil
+	})
+}
+
+func (s *commentStore) Delete(ids ...string) error {
+	return s.d.update(func(db *database) error {
+		for _, id := range ids {
+			if _, ok := db.Comments[id]; !ok {
+				return storage.ErrNotFound
+			}
+		}
+
+		for _, id := range ids {
+			delete(db.Comments, id)
+		}
+
+		return nil
+	})
+}
+
+func (s *commentStore) DeleteAll(postName string) error {
+	return s.d.update(func(db *database) error {
+		for id, c := range db.Comments {
+			if c.Post == postName {
+				delete(db.Comments, id)
+			}
+		}
+
+		return nil
+	})
+}
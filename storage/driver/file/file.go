@@ -0,0 +1,301 @@
+package file
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/danielkrainas/gobag/decouple/drivers"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+	"github.com/danielkrainas/tinkersnest/storage/driver/factory"
+)
+
+var ErrLocked = errors.New("storage file is locked by another process")
+
+type driverFactory struct{}
+
+func (f *driverFactory) Create(parameters map[string]interface{}) (drivers.DriverBase, error) {
+	path, ok := parameters["path"].(string)
+	if !ok || path == "" {
+		return nil, errors.New("path parameter invalid or missing")
+	}
+
+	return open(path)
+}
+
+func init() {
+	factory.Register("file", &driverFactory{})
+}
+
+// database is the full contents of the storage file. The whole thing is
+// rewritten on every change, which keeps writes atomic at the cost of
+// throughput; fine for the single-node sites this driver is meant for.
+type database struct {
+	Users  map[string]*v1.User
+	Posts  map[string]*v1.Post
+	Claims map[string]*v1.Claim
+
+	Sessions map[string]*v1.Session
+	APIKeys  map[string]*v1.APIKey
+
+	// Revisions holds the history of each post, keyed by post name.
+	Revisions map[string][]*v1.Revision
+
+	Tags       map[string]*v1.Tag
+	Categories map[string]*v1.Category
+
+	Comments map[string]*v1.Comment
+
+	Collections map[string]*v1.Collection
+	// Items holds the items of each collection, keyed by collection name
+	// and then by item name.
+	Items map[string]map[string]*v1.Item
+
+	Webhooks map[string]*v1.Webhook
+	// Deliveries is the log of every webhook's deliveries, oldest first.
+	Deliveries []*v1.Delivery
+}
+
+func newDatabase() *database {
+	return &database{
+		Users:  make(map[string]*v1.User),
+		Posts:  make(map[string]*v1.Post),
+		Claims: make(map[string]*v1.Claim),
+
+		Sessions: make(map[string]*v1.Session),
+		APIKeys:  make(map[string]*v1.APIKey),
+
+		Revisions: make(map[string][]*v1.Revision),
+
+		Tags:       make(map[string]*v1.Tag),
+		Categories: make(map[string]*v1.Category),
+
+		Comments: make(map[string]*v1.Comment),
+
+		Collections: make(map[string]*v1.Collection),
+		Items:       make(map[string]map[string]*v1.Item),
+
+		Webhooks: make(map[string]*v1.Webhook),
+	}
+}
+
+type driver struct {
+	m    sync.RWMutex
+	path string
+	lock *fileLock
+	db   *database
+
+	users     *userStore
+	posts     *postStore
+	claims    *claimStore
+	sessions  *sessionStore
+	apiKeys   *apiKeyStore
+	revisions *revisionStore
+
+	tags       *tagStore
+	categories *categoryStore
+	comments   *commentStore
+
+	collections *collectionStore
+	items       *itemStore
+
+	webhooks   *webhookStore
+	deliveries *deliveryStore
+}
+
+var _ storage.Driver = &driver{}
+
+func open(path string) (*driver, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	lock, err := acquireLock(path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+
+	d := &driver{
+		path: path,
+		lock: lock,
+	}
+
+	if err := d.load(); err != nil {
+		lock.Release()
+		return nil, err
+	}
+
+	d.users = &userStore{d}
+	d.posts = newPostStore(d)
+	d.claims = &claimStore{d}
+	d.sessions = &sessionStore{d}
+	d.apiKeys = &apiKeyStore{d}
+	d.revisions = &revisionStore{d}
+	d.tags = &tagStore{d}
+	d.categories = &categoryStore{d}
+	d.comments = &commentStore{d}
+	d.collections = &collectionStore{d}
+	d.items = &itemStore{d}
+	d.webhooks = &webhookStore{d}
+	d.deliveries = &deliveryStore{d}
+	return d, nil
+}
+
+func (d *driver) load() error {
+	buf, err := ioutil.ReadFile(d.path)
+	if os.IsNotExist(err) {
+		d.db = newDatabase()
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	db := newDatabase()
+	if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(db); err != nil {
+		return fmt.Errorf("error reading %s: %v", d.path, err)
+	}
+
+	d.db = db
+	return nil
+}
+
+func (d *driver) save() error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(d.db); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(d.path, buf.Bytes())
+}
+
+func (d *driver) view(fn func(db *database) error) error {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	return fn(d.db)
+}
+
+// update applies fn to the database and persists the result. If either step
+// fails the in-memory state is reloaded from the last good copy on disk.
+func (d *driver) update(fn func(db *database) error) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	err := fn(d.db)
+	if err == nil {
+		err = d.save()
+	}
+
+	if err != nil {
+		if lerr := d.load(); lerr != nil {
+			return fmt.Errorf("%v (reload failed: %v)", err, lerr)
+		}
+	}
+
+	return err
+}
+
+func (d *driver) Users() storage.UserStore {
+	return d.users
+}
+
+func (d *driver) Posts() storage.PostStore {
+	return d.posts
+}
+
+func (d *driver) Claims() storage.ClaimStore {
+	return d.claims
+}
+
+func (d *driver) Sessions() storage.SessionStore {
+	return d.sessions
+}
+
+func (d *driver) APIKeys() storage.APIKeyStore {
+	return d.apiKeys
+}
+
+func (d *driver) Revisions() storage.RevisionStore {
+	return d.revisions
+}
+
+func (d *driver) Tags() storage.TagStore {
+	return d.tags
+}
+
+func (d *driver) Categories() storage.CategoryStore {
+	return d.categories
+}
+
+// writeFileAtomic writes to a temp file in the same directory and renames it
+// over the destination, so readers only ever see the old or the new contents.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+func syncDir(dir string) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+
+	// not every platform supports fsync on directories
+	f.Sync()
+	f.Close()
+}
+
+func (d *driver) Comments() storage.CommentStore {
+	return d.comments
+}
+
+func (d *driver) Collections() storage.CollectionStore {
+	return d.collections
+}
+
+func (d *driver) Items() storage.ItemStore {
+	return d.items
+}
+
+func (d *driver) Webhooks() storage.WebhookStore {
+	return d.webhooks
+}
+
+func (d *driver) Deliveries() storage.DeliveryStore {
+	return d.deliveries
+}
@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package file
+
+import (
+	"os"
+)
+
+// fileLock falls back to an exclusively created lock file on windows. A
+// process that crashes leaves it behind and it must be removed by hand.
+type fileLock struct {
+	f *os.File
+}
+
+func acquireLock(path string) (*fileLock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
+	if os.IsExist(err) {
+		return nil, ErrLocked
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &fileLock{f}, nil
+}
+
+func (l *fileLock) Release() error {
+	l.f.Close()
+	return os.Remove(l.f.Name())
+}
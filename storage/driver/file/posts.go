@@ -0,0 +1,140 @@
+package file
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/search"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type postStore struct {
+	d *driver
+
+	// index isn't persisted, it's rebuilt from the posts when the database
+	// is opened.
+	index *search.Index
+}
+
+var _ storage.PostStore = &postStore{}
+
+func newPostStore(d *driver) *postStore {
+	s := &postStore{
+		d:     d,
+		index: search.NewIndex(),
+	}
+
+	for name, p := range d.db.Posts {
+		s.index.Add(name, search.NewDocument(p))
+	}
+
+	return s
+}
+
+func (s *postStore) Delete(name string) error {
+	err := s.d.update(func(db *database) error {
+		if _, ok := db.Posts[name]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Posts, name)
+		return nil
+	})
+
+	if err == nil {
+		s.index.Remove(name)
+	}
+
+	return err
+}
+
+func (s *postStore) Store(p *v1.Post, isNew bool) error {
+	cp := *p
+	cp.Version++
+	if isNew {
+		cp.Version = 1
+	}
+
+	err := s.d.update(func(db *database) error {
+		if stored, ok := db.Posts[p.Name]; ok && (isNew || stored.Version != p.Version) {
+			return storage.ErrConflict
+		}
+
+		db.Posts[p.Name] = &cp
+		return nil
+	})
+
+	if err == nil {
+		p.Version = cp.Version
+		s.index.Add(p.Name, search.NewDocument(p))
+	}
+
+	return err
+}
+
+func (s *postStore) Find(name string) (*v1.Post, error) {
+	var post *v1.Post
+	err := s.d.view(func(db *database) error {
+		p, ok := db.Posts[name]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *p
+		post = &cp
+		return nil
+	})
+
+	return post, err
+}
+
+func (s *postStore) FindMany(f *storage.PostFilters) ([]*v1.Post, error) {
+	posts := make([]*v1.Post, 0)
+	err := s.d.view(func(db *database) error {
+		for _, p := range db.Posts {
+			cp := *p
+			posts = append(posts, &cp)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Text != "" {
+		return storage.RankPosts(posts, s.index.Search(f.Text), f), nil
+	}
+
+	return storage.FilterPosts(posts, f), nil
+}
+
+func (s *postStore) FindDue(now int64) ([]*v1.Post, error) {
+	due := make([]*v1.Post, 0)
+	err := s.d.view(func(db *database) error {
+		for _, p := range db.Posts {
+			if storage.PostDue(p, now) {
+				cp := *p
+				due = append(due, &cp)
+			}
+		}
+
+		return nil
+	})
+
+	return due, err
+}
+
+func (s *postStore) Transition(name string, version int64, to v1.PostState) error {
+	return s.d.update(func(db *database) error {
+		p, ok := db.Posts[name]
+		if !ok {
+			return storage.ErrNotFound
+		} else if p.Version != version {
+			return storage.ErrConflict
+		}
+
+		storage.TransitionPost(p, to)
+		p.Version++
+		return nil
+	})
+}
@@ -0,0 +1,76 @@
+package file
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type revisionStore struct {
+	d *driver
+}
+
+var _ storage.RevisionStore = &revisionStore{}
+
+func (s *revisionStore) Append(r *v1.Revision) error {
+	return s.d.update(func(db *database) error {
+		name := r.Post.Name
+		cp := *r
+		cp.Number = len(db.Revisions[name]) + 1
+		db.Revisions[name] = append(db.Revisions[name], &cp)
+		r.Number = cp.Number
+		return nil
+	})
+}
+
+func (s *revisionStore) Find(postName string, number int) (*v1.Revision, error) {
+	var revision *v1.Revision
+	err := s.d.view(func(db *database) error {
+		revisions := db.Revisions[postName]
+		if number < 1 || number > len(revisions) {
+			return storage.ErrNotFound
+		}
+
+		cp := *revisions[number-1]
+		revision = &cp
+		return nil
+	})
+
+	return revision, err
+}
+
+func (s *revisionStore) FindMany(postName string) ([]*v1.Revision, error) {
+	revisions := make([]*v1.Revision, 0)
+	err := s.d.view(func(db *database) error {
+		for _, r := range db.Revisions[postName] {
+			cp := *r
+			revisions = append(revisions, &cp)
+		}
+
+		return nil
+	})
+
+	return revisions, err
+}
+
+func (s *revisionStore) Latest(postName string) (*v1.Revision, error) {
+	var revision *v1.Revision
+	err := s.d.view(func(db *database) error {
+		revisions := db.Revisions[postName]
+		if len(revisions) == 0 {
+			return storage.ErrNotFound
+		}
+
+		cp := *revisions[len(revisions)-1]
+		revision = &cp
+		return nil
+	})
+
+	return revision, err
+}
+
+func (s *revisionStore) DeleteAll(postName string) error {
+	return s.d.update(func(db *database) error {
+		delete(db.Revisions, postName)
+		return nil
+	})
+}
@@ -0,0 +1,71 @@
+package file
+
+import (
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type sessionStore struct {
+	d *driver
+}
+
+var _ storage.SessionStore = &sessionStore{}
+
+func (s *sessionStore) Delete(id string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Sessions[id]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Sessions, id)
+		return nil
+	})
+}
+
+func (s *sessionStore) Store(session *v1.Session, isNew bool) error {
+	return s.d.update(func(db *database) error {
+		cp := *session
+		db.Sessions[session.ID] = &cp
+		return nil
+	})
+}
+
+func (s *sessionStore) Find(id string) (*v1.Session, error) {
+	var session *v1.Session
+	err := s.d.view(func(db *database) error {
+		found, ok := db.Sessions[id]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *found
+		session = &cp
+		return nil
+	})
+
+	return session, err
+}
+
+func (s *sessionStore) FindMany(f *storage.SessionFilters) ([]*v1.Session, error) {
+	sessions := make([]*v1.Session, 0)
+	err := s.d.view(func(db *database) error {
+		for _, session := range db.Sessions {
+			if f.User != "" && session.User != f.User {
+				continue
+			}
+
+			cp := *session
+			sessions = append(sessions, &cp)
+		}
+
+		return nil
+	})
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Created < sessions[j].Created
+	})
+
+	return sessions, err
+}
@@ -0,0 +1,116 @@
+package file
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type tagStore struct {
+	d *driver
+}
+
+var _ storage.TagStore = &tagStore{}
+
+func (s *tagStore) Delete(slug string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Tags[slug]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Tags, slug)
+		return nil
+	})
+}
+
+func (s *tagStore) Store(t *v1.Tag) error {
+	return s.d.update(func(db *database) error {
+		cp := *t
+		db.Tags[t.Slug] = &cp
+		return nil
+	})
+}
+
+func (s *tagStore) Find(slug string) (*v1.Tag, error) {
+	var tag *v1.Tag
+	err := s.d.view(func(db *database) error {
+		t, ok := db.Tags[slug]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *t
+		tag = &cp
+		return nil
+	})
+
+	return tag, err
+}
+
+func (s *tagStore) FindAll() ([]*v1.Tag, error) {
+	tags := make([]*v1.Tag, 0)
+	err := s.d.view(func(db *database) error {
+		for _, t := range db.Tags {
+			cp := *t
+			tags = append(tags, &cp)
+		}
+
+		return nil
+	})
+
+	return tags, err
+}
+
+type categoryStore struct {
+	d *driver
+}
+
+var _ storage.CategoryStore = &categoryStore{}
+
+func (s *categoryStore) Delete(slug string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Categories[slug]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Categories, slug)
+		return nil
+	})
+}
+
+func (s *categoryStore) Store(c *v1.Category) error {
+	return s.d.update(func(db *database) error {
+		cp := *c
+		db.Categories[c.Slug] = &cp
+		return nil
+	})
+}
+
+func (s *categoryStore) Find(slug string) (*v1.Category, error) {
+	var category *v1.Category
+	err := s.d.view(func(db *database) error {
+		c, ok := db.Categories[slug]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *c
+		category = &cp
+		return nil
+	})
+
+	return category, err
+}
+
+func (s *categoryStore) FindAll() ([]*v1.Category, error) {
+	categories := make([]*v1.Category, 0)
+	err := s.d.view(func(db *database) error {
+		for _, c := range db.Categories {
+			cp := *c
+			categories = append(categories, &cp)
+		}
+
+		return nil
+	})
+
+	return categories, err
+}
@@ -0,0 +1,92 @@
+package file
+
+import (
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type userStore struct {
+	d *driver
+}
+
+var _ storage.UserStore = &userStore{}
+
+func (s *userStore) Delete(name string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Users[name]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Users, name)
+		return nil
+	})
+}
+
+func (s *userStore) Store(u *v1.User, isNew bool) error {
+	cp := *u
+	cp.Version++
+	if isNew {
+		cp.Version = 1
+	}
+
+	err := s.d.update(func(db *database) error {
+		if stored, ok := db.Users[u.Name]; ok && (isNew || stored.Version != u.Version) {
+			return storage.ErrConflict
+		}
+
+		db.Users[u.Name] = &cp
+		return nil
+	})
+
+	if err == nil {
+		u.Version = cp.Version
+	}
+
+	return err
+}
+
+func (s *userStore) Find(name string) (*v1.User, error) {
+	var user *v1.User
+	err := s.d.view(func(db *database) error {
+		u, ok := db.Users[name]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *u
+		user = &cp
+		return nil
+	})
+
+	return user, err
+}
+
+func (s *userStore) FindMany(f *storage.UserFilters) ([]*v1.User, error) {
+	users := make([]*v1.User, 0)
+	err := s.d.view(func(db *database) error {
+		for _, u := range db.Users {
+			cp := *u
+			users = append(users, &cp)
+		}
+
+		return nil
+	})
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+
+	return users, err
+}
+
+func (s *userStore) Count(f *storage.UserFilters) (int, error) {
+	count := 0
+	err := s.d.view(func(db *database) error {
+		count = len(db.Users)
+		return nil
+	})
+
+	return count, err
+}
@@ -0,0 +1,106 @@
+package file
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type webhookStore struct {
+	d *driver
+}
+
+var _ storage.WebhookStore = &webhookStore{}
+
+func (s *webhookStore) Delete(id string) error {
+	return s.d.update(func(db *database) error {
+		if _, ok := db.Webhooks[id]; !ok {
+			return storage.ErrNotFound
+		}
+
+		delete(db.Webhooks, id)
+		return nil
+	})
+}
+
+func (s *webhookStore) Store(h *v1.Webhook, isNew bool) error {
+	return s.d.update(func(db *database) error {
+		cp := *h
+		db.Webhooks[h.ID] = &cp
+		return nil
+	})
+}
+
+func (s *webhookStore) Find(id string) (*v1.Webhook, error) {
+	var hook *v1.Webhook
+	err := s.d.view(func(db *database) error {
+		h, ok := db.Webhooks[id]
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		cp := *h
+		hook = &cp
+		return nil
+	})
+
+	return hook, err
+}
+
+func (s *webhookStore) FindAll() ([]*v1.Webhook, error) {
+	hooks := make([]*v1.Webhook, 0)
+	err := s.d.view(func(db *database) error {
+		for _, h := range db.Webhooks {
+			cp := *h
+			hooks = append(hooks, &cp)
+		}
+
+		return nil
+	})
+
+	storage.SortWebhooks(hooks)
+	return hooks, err
+}
+
+type deliveryStore struct {
+	d *driver
+}
+
+var _ storage.DeliveryStore = &deliveryStore{}
+
+func (s *deliveryStore) Append(d *v1.Delivery, keep int) error {
+	return s.d.update(func(db *database) error {
+		cp := *d
+		db.Deliveries = storage.TrimDeliveries(append(db.Deliveries, &cp), d.Webhook, keep)
+		return nil
+	})
+}
+
+func (s *deliveryStore) FindMany(webhook string) ([]*v1.Delivery, error) {
+	deliveries := make([]*v1.Delivery, 0)
+	err := s.d.view(func(db *database) error {
+		for i := len(db.Deliveries) - 1; i >= 0; i-- {
+			if d := db.Deliveries[i]; d.Webhook == webhook {
+				cp := *d
+				deliveries = append(deliveries, &cp)
+			}
+		}
+
+		return nil
+	})
+
+	return deliveries, err
+}
+
+func (s *deliveryStore) DeleteAll(webhook string) error {
+	return s.d.update(func(db *database) error {
+		kept := make([]*v1.Delivery, 0, len(db.Deliveries))
+		for _, d := range db.Deliveries {
+			if d.Webhook != webhook {
+				kept = append(kept, d)
+			}
+		}
+
+		db.Deliveries = kept
+		return nil
+	})
+}
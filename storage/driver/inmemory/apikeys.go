@@ -0,0 +1,68 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type apiKeyStore struct {
+	m    sync.Mutex
+	keys []*v1.APIKey
+}
+
+func (s *apiKeyStore) FindMany(f *storage.APIKeyFilters) ([]*v1.APIKey, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.APIKey, 0)
+	for _, key := range s.keys {
+		if f.User == "" || key.User == f.User {
+			result = append(result, key)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *apiKeyStore) Delete(id string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, key := range s.keys {
+		if key.ID == id {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *apiKeyStore) Store(key *v1.APIKey, isNew bool) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !isNew {
+		for i, k2 := range s.keys {
+			if k2.ID == key.ID {
+				s.keys[i] = key
+				return nil
+			}
+		}
+	}
+
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *apiKeyStore) Find(id string) (*v1.APIKey, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, key := range s.keys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
@@ -12,10 +12,31 @@ type claimStore struct {
 	claims []*v1.Claim
 }
 
+func (s *claimStore) Delete(code string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, c := range s.claims {
+		if c.Code == code {
+			s.claims = append(s.claims[:i], s.claims[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
 func (s *claimStore) Store(c *v1.Claim, isNew bool) error {
 	s.m.Lock()
 	defer s.m.Unlock()
-	s.claims = append(s.claims, c)
+	cp := *c
+	for i, c2 := range s.claims {
+		if c2.Code == c.Code {
+			s.claims[i] = &cp
+			return nil
+		}
+	}
+
+	s.claims = append(s.claims, &cp)
 	return nil
 }
 
@@ -25,9 +46,47 @@ func (s *claimStore) Find(code string) (*v1.Claim, error) {
 
 	for _, c := range s.claims {
 		if c.Code == code {
-			return c, nil
+			cp := *c
+			return &cp, nil
 		}
 	}
 
 	return nil, storage.ErrNotFound
 }
+
+func (s *claimStore) FindAll() ([]*v1.Claim, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Claim, 0, len(s.claims))
+	for _, c := range s.claims {
+		cp := *c
+		result = append(result, &cp)
+	}
+
+	storage.SortClaims(result)
+	return result, nil
+}
+
+func (s *claimStore) Redeem(code string, now int64) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, c := range s.claims {
+		if c.Code == code && storage.RedeemClaim(c, now) {
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *claimStore) Release(code string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, c := range s.claims {
+		if c.Code == code && storage.ReleaseClaim(c) {
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
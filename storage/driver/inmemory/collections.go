@@ -0,0 +1,141 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type collectionStore struct {
+	m           sync.Mutex
+	collections []*v1.Collection
+}
+
+func (s *collectionStore) Delete(name string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, c := range s.collections {
+		if c.Name == name {
+			s.collections = append(s.collections[:i], s.collections[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *collectionStore) Store(c *v1.Collection) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	cp := *c
+	for i, c2 := range s.collections {
+		if c2.Name == c.Name {
+			s.collections[i] = &cp
+			return nil
+		}
+	}
+
+	s.collections = append(s.collections, &cp)
+	return nil
+}
+
+func (s *collectionStore) Find(name string) (*v1.Collection, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, c := range s.collections {
+		if c.Name == name {
+			cp := *c
+			return &cp, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+func (s *collectionStore) FindAll() ([]*v1.Collection, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Collection, 0, len(s.collections))
+	for _, c := range s.collections {
+		cp := *c
+		result = append(result, &cp)
+	}
+
+	return result, nil
+}
+
+type itemStore struct {
+	m     sync.Mutex
+	items []*v1.Item
+}
+
+func (s *itemStore) Delete(collection string, name string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, item := range s.items {
+		if item.Collection == collection && item.Name == name {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *itemStore) DeleteAll(collection string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	kept := s.items[:0]
+	for _, item := range s.items {
+		if item.Collection != collection {
+			kept = append(kept, item)
+		}
+	}
+
+	s.items = kept
+	return nil
+}
+
+func (s *itemStore) Store(i *v1.Item) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	cp := *i
+	for j, item := range s.items {
+		if item.Collection == i.Collection && item.Name == i.Name {
+			s.items[j] = &cp
+			return nil
+		}
+	}
+
+	s.items = append(s.items, &cp)
+	return nil
+}
+
+func (s *itemStore) Find(collection string, name string) (*v1.Item, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, item := range s.items {
+		if item.Collection == collection && item.Name == name {
+			cp := *item
+			return &cp, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+func (s *itemStore) FindMany(f *storage.ItemFilters) ([]*v1.Item, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Item, 0)
+	for _, item := range s.items {
+		if f.Match(item) {
+			cp := *item
+			result = append(result, &cp)
+		}
+	}
+
+	storage.SortItems(result)
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type commentStore struct {
+	m        sync.Mutex
+	comments []*v1.Comment
+}
+
+func (s *commentStore) Store(c *v1.Comment) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	cp := *c
+	for i, c2 := range s.comments {
+		if c2.ID == c.ID {
+			s.comments[i] = &cp
+			return nil
+		}
+	}
+
+	s.comments = append(s.comments, &cp)
+	return nil
+}
+
+func (s *commentStore) Find(id string) (*v1.Comment, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if i := s.index(id); i >= 0 {
+		cp := *s.comments[i]
+		return &cp, nil
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+func (s *commentStore) FindMany(f *storage.CommentFilters) ([]*v1.Comment, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Comment, 0)
+	for _, c := range s.comments {
+		if f.Match(c) {
+			cp := *c
+			result = append(result, &cp)
+		}
+	}
+
+	storage.SortComments(result)
+	return result, nil
+}
+
+func (s *commentStore) SetState(ids []string, state v1.CommentState) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, id := range ids {
+		if s.index(id) < 0 {
+			return storage.ErrNotFound
+		}
+	}
+
+	for _, id := range ids {
+		s.comments[s.index(id)].State = state
+	}
+
+	return nil
+}
+
+func (s *commentStore) Delete(ids ...string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, id := range ids {
+		if s.index(id) < 0 {
+			return storage.ErrNotFound
+		}
+	}
+
+	for _, id := range ids {
+		i := s.index(id)
+		s.comments = append(s.comments[:i], s.comments[i+1:]...)
+	}
+
+	return nil
+}
+
+func (s *commentStore) DeleteAll(postName string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	kept := s.comments[:0]
+	for _, c := range s.comments {
+		if c.Post != postName {
+			kept = append(kept, c)
+		}
+	}
+
+	s.comments = kept
+	return nil
+}
+
+func (s *commentStore) index(id string) int {
+	for i, c := range s.comments {
+		if c.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -46,9 +46,109 @@ func (d *driver) Claims() storage.ClaimStore {
 func (d *driver) Posts() storage.PostStore {
 	store, ok := d.stores["post"].(storage.PostStore)
 	if !ok {
-		store = &postStore{}
+		store = newPostStore()
 		d.stores["post"] = store
 	}
 
 	return store
 }
+
+func (d *driver) Sessions() storage.SessionStore {
+	store, ok := d.stores["session"].(storage.SessionStore)
+	if !ok {
+		store = &sessionStore{}
+		d.stores["session"] = store
+	}
+
+	return store
+}
+
+func (d *driver) APIKeys() storage.APIKeyStore {
+	store, ok := d.stores["apikey"].(storage.APIKeyStore)
+	if !ok {
+		store = &apiKeyStore{}
+		d.stores["apikey"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Revisions() storage.RevisionStore {
+	store, ok := d.stores["revision"].(storage.RevisionStore)
+	if !ok {
+		store = &revisionStore{}
+		d.stores["revision"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Tags() storage.TagStore {
+	store, ok := d.stores["tag"].(storage.TagStore)
+	if !ok {
+		store = &tagStore{}
+		d.stores["tag"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Categories() storage.CategoryStore {
+	store, ok := d.stores["category"].(storage.CategoryStore)
+	if !ok {
+		store = &categoryStore{}
+		d.stores["category"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Comments() storage.CommentStore {
+	store, ok := d.stores["comment"].(storage.CommentStore)
+	if !ok {
+		store = &commentStore{}
+		d.stores["comment"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Collections() storage.CollectionStore {
+	store, ok := d.stores["collection"].(storage.CollectionStore)
+	if !ok {
+		store = &collectionStore{}
+		d.stores["collection"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Items() storage.ItemStore {
+	store, ok := d.stores["item"].(storage.ItemStore)
+	if !ok {
+		store = &itemStore{}
+		d.stores["item"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Webhooks() storage.WebhookStore {
+	store, ok := d.stores["webhook"].(storage.WebhookStore)
+	if !ok {
+		store = &webhookStore{}
+		d.stores["webhook"] = store
+	}
+
+	return store
+}
+
+func (d *driver) Deliveries() storage.DeliveryStore {
+	store, ok := d.stores["delivery"].(storage.DeliveryStore)
+	if !ok {
+		store = &deliveryStore{}
+		d.stores["delivery"] = store
+	}
+
+	return store
+}
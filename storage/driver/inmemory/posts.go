@@ -4,18 +4,42 @@ import (
 	"sync"
 
 	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/search"
 	"github.com/danielkrainas/tinkersnest/storage"
 )
 
 type postStore struct {
 	m     sync.Mutex
 	posts []*v1.Post
+	index *search.Index
+}
+
+func newPostStore() *postStore {
+	return &postStore{
+		index: search.NewIndex(),
+	}
 }
 
 func (s *postStore) FindMany(f *storage.PostFilters) ([]*v1.Post, error) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	return s.posts[:], nil
+	if f.Text != "" {
+		return copyPosts(storage.RankPosts(s.posts, s.index.Search(f.Text), f)), nil
+	}
+
+	return copyPosts(storage.FilterPosts(s.posts, f)), nil
+}
+
+// copyPosts copies the posts so that changing them doesn't change what's
+// stored behind the back of Store.
+func copyPosts(posts []*v1.Post) []*v1.Post {
+	copies := make([]*v1.Post, len(posts))
+	for i, p := range posts {
+		cp := *p
+		copies[i] = &cp
+	}
+
+	return copies
 }
 
 func (s *postStore) Delete(name string) error {
@@ -24,6 +48,7 @@ func (s *postStore) Delete(name string) error {
 	for i, p := range s.posts {
 		if p.Name == name {
 			s.posts = append(s.posts[:i], s.posts[i+1:]...)
+			s.index.Remove(name)
 			return nil
 		}
 	}
@@ -35,21 +60,31 @@ func (s *postStore) Store(p *v1.Post, isNew bool) error {
 	s.m.Lock()
 	defer s.m.Unlock()
 
+	cp := *p
+	cp.Version++
+	if isNew {
+		cp.Version = 1
+	}
+
 	found := false
-	if !isNew {
-		for i, p2 := range s.posts {
-			if p2.Name == p.Name {
-				s.posts[i] = p
-				found = true
-				break
+	for i, p2 := range s.posts {
+		if p2.Name == p.Name {
+			if isNew || p2.Version != p.Version {
+				return storage.ErrConflict
 			}
+
+			s.posts[i] = &cp
+			found = true
+			break
 		}
 	}
 
 	if !found {
-		s.posts = append(s.posts, p)
+		s.posts = append(s.posts, &cp)
 	}
 
+	p.Version = cp.Version
+	s.index.Add(p.Name, search.NewDocument(p))
 	return nil
 }
 
@@ -58,9 +93,42 @@ func (s *postStore) Find(name string) (*v1.Post, error) {
 	defer s.m.Unlock()
 	for _, p := range s.posts {
 		if p.Name == name {
-			return p, nil
+			cp := *p
+			return &cp, nil
 		}
 	}
 
 	return nil, nil
 }
+
+func (s *postStore) FindDue(now int64) ([]*v1.Post, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	due := make([]*v1.Post, 0)
+	for _, p := range s.posts {
+		if storage.PostDue(p, now) {
+			cp := *p
+			due = append(due, &cp)
+		}
+	}
+
+	return due, nil
+}
+
+func (s *postStore) Transition(name string, version int64, to v1.PostState) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, p := range s.posts {
+		if p.Name == name {
+			if p.Version != version {
+				return storage.ErrConflict
+			}
+
+			storage.TransitionPost(p, to)
+			p.Version++
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
@@ -0,0 +1,189 @@
+package inmemory
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+// newTestPosts returns a post store with posts whose created times and titles
+// tie, so that only their names order them.
+func newTestPosts(t *testing.T) storage.PostStore {
+	posts := []*v1.Post{
+		{Name: "a", Created: 100, Title: "Beta"},
+		{Name: "b", Created: 200, Title: "Alpha"},
+		{Name: "c", Created: 200, Title: "Alpha"},
+		{Name: "d", Created: 200, Title: "Gamma"},
+		{Name: "e", Created: 300, Title: "Delta"},
+		{Name: "f", Created: 50, Title: "Alpha"},
+	}
+
+	s := newPostStore()
+	for _, p := range posts {
+		if err := s.Store(p, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return s
+}
+
+// readPages reads every page of the posts the way searches do, asking for one
+// more post than the limit to find out whether there's a next page and
+// passing the cursor along encoded.
+func readPages(t *testing.T, s storage.PostStore, sort storage.PostSort, limit int) [][]string {
+	pages := make([][]string, 0)
+	next := ""
+	for {
+		f := &storage.PostFilters{Sort: sort, Limit: limit + 1}
+		if next != "" {
+			c, err := storage.DecodePostCursor(next)
+			if err != nil {
+				t.Fatalf("decoding cursor %q: %v", next, err)
+			}
+
+			f.Cursor = c
+		}
+
+		posts, err := s.FindMany(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		more := len(posts) > limit
+		if more {
+			posts = posts[:limit]
+		}
+
+		page := make([]string, len(posts))
+		for i, p := range posts {
+			page[i] = p.Name
+		}
+
+		pages = append(pages, page)
+		if !more {
+			return pages
+		}
+
+		next = storage.EncodePostCursor(posts[len(posts)-1])
+		if len(pages) > 10 {
+			t.Fatalf("still paging after %v", pages)
+		}
+	}
+}
+
+func TestPostPages(t *testing.T) {
+	s := newTestPosts(t)
+	tests := []struct {
+		sort  storage.PostSort
+		limit int
+		pages string
+	}{
+		{storage.SortCreatedDesc, 2, "e,d|c,b|a,f"},
+		{storage.SortCreatedDesc, 4, "e,d,c,b|a,f"},
+		{storage.SortCreatedDesc, 6, "e,d,c,b,a,f"},
+		{storage.SortCreatedDesc, 10, "e,d,c,b,a,f"},
+		{storage.SortCreatedAsc, 1, "f|a|b|c|d|e"},
+		{storage.SortCreatedAsc, 3, "f,a,b|c,d,e"},
+		{storage.SortCreatedAsc, 5, "f,a,b,c,d|e"},
+		{storage.SortTitleAsc, 2, "b,c|f,a|e,d"},
+		{storage.SortTitleAsc, 4, "b,c,f,a|e,d"},
+		{storage.SortTitleDesc, 2, "d,e|a,f|c,b"},
+		{storage.SortTitleDesc, 5, "d,e,a,f,c|b"},
+	}
+
+	for _, test := range tests {
+		pages := readPages(t, s, test.sort, test.limit)
+		joined := make([]string, len(pages))
+		for i, page := range pages {
+			joined[i] = strings.Join(page, ",")
+		}
+
+		if got := strings.Join(joined, "|"); got != test.pages {
+			t.Errorf("sort %q by %d: pages = %s, want %s", test.sort, test.limit, got, test.pages)
+		}
+	}
+}
+
+func TestPostCursorAfterLastPost(t *testing.T) {
+	s := newTestPosts(t)
+	tests := []struct {
+		sort storage.PostSort
+		last string
+	}{
+		{storage.SortCreatedDesc, "f"},
+		{storage.SortCreatedAsc, "e"},
+		{storage.SortTitleAsc, "d"},
+		{storage.SortTitleDesc, "b"},
+	}
+
+	for _, test := range tests {
+		p, err := s.Find(test.last)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := storage.DecodePostCursor(storage.EncodePostCursor(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		posts, err := s.FindMany(&storage.PostFilters{Sort: test.sort, Cursor: c})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(posts) != 0 {
+			t.Errorf("sort %q: %d posts after the last one", test.sort, len(posts))
+		}
+	}
+}
+
+func TestPostCursorOfDeletedPost(t *testing.T) {
+	s := newTestPosts(t)
+	p, err := s.Find("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursor := storage.EncodePostCursor(p)
+	if err := s.Delete("c"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the cursor holds the post's keys, so the page resumes where it would have
+	c, err := storage.DecodePostCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts, err := s.FindMany(&storage.PostFilters{Sort: storage.SortCreatedDesc, Cursor: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, len(posts))
+	for i, p := range posts {
+		names[i] = p.Name
+	}
+
+	if got := strings.Join(names, ","); got != "b,a,f" {
+		t.Errorf("posts after deleted cursor = %s, want b,a,f", got)
+	}
+}
+
+func TestDecodeInvalidPostCursor(t *testing.T) {
+	cursors := []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"c":100}`)),
+	}
+
+	for _, raw := range cursors {
+		if _, err := storage.DecodePostCursor(raw); err != storage.ErrInvalidCursor {
+			t.Errorf("decoding %q = %v, want %v", raw, err, storage.ErrInvalidCursor)
+		}
+	}
+}
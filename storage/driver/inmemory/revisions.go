@@ -0,0 +1,61 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type revisionStore struct {
+	m         sync.Mutex
+	revisions map[string][]*v1.Revision
+}
+
+func (s *revisionStore) Append(r *v1.Revision) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.revisions == nil {
+		s.revisions = make(map[string][]*v1.Revision)
+	}
+
+	name := r.Post.Name
+	r.Number = len(s.revisions[name]) + 1
+	s.revisions[name] = append(s.revisions[name], r)
+	return nil
+}
+
+func (s *revisionStore) Find(postName string, number int) (*v1.Revision, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	revisions := s.revisions[postName]
+	if number < 1 || number > len(revisions) {
+		return nil, storage.ErrNotFound
+	}
+
+	return revisions[number-1], nil
+}
+
+func (s *revisionStore) FindMany(postName string) ([]*v1.Revision, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]*v1.Revision{}, s.revisions[postName]...), nil
+}
+
+func (s *revisionStore) Latest(postName string) (*v1.Revision, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	revisions := s.revisions[postName]
+	if len(revisions) == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	return revisions[len(revisions)-1], nil
+}
+
+func (s *revisionStore) DeleteAll(postName string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.revisions, postName)
+	return nil
+}
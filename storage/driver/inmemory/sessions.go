@@ -0,0 +1,68 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type sessionStore struct {
+	m        sync.Mutex
+	sessions []*v1.Session
+}
+
+func (s *sessionStore) FindMany(f *storage.SessionFilters) ([]*v1.Session, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Session, 0)
+	for _, session := range s.sessions {
+		if f.User == "" || session.User == f.User {
+			result = append(result, session)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *sessionStore) Delete(id string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, session := range s.sessions {
+		if session.ID == id {
+			s.sessions = append(s.sessions[:i], s.sessions[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *sessionStore) Store(session *v1.Session, isNew bool) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !isNew {
+		for i, s2 := range s.sessions {
+			if s2.ID == session.ID {
+				s.sessions[i] = session
+				return nil
+			}
+		}
+	}
+
+	s.sessions = append(s.sessions, session)
+	return nil
+}
+
+func (s *sessionStore) Find(id string) (*v1.Session, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, session := range s.sessions {
+		if session.ID == id {
+			return session, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
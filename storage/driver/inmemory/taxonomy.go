@@ -0,0 +1,124 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type tagStore struct {
+	m    sync.Mutex
+	tags []*v1.Tag
+}
+
+func (s *tagStore) Delete(slug string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, t := range s.tags {
+		if t.Slug == slug {
+			s.tags = append(s.tags[:i], s.tags[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *tagStore) Store(t *v1.Tag) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	cp := *t
+	for i, t2 := range s.tags {
+		if t2.Slug == t.Slug {
+			s.tags[i] = &cp
+			return nil
+		}
+	}
+
+	s.tags = append(s.tags, &cp)
+	return nil
+}
+
+func (s *tagStore) Find(slug string) (*v1.Tag, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, t := range s.tags {
+		if t.Slug == slug {
+			cp := *t
+			return &cp, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+func (s *tagStore) FindAll() ([]*v1.Tag, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Tag, 0, len(s.tags))
+	for _, t := range s.tags {
+		cp := *t
+		result = append(result, &cp)
+	}
+
+	return result, nil
+}
+
+type categoryStore struct {
+	m          sync.Mutex
+	categories []*v1.Category
+}
+
+func (s *categoryStore) Delete(slug string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, c := range s.categories {
+		if c.Slug == slug {
+			s.categories = append(s.categories[:i], s.categories[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *categoryStore) Store(c *v1.Category) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	cp := *c
+	for i, c2 := range s.categories {
+		if c2.Slug == c.Slug {
+			s.categories[i] = &cp
+			return nil
+		}
+	}
+
+	s.categories = append(s.categories, &cp)
+	return nil
+}
+
+func (s *categoryStore) Find(slug string) (*v1.Category, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, c := range s.categories {
+		if c.Slug == slug {
+			cp := *c
+			return &cp, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+func (s *categoryStore) FindAll() ([]*v1.Category, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Category, 0, len(s.categories))
+	for _, c := range s.categories {
+		cp := *c
+		result = append(result, &cp)
+	}
+
+	return result, nil
+}
@@ -16,21 +16,30 @@ func (s *userStore) Store(u *v1.User, isNew bool) error {
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	found := false
+	cp := *u
+	cp.Version++
 	if isNew {
-		for i, u2 := range s.users {
-			if u2.Name == u.Name {
-				s.users[i] = u
-				found = true
-				break
+		cp.Version = 1
+	}
+
+	found := false
+	for i, u2 := range s.users {
+		if u2.Name == u.Name {
+			if isNew || u2.Version != u.Version {
+				return storage.ErrConflict
 			}
+
+			s.users[i] = &cp
+			found = true
+			break
 		}
 	}
 
 	if !found {
-		s.users = append(s.users, u)
+		s.users = append(s.users, &cp)
 	}
 
+	u.Version = cp.Version
 	return nil
 }
 
@@ -52,7 +61,8 @@ func (s *userStore) Find(name string) (*v1.User, error) {
 	defer s.m.Unlock()
 	for _, u := range s.users {
 		if u.Name == name {
-			return u, nil
+			cp := *u
+			return &cp, nil
 		}
 	}
 
@@ -68,5 +78,11 @@ func (s *userStore) Count(f *storage.UserFilters) (int, error) {
 func (s *userStore) FindMany(f *storage.UserFilters) ([]*v1.User, error) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	return s.users[:], nil
+	users := make([]*v1.User, len(s.users))
+	for i, u := range s.users {
+		cp := *u
+		users[i] = &cp
+	}
+
+	return users, nil
 }
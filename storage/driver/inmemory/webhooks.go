@@ -0,0 +1,109 @@
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type webhookStore struct {
+	m     sync.Mutex
+	hooks []*v1.Webhook
+}
+
+func (s *webhookStore) Delete(id string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, h := range s.hooks {
+		if h.ID == id {
+			s.hooks = append(s.hooks[:i], s.hooks[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNotFound
+}
+
+func (s *webhookStore) Store(h *v1.Webhook, isNew bool) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	cp := *h
+	if !isNew {
+		for i, h2 := range s.hooks {
+			if h2.ID == h.ID {
+				s.hooks[i] = &cp
+				return nil
+			}
+		}
+	}
+
+	s.hooks = append(s.hooks, &cp)
+	return nil
+}
+
+func (s *webhookStore) Find(id string) (*v1.Webhook, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, h := range s.hooks {
+		if h.ID == id {
+			cp := *h
+			return &cp, nil
+		}
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+func (s *webhookStore) FindAll() ([]*v1.Webhook, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Webhook, 0, len(s.hooks))
+	for _, h := range s.hooks {
+		cp := *h
+		result = append(result, &cp)
+	}
+
+	return result, nil
+}
+
+type deliveryStore struct {
+	m          sync.Mutex
+	deliveries []*v1.Delivery
+}
+
+func (s *deliveryStore) Append(d *v1.Delivery, keep int) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	cp := *d
+	s.deliveries = storage.TrimDeliveries(append(s.deliveries, &cp), d.Webhook, keep)
+	return nil
+}
+
+func (s *deliveryStore) FindMany(webhook string) ([]*v1.Delivery, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	result := make([]*v1.Delivery, 0)
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		if d := s.deliveries[i]; d.Webhook == webhook {
+			cp := *d
+			result = append(result, &cp)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *deliveryStore) DeleteAll(webhook string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	kept := s.deliveries[:0]
+	for _, d := range s.deliveries {
+		if d.Webhook != webhook {
+			kept = append(kept, d)
+		}
+	}
+
+	s.deliveries = kept
+	return nil
+}
@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type apiKeyStore struct {
+	db *mgo.Database
+}
+
+var _ storage.APIKeyStore = &apiKeyStore{}
+
+func (s *apiKeyStore) Delete(id string) error {
+	err := s.db.C(apiKeysCollection).Remove(bson.M{"id": id})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *apiKeyStore) Store(key *v1.APIKey, isNew bool) error {
+	keys := s.db.C(apiKeysCollection)
+	_, err := keys.Upsert(bson.M{"id": key.ID}, bson.M{"$set": key})
+	return err
+}
+
+func (s *apiKeyStore) Find(id string) (*v1.APIKey, error) {
+	key := &v1.APIKey{}
+	iter := s.db.C(apiKeysCollection).Find(bson.M{"id": id}).Iter()
+	if !iter.Next(key) {
+		return nil, storage.ErrNotFound
+	}
+
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *apiKeyStore) FindMany(f *storage.APIKeyFilters) ([]*v1.APIKey, error) {
+	q := bson.M{}
+	if f.User != "" {
+		q["user"] = f.User
+	}
+
+	keys := make([]*v1.APIKey, 0)
+	iter := s.db.C(apiKeysCollection).Find(q).Iter()
+	key := v1.APIKey{}
+	for iter.Next(&key) {
+		k := key
+		keys = append(keys, &k)
+	}
+
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
@@ -14,6 +14,15 @@ type claimStore struct {
 
 var _ storage.ClaimStore = &claimStore{}
 
+func (s *claimStore) Delete(code string) error {
+	err := s.db.C(claimsCollection).Remove(bson.M{"code": code})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
 func (s *claimStore) Store(c *v1.Claim, isNew bool) error {
 	claims := s.db.C(claimsCollection)
 	_, err := claims.Upsert(bson.M{"code": c.Code}, bson.M{"$set": c})
@@ -37,3 +46,60 @@ func (s *claimStore) Find(code string) (*v1.Claim, error) {
 
 	return c, nil
 }
+
+func (s *claimStore) FindAll() ([]*v1.Claim, error) {
+	claims := make([]*v1.Claim, 0)
+	if err := s.db.C(claimsCollection).Find(nil).Sort("created", "code").All(&claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (s *claimStore) Redeem(code string, now int64) error {
+	// a claim without max uses can be redeemed once, and one redeemed before
+	// uses were counted has been used once
+	uses := bson.M{"$cond": []interface{}{
+		bson.M{"$gt": []interface{}{"$uses", 0}},
+		"$uses",
+		bson.M{"$cond": []interface{}{bson.M{"$gt": []interface{}{"$redeemed", 0}}, 1, 0}},
+	}}
+
+	q := bson.M{
+		"code":    code,
+		"revoked": bson.M{"$in": []interface{}{0, nil}},
+		"$or": []bson.M{
+			{"expires": bson.M{"$in": []interface{}{0, nil}}},
+			{"expires": bson.M{"$gt": now}},
+		},
+		"$expr": bson.M{"$lt": []interface{}{uses, bson.M{"$max": []interface{}{"$maxuses", 1}}}},
+	}
+
+	err := s.db.C(claimsCollection).Update(q, bson.M{
+		"$inc": bson.M{"uses": 1},
+		"$set": bson.M{"redeemed": now},
+	})
+
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *claimStore) Release(code string) error {
+	claims := s.db.C(claimsCollection)
+
+	// the last use is given back along with the time it was redeemed, in a
+	// single update so a redemption in between can't be lost
+	err := claims.Update(bson.M{"code": code, "uses": 1}, bson.M{"$set": bson.M{"uses": 0, "redeemed": 0}})
+	if err == mgo.ErrNotFound {
+		err = claims.Update(bson.M{"code": code, "uses": bson.M{"$gt": 1}}, bson.M{"$inc": bson.M{"uses": -1}})
+	}
+
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
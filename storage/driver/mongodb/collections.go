@@ -0,0 +1,105 @@
+package mongodb
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type collectionStore struct {
+	db *mgo.Database
+}
+
+var _ storage.CollectionStore = &collectionStore{}
+
+func (s *collectionStore) Delete(name string) error {
+	err := s.db.C(collectionsCollection).Remove(bson.M{"name": name})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *collectionStore) Store(c *v1.Collection) error {
+	_, err := s.db.C(collectionsCollection).Upsert(bson.M{"name": c.Name}, bson.M{"$set": c})
+	return err
+}
+
+func (s *collectionStore) Find(name string) (*v1.Collection, error) {
+	c := &v1.Collection{}
+	err := s.db.C(collectionsCollection).Find(bson.M{"name": name}).One(c)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (s *collectionStore) FindAll() ([]*v1.Collection, error) {
+	collections := make([]*v1.Collection, 0)
+	if err := s.db.C(collectionsCollection).Find(nil).All(&collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+type itemStore struct {
+	db *mgo.Database
+}
+
+var _ storage.ItemStore = &itemStore{}
+
+func (s *itemStore) Delete(collection string, name string) error {
+	err := s.db.C(itemsCollection).Remove(bson.M{"collection": collection, "name": name})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *itemStore) DeleteAll(collection string) error {
+	_, err := s.db.C(itemsCollection).RemoveAll(bson.M{"collection": collection})
+	return err
+}
+
+func (s *itemStore) Store(i *v1.Item) error {
+	_, err := s.db.C(itemsCollection).Upsert(bson.M{"collection": i.Collection, "name": i.Name}, i)
+	return err
+}
+
+func (s *itemStore) Find(collection string, name string) (*v1.Item, error) {
+	i := &v1.Item{}
+	err := s.db.C(itemsCollection).Find(bson.M{"collection": collection, "name": name}).One(i)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (s *itemStore) FindMany(f *storage.ItemFilters) ([]*v1.Item, error) {
+	q := bson.M{}
+	if f.Collection != "" {
+		q["collection"] = f.Collection
+	}
+
+	if f.Published != nil {
+		q["publish"] = *f.Published
+	}
+
+	items := make([]*v1.Item, 0)
+	if err := s.db.C(itemsCollection).Find(q).Sort("collection", "name").All(&items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
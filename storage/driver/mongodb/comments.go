@@ -0,0 +1,94 @@
+package mongodb
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type commentStore struct {
+	db *mgo.Database
+}
+
+var _ storage.CommentStore = &commentStore{}
+
+func (s *commentStore) Store(c *v1.Comment) error {
+	_, err := s.db.C(commentsCollection).Upsert(bson.M{"id": c.ID}, bson.M{"$set": c})
+	return err
+}
+
+func (s *commentStore) Find(id string) (*v1.Comment, error) {
+	c := &v1.Comment{}
+	err := s.db.C(commentsCollection).Find(bson.M{"id": id}).One(c)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (s *commentStore) FindMany(f *storage.CommentFilters) ([]*v1.Comment, error) {
+	q := bson.M{}
+	if f.Post != "" {
+		q["post"] = f.Post
+	}
+
+	if f.State != "" {
+		q["state"] = f.State
+	}
+
+	comments := make([]*v1.Comment, 0)
+	if err := s.db.C(commentsCollection).Find(q).Sort("created", "id").All(&comments); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (s *commentStore) SetState(ids []string, state v1.CommentState) error {
+	q, err := s.existing(ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.C(commentsCollection).UpdateAll(q, bson.M{"$set": bson.M{"state": state}})
+	return err
+}
+
+func (s *commentStore) Delete(ids ...string) error {
+	q, err := s.existing(ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.C(commentsCollection).RemoveAll(q)
+	return err
+}
+
+func (s *commentStore) DeleteAll(postName string) error {
+	_, err := s.db.C(commentsCollection).RemoveAll(bson.M{"post": postName})
+	return err
+}
+
+// existing returns a query matching the ids, or storage.ErrNotFound if any
+// of them doesn't exist.
+func (s *commentStore) existing(ids []string) (bson.M, error) {
+	unique := make(map[string]bool)
+	for _, id := range ids {
+		unique[id] = true
+	}
+
+	q := bson.M{"id": bson.M{"$in": ids}}
+	n, err := s.db.C(commentsCollection).Find(q).Count()
+	if err != nil {
+		return nil, err
+	} else if n != len(unique) {
+		return nil, storage.ErrNotFound
+	}
+
+	return q, nil
+}
@@ -1,7 +1,59 @@
 package mongodb
 
-import "gopkg.in/mgo.v2/bson"
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/storage"
+)
 
 func nameQuery(name string) bson.M {
 	return bson.M{"name": name}
 }
+
+// versionQuery matches the named document only while it's still at the
+// version. Documents saved before they had one count as version 0.
+func versionQuery(name string, version int64) bson.M {
+	q := nameQuery(name)
+	if version == 0 {
+		q["version"] = bson.M{"$in": []interface{}{0, nil}}
+	} else {
+		q["version"] = version
+	}
+
+	return q
+}
+
+// storeVersioned saves doc as the named document. A new document is
+// inserted and gets ErrConflict if the name is taken. Otherwise the update is
+// conditional on the stored document still being at version, so when several
+// servers or requests change it at once just one of them wins and the rest
+// get ErrConflict. Updating a document that doesn't exist creates it, like an
+// upsert.
+func storeVersioned(c *mgo.Collection, name string, version int64, isNew bool, doc interface{}) error {
+	if isNew {
+		err := c.Insert(doc)
+		if mgo.IsDup(err) {
+			return storage.ErrConflict
+		}
+
+		return err
+	}
+
+	err := c.Update(versionQuery(name, version), bson.M{"$set": doc})
+	if err != mgo.ErrNotFound {
+		return err
+	}
+
+	if n, err := c.Find(nameQuery(name)).Count(); err != nil {
+		return err
+	} else if n > 0 {
+		return storage.ErrConflict
+	}
+
+	if err = c.Insert(doc); mgo.IsDup(err) {
+		return storage.ErrConflict
+	}
+
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/danielkrainas/gobag/decouple/drivers"
 	"gopkg.in/mgo.v2"
 
+	"github.com/danielkrainas/tinkersnest/search"
 	"github.com/danielkrainas/tinkersnest/storage"
 	"github.com/danielkrainas/tinkersnest/storage/driver/factory"
 )
@@ -14,6 +15,22 @@ const (
 	postsCollection  = "posts"
 	claimsCollection = "claims"
 	usersCollection  = "users"
+
+	sessionsCollection = "sessions"
+	apiKeysCollection  = "apikeys"
+
+	revisionsCollection = "revisions"
+
+	tagsCollection       = "tags"
+	categoriesCollection = "categories"
+
+	commentsCollection = "comments"
+
+	collectionsCollection = "collections"
+	itemsCollection       = "items"
+
+	webhooksCollection   = "webhooks"
+	deliveriesCollection = "deliveries"
 )
 
 type driverFactory struct{}
@@ -51,9 +68,22 @@ type driver struct {
 	session *mgo.Session
 	db      *mgo.Database
 
-	users  *userStore
-	posts  *postStore
-	claims *claimStore
+	users     *userStore
+	posts     *postStore
+	claims    *claimStore
+	sessions  *sessionStore
+	apiKeys   *apiKeyStore
+	revisions *revisionStore
+
+	tags       *tagStore
+	categories *categoryStore
+	comments   *commentStore
+
+	collections *collectionStore
+	items       *itemStore
+
+	webhooks   *webhookStore
+	deliveries *deliveryStore
 }
 
 var _ storage.Driver = &driver{}
@@ -62,6 +92,16 @@ func (d *driver) Init() error {
 	d.users = &userStore{d.db}
 	d.posts = &postStore{d.db}
 	d.claims = &claimStore{d.db}
+	d.sessions = &sessionStore{d.db}
+	d.apiKeys = &apiKeyStore{d.db}
+	d.revisions = &revisionStore{d.db}
+	d.tags = &tagStore{d.db}
+	d.categories = &categoryStore{d.db}
+	d.comments = &commentStore{d.db}
+	d.collections = &collectionStore{d.db}
+	d.items = &itemStore{d.db}
+	d.webhooks = &webhookStore{d.db}
+	d.deliveries = &deliveryStore{d.db}
 
 	nameIndex := mgo.Index{
 		Key:        []string{"name"},
@@ -72,6 +112,21 @@ func (d *driver) Init() error {
 	}
 
 	d.db.C(postsCollection).EnsureIndex(nameIndex)
+	d.db.C(postsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"state"},
+		Background: true,
+	})
+
+	d.db.C(postsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"$text:search.title", "$text:search.tags", "$text:search.body"},
+		Background: true,
+		Weights: map[string]int{
+			"search.title": search.TitleWeight,
+			"search.tags":  search.TagsWeight,
+			"search.body":  search.BodyWeight,
+		},
+	})
+
 	d.db.C(usersCollection).EnsureIndex(nameIndex)
 	d.db.C(claimsCollection).EnsureIndex(mgo.Index{
 		Key:        []string{"code"},
@@ -81,7 +136,81 @@ func (d *driver) Init() error {
 		Sparse:     false,
 	})
 
-	return nil
+	d.db.C(sessionsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"id"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     false,
+	})
+
+	d.db.C(sessionsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"user"},
+		Background: true,
+	})
+
+	d.db.C(apiKeysCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"id"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     false,
+	})
+
+	d.db.C(apiKeysCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"user"},
+		Background: true,
+	})
+
+	// the unique index is what keeps concurrent appends from reusing a number
+	d.db.C(revisionsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"post.name", "number"},
+		Unique:     true,
+		Background: true,
+	})
+
+	d.db.C(tagsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"slug"},
+		Unique:     true,
+		Background: true,
+	})
+
+	d.db.C(categoriesCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"slug"},
+		Unique:     true,
+		Background: true,
+	})
+
+	d.db.C(commentsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"id"},
+		Unique:     true,
+		Background: true,
+	})
+
+	d.db.C(commentsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"post", "created"},
+		Background: true,
+	})
+
+	d.db.C(collectionsCollection).EnsureIndex(nameIndex)
+	d.db.C(itemsCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"collection", "name"},
+		Unique:     true,
+		Background: true,
+	})
+
+	d.db.C(webhooksCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"id"},
+		Unique:     true,
+		Background: true,
+	})
+
+	d.db.C(deliveriesCollection).EnsureIndex(mgo.Index{
+		Key:        []string{"webhook"},
+		Background: true,
+	})
+
+	return d.posts.indexPosts()
 }
 
 func (d *driver) Users() storage.UserStore {
@@ -95,3 +224,43 @@ func (d *driver) Posts() storage.PostStore {
 func (d *driver) Claims() storage.ClaimStore {
 	return d.claims
 }
+
+func (d *driver) Sessions() storage.SessionStore {
+	return d.sessions
+}
+
+func (d *driver) APIKeys() storage.APIKeyStore {
+	return d.apiKeys
+}
+
+func (d *driver) Revisions() storage.RevisionStore {
+	return d.revisions
+}
+
+func (d *driver) Tags() storage.TagStore {
+	return d.tags
+}
+
+func (d *driver) Categories() storage.CategoryStore {
+	return d.categories
+}
+
+func (d *driver) Comments() storage.CommentStore {
+	return d.comments
+}
+
+func (d *driver) Collections() storage.CollectionStore {
+	return d.collections
+}
+
+func (d *driver) Items() storage.ItemStore {
+	return d.items
+}
+
+func (d *driver) Webhooks() storage.WebhookStore {
+	return d.webhooks
+}
+
+func (d *driver) Deliveries() storage.DeliveryStore {
+	return d.deliveries
+}
@@ -5,6 +5,7 @@ import (
 	"gopkg.in/mgo.v2/bson"
 
 	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/search"
 	"github.com/danielkrainas/tinkersnest/storage"
 )
 
@@ -14,14 +15,54 @@ type postStore struct {
 
 var _ storage.PostStore = &postStore{}
 
+// postDocument is a post as it's saved, along with the plain text the text
+// index is built from.
+type postDocument struct {
+	v1.Post `bson:",inline"`
+	Search  *search.Document `bson:"search"`
+}
+
+func newPostDocument(p *v1.Post) *postDocument {
+	return &postDocument{
+		Post:   *p,
+		Search: search.NewDocument(p),
+	}
+}
+
 func (s *postStore) Delete(name string) error {
 	return s.db.C(postsCollection).Remove(nameQuery(name))
 }
 
 func (s *postStore) Store(p *v1.Post, isNew bool) error {
+	doc := newPostDocument(p)
+	doc.Version++
+	if isNew {
+		doc.Version = 1
+	}
+
+	if err := storeVersioned(s.db.C(postsCollection), p.Name, p.Version, isNew, doc); err != nil {
+		return err
+	}
+
+	p.Version = doc.Version
+	return nil
+}
+
+// indexPosts fills in the search text of posts saved before there was one.
+func (s *postStore) indexPosts() error {
 	posts := s.db.C(postsCollection)
-	_, err := posts.Upsert(nameQuery(p.Name), bson.M{"$set": p})
-	return err
+	iter := posts.Find(bson.M{"search": bson.M{"$exists": false}}).Iter()
+	p := v1.Post{}
+	for iter.Next(&p) {
+		if err := posts.Update(nameQuery(p.Name), bson.M{"$set": bson.M{"search": search.NewDocument(&p)}}); err != nil {
+			iter.Close()
+			return err
+		}
+
+		p = v1.Post{}
+	}
+
+	return iter.Close()
 }
 
 func (s *postStore) Find(name string) (*v1.Post, error) {
@@ -43,8 +84,23 @@ func (s *postStore) Find(name string) (*v1.Post, error) {
 }
 
 func (s *postStore) FindMany(f *storage.PostFilters) ([]*v1.Post, error) {
+	query := s.db.C(postsCollection).Find(postFiltersQuery(f))
+	if f.Text != "" {
+		query = query.Select(bson.M{"score": bson.M{"$meta": "textScore"}, "search": 0}).
+			Sort("$textScore:score", "-created", "name")
+		if f.Cursor != nil {
+			query = query.Skip(f.Cursor.Offset)
+		}
+	} else {
+		query = query.Sort(postSortFields(f)...)
+	}
+
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+
 	posts := make([]*v1.Post, 0)
-	iter := s.db.C(postsCollection).Find(bson.M{}).Iter()
+	iter := query.Iter()
 	post := v1.Post{}
 	for iter.Next(&post) {
 		p := post
@@ -61,3 +117,105 @@ func (s *postStore) FindMany(f *storage.PostFilters) ([]*v1.Post, error) {
 
 	return posts, nil
 }
+
+func (s *postStore) FindDue(now int64) ([]*v1.Post, error) {
+	q := bson.M{"$or": []bson.M{
+		{"state": v1.PostScheduled, "publishat": bson.M{"$lte": now}},
+		{"state": v1.PostPublished, "unpublishat": bson.M{"$gt": 0, "$lte": now}},
+	}}
+
+	posts := make([]*v1.Post, 0)
+	if err := s.db.C(postsCollection).Find(q).All(&posts); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// Transition only matches the post while it's still at the version it was
+// found at, so when several servers share the database just one of them wins
+// and edits made in the meantime are kept.
+func (s *postStore) Transition(name string, version int64, to v1.PostState) error {
+	err := s.db.C(postsCollection).Update(versionQuery(name, version), bson.M{
+		"$set": bson.M{
+			"state":   to,
+			"publish": to == v1.PostPublished,
+		},
+		"$inc": bson.M{"version": 1},
+	})
+
+	if err == mgo.ErrNotFound {
+		if n, err := s.db.C(postsCollection).Find(nameQuery(name)).Count(); err != nil {
+			return err
+		} else if n == 0 {
+			return storage.ErrNotFound
+		}
+
+		return storage.ErrConflict
+	}
+
+	return err
+}
+
+func postSortFields(f *storage.PostFilters) []string {
+	order := f.SortOrder()
+	if order.Descending() {
+		return []string{"-" + order.Field(), "-name"}
+	}
+
+	return []string{order.Field(), "name"}
+}
+
+func postFiltersQuery(f *storage.PostFilters) bson.M {
+	q := bson.M{}
+	if f.Author != "" {
+		q["author.user"] = f.Author
+	}
+
+	if len(f.Tags) > 0 {
+		q["tags"] = bson.M{"$all": f.Tags}
+	}
+
+	if len(f.Categories) > 0 {
+		q["categories"] = bson.M{"$in": f.Categories}
+	}
+
+	if f.Published != nil {
+		q["publish"] = *f.Published
+	}
+
+	created := bson.M{}
+	if f.CreatedAfter > 0 {
+		created["$gt"] = f.CreatedAfter
+	}
+
+	if f.CreatedBefore > 0 {
+		created["$lt"] = f.CreatedBefore
+	}
+
+	if len(created) > 0 {
+		q["created"] = created
+	}
+
+	if f.Text != "" {
+		q["$text"] = bson.M{"$search": f.Text}
+	} else if f.Cursor != nil {
+		order := f.SortOrder()
+		op := "$gt"
+		if order.Descending() {
+			op = "$lt"
+		}
+
+		var key interface{} = f.Cursor.Created
+		if order.Field() == "title" {
+			key = f.Cursor.Title
+		}
+
+		q["$or"] = []bson.M{
+			{order.Field(): bson.M{op: key}},
+			{order.Field(): key, "name": bson.M{op: f.Cursor.Name}},
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+// appendRetries is how many times Append retries when another instance took
+// the same revision number first.
+const appendRetries = 5
+
+type revisionStore struct {
+	db *mgo.Database
+}
+
+var _ storage.RevisionStore = &revisionStore{}
+
+func (s *revisionStore) Append(r *v1.Revision) error {
+	revisions := s.db.C(revisionsCollection)
+	for attempt := 0; ; attempt++ {
+		last := &v1.Revision{}
+		err := revisions.Find(bson.M{"post.name": r.Post.Name}).Sort("-number").One(last)
+		if err == mgo.ErrNotFound {
+			last.Number = 0
+		} else if err != nil {
+			return err
+		}
+
+		r.Number = last.Number + 1
+		err = revisions.Insert(r)
+		if !mgo.IsDup(err) || attempt >= appendRetries {
+			return err
+		}
+	}
+}
+
+func (s *revisionStore) Find(postName string, number int) (*v1.Revision, error) {
+	r := &v1.Revision{}
+	err := s.db.C(revisionsCollection).Find(bson.M{"post.name": postName, "number": number}).One(r)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *revisionStore) FindMany(postName string) ([]*v1.Revision, error) {
+	revisions := make([]*v1.Revision, 0)
+	iter := s.db.C(revisionsCollection).Find(bson.M{"post.name": postName}).Sort("number").Iter()
+	revision := v1.Revision{}
+	for iter.Next(&revision) {
+		r := revision
+		revisions = append(revisions, &r)
+		revision = v1.Revision{}
+	}
+
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (s *revisionStore) Latest(postName string) (*v1.Revision, error) {
+	r := &v1.Revision{}
+	err := s.db.C(revisionsCollection).Find(bson.M{"post.name": postName}).Sort("-number").One(r)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *revisionStore) DeleteAll(postName string) error {
+	_, err := s.db.C(revisionsCollection).RemoveAll(bson.M{"post.name": postName})
+	return err
+}
@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type sessionStore struct {
+	db *mgo.Database
+}
+
+var _ storage.SessionStore = &sessionStore{}
+
+func (s *sessionStore) Delete(id string) error {
+	err := s.db.C(sessionsCollection).Remove(bson.M{"id": id})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *sessionStore) Store(session *v1.Session, isNew bool) error {
+	sessions := s.db.C(sessionsCollection)
+	_, err := sessions.Upsert(bson.M{"id": session.ID}, bson.M{"$set": session})
+	return err
+}
+
+func (s *sessionStore) Find(id string) (*v1.Session, error) {
+	session := &v1.Session{}
+	iter := s.db.C(sessionsCollection).Find(bson.M{"id": id}).Iter()
+	if !iter.Next(session) {
+		return nil, storage.ErrNotFound
+	}
+
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *sessionStore) FindMany(f *storage.SessionFilters) ([]*v1.Session, error) {
+	q := bson.M{}
+	if f.User != "" {
+		q["user"] = f.User
+	}
+
+	sessions := make([]*v1.Session, 0)
+	iter := s.db.C(sessionsCollection).Find(q).Iter()
+	session := v1.Session{}
+	for iter.Next(&session) {
+		cp := session
+		sessions = append(sessions, &cp)
+	}
+
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type tagStore struct {
+	db *mgo.Database
+}
+
+var _ storage.TagStore = &tagStore{}
+
+func (s *tagStore) Delete(slug string) error {
+	err := s.db.C(tagsCollection).Remove(bson.M{"slug": slug})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *tagStore) Store(t *v1.Tag) error {
+	_, err := s.db.C(tagsCollection).Upsert(bson.M{"slug": t.Slug}, bson.M{"$set": t})
+	return err
+}
+
+func (s *tagStore) Find(slug string) (*v1.Tag, error) {
+	t := &v1.Tag{}
+	err := s.db.C(tagsCollection).Find(bson.M{"slug": slug}).One(t)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *tagStore) FindAll() ([]*v1.Tag, error) {
+	tags := make([]*v1.Tag, 0)
+	if err := s.db.C(tagsCollection).Find(nil).All(&tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+type categoryStore struct {
+	db *mgo.Database
+}
+
+var _ storage.CategoryStore = &categoryStore{}
+
+func (s *categoryStore) Delete(slug string) error {
+	err := s.db.C(categoriesCollection).Remove(bson.M{"slug": slug})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *categoryStore) Store(c *v1.Category) error {
+	_, err := s.db.C(categoriesCollection).Upsert(bson.M{"slug": c.Slug}, bson.M{"$set": c})
+	return err
+}
+
+func (s *categoryStore) Find(slug string) (*v1.Category, error) {
+	c := &v1.Category{}
+	err := s.db.C(categoriesCollection).Find(bson.M{"slug": slug}).One(c)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (s *categoryStore) FindAll() ([]*v1.Category, error) {
+	categories := make([]*v1.Category, 0)
+	if err := s.db.C(categoriesCollection).Find(nil).All(&categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
@@ -19,9 +19,18 @@ func (s *userStore) Delete(name string) error {
 }
 
 func (s *userStore) Store(u *v1.User, isNew bool) error {
-	users := s.db.C(usersCollection)
-	_, err := users.Upsert(nameQuery(u.Name), bson.M{"$set": u})
-	return err
+	cp := *u
+	cp.Version++
+	if isNew {
+		cp.Version = 1
+	}
+
+	if err := storeVersioned(s.db.C(usersCollection), u.Name, u.Version, isNew, &cp); err != nil {
+		return err
+	}
+
+	u.Version = cp.Version
+	return nil
 }
 
 func (s *userStore) Find(name string) (*v1.User, error) {
@@ -44,7 +53,8 @@ func (s *userStore) Find(name string) (*v1.User, error) {
 
 func (s *userStore) FindMany(f *storage.UserFilters) ([]*v1.User, error) {
 	users := make([]*v1.User, 0)
-	iter := s.db.C(usersCollection).Find(bson.M{}).Iter()
+	// generated ids start with the time they were made at
+	iter := s.db.C(usersCollection).Find(bson.M{}).Sort("_id").Iter()
 	user := v1.User{}
 	for iter.Next(&user) {
 		u := user
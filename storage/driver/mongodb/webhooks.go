@@ -0,0 +1,101 @@
+package mongodb
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+type webhookStore struct {
+	db *mgo.Database
+}
+
+var _ storage.WebhookStore = &webhookStore{}
+
+func (s *webhookStore) Delete(id string) error {
+	err := s.db.C(webhooksCollection).Remove(bson.M{"id": id})
+	if err == mgo.ErrNotFound {
+		return storage.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *webhookStore) Store(h *v1.Webhook, isNew bool) error {
+	_, err := s.db.C(webhooksCollection).Upsert(bson.M{"id": h.ID}, h)
+	return err
+}
+
+func (s *webhookStore) Find(id string) (*v1.Webhook, error) {
+	h := &v1.Webhook{}
+	err := s.db.C(webhooksCollection).Find(bson.M{"id": id}).One(h)
+	if err == mgo.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (s *webhookStore) FindAll() ([]*v1.Webhook, error) {
+	hooks := make([]*v1.Webhook, 0)
+	if err := s.db.C(webhooksCollection).Find(nil).Sort("created").All(&hooks); err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+type deliveryStore struct {
+	db *mgo.Database
+}
+
+var _ storage.DeliveryStore = &deliveryStore{}
+
+// Append relies on the generated object ids to tell which deliveries are the
+// oldest.
+func (s *deliveryStore) Append(d *v1.Delivery, keep int) error {
+	deliveries := s.db.C(deliveriesCollection)
+	if err := deliveries.Insert(d); err != nil {
+		return err
+	}
+
+	var stale []struct {
+		ID bson.ObjectId `bson:"_id"`
+	}
+
+	err := deliveries.Find(bson.M{"webhook": d.Webhook}).
+		Sort("-_id").
+		Skip(keep).
+		Select(bson.M{"_id": 1}).
+		All(&stale)
+
+	if err != nil || len(stale) == 0 {
+		return err
+	}
+
+	ids := make([]bson.ObjectId, len(stale))
+	for i, doc := range stale {
+		ids[i] = doc.ID
+	}
+
+	_, err = deliveries.RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+func (s *deliveryStore) FindMany(webhook string) ([]*v1.Delivery, error) {
+	deliveries := make([]*v1.Delivery, 0)
+	if err := s.db.C(deliveriesCollection).Find(bson.M{"webhook": webhook}).Sort("-_id").All(&deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (s *deliveryStore) DeleteAll(webhook string) error {
+	_, err := s.db.C(deliveriesCollection).RemoveAll(bson.M{"webhook": webhook})
+	return err
+}
@@ -0,0 +1,273 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func ValidPostSort(s PostSort) bool {
+	switch s {
+	case SortCreatedDesc, SortCreatedAsc, SortTitleDesc, SortTitleAsc:
+		return true
+	}
+
+	return false
+}
+
+func (s PostSort) Field() string {
+	if s.Descending() {
+		return string(s[1:])
+	}
+
+	return string(s)
+}
+
+func (s PostSort) Descending() bool {
+	return len(s) > 0 && s[0] == '-'
+}
+
+// PostCursor marks the position of the last post on a page. It carries every
+// sortable field so the next page can be resumed for any sort order, with the
+// post name breaking ties between equal keys.
+type PostCursor struct {
+	Created int64  `json:"c"`
+	Title   string `json:"t"`
+	Name    string `json:"n"`
+
+	// Offset is how many results of a text search were already returned,
+	// since relevance can't be resumed from a key.
+	Offset int `json:"o,omitempty"`
+}
+
+func EncodePostCursor(p *v1.Post) string {
+	buf, _ := json.Marshal(&PostCursor{
+		Created: p.Created,
+		Title:   p.Title,
+		Name:    p.Name,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func EncodeSearchCursor(p *v1.Post, offset int) string {
+	buf, _ := json.Marshal(&PostCursor{
+		Created: p.Created,
+		Title:   p.Title,
+		Name:    p.Name,
+		Offset:  offset,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func DecodePostCursor(raw string) (*PostCursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &PostCursor{}
+	if err := json.Unmarshal(buf, c); err != nil || c.Name == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+func (f *PostFilters) SortOrder() PostSort {
+	if f.Sort == "" {
+		return SortCreatedDesc
+	}
+
+	return f.Sort
+}
+
+// Match reports whether the post satisfies every filter except the cursor
+// and limit, which only make sense against an ordered result set.
+func (f *PostFilters) Match(p *v1.Post) bool {
+	if f.Author != "" && (p.Author == nil || p.Author.User != f.Author) {
+		return false
+	}
+
+	if f.Published != nil && p.Publish != *f.Published {
+		return false
+	}
+
+	if f.CreatedAfter > 0 && p.Created <= f.CreatedAfter {
+		return false
+	}
+
+	if f.CreatedBefore > 0 && p.Created >= f.CreatedBefore {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !hasTag(p, tag) {
+			return false
+		}
+	}
+
+	if len(f.Categories) > 0 && !inCategory(p, f.Categories) {
+		return false
+	}
+
+	return true
+}
+
+func hasTag(p *v1.Post, tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inCategory(p *v1.Post, categories []string) bool {
+	for _, c := range p.Categories {
+		for _, want := range categories {
+			if c == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// comparePosts orders two posts by the sort field ascending, falling back to
+// the post name.
+func comparePosts(field string, a, b *PostCursor) int {
+	switch field {
+	case "title":
+		if a.Title < b.Title {
+			return -1
+		} else if a.Title > b.Title {
+			return 1
+		}
+
+	default:
+		if a.Created < b.Created {
+			return -1
+		} else if a.Created > b.Created {
+			return 1
+		}
+	}
+
+	if a.Name < b.Name {
+		return -1
+	} else if a.Name > b.Name {
+		return 1
+	}
+
+	return 0
+}
+
+func postKey(p *v1.Post) *PostCursor {
+	return &PostCursor{Created: p.Created, Title: p.Title, Name: p.Name}
+}
+
+// FilterPosts applies the filters to an unordered set of posts in memory. It
+// is meant for drivers that can't push filtering down into their backend.
+func FilterPosts(posts []*v1.Post, f *PostFilters) []*v1.Post {
+	order := f.SortOrder()
+	field := order.Field()
+	desc := order.Descending()
+
+	result := make([]*v1.Post, 0)
+	for _, p := range posts {
+		if !f.Match(p) {
+			continue
+		}
+
+		if f.Cursor != nil {
+			cmp := comparePosts(field, postKey(p), f.Cursor)
+			if (desc && cmp >= 0) || (!desc && cmp <= 0) {
+				continue
+			}
+		}
+
+		result = append(result, p)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		cmp := comparePosts(field, postKey(result[i]), postKey(result[j]))
+		if desc {
+			return cmp > 0
+		}
+
+		return cmp < 0
+	})
+
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[:f.Limit]
+	}
+
+	return result
+}
+
+// RankPosts applies the filters to the posts of a text search, ordering them
+// by their score with the best match first. Posts without a score didn't
+// match the search.
+func RankPosts(posts []*v1.Post, scores map[string]float64, f *PostFilters) []*v1.Post {
+	result := make([]*v1.Post, 0)
+	for _, p := range posts {
+		if _, ok := scores[p.Name]; ok && f.Match(p) {
+			result = append(result, p)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if scores[a.Name] != scores[b.Name] {
+			return scores[a.Name] > scores[b.Name]
+		}
+
+		if a.Created != b.Created {
+			return a.Created > b.Created
+		}
+
+		return a.Name < b.Name
+	})
+
+	if f.Cursor != nil {
+		if f.Cursor.Offset >= len(result) {
+			return result[:0]
+		}
+
+		result = result[f.Cursor.Offset:]
+	}
+
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[:f.Limit]
+	}
+
+	return result
+}
+
+// PostDue reports whether the scheduler has a transition to make on the post
+// at the given unix time.
+func PostDue(p *v1.Post, now int64) bool {
+	switch p.State {
+	case v1.PostScheduled:
+		return p.PublishAt <= now
+	case v1.PostPublished:
+		return p.UnpublishAt > 0 && p.UnpublishAt <= now
+	}
+
+	return false
+}
+
+// TransitionPost moves the post into the given state, keeping Publish in step
+// for clients that only look at the flag.
+func TransitionPost(p *v1.Post, to v1.PostState) {
+	p.State = to
+	p.Publish = to == v1.PostPublished
+}
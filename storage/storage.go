@@ -10,34 +10,201 @@ import (
 
 var ErrNotFound = errors.New("not found")
 
+// ErrConflict is returned when a conditional change loses to another writer.
+var ErrConflict = errors.New("conflicting change")
+
 type Driver interface {
 	drivers.DriverBase
 
 	Users() UserStore
 	Claims() ClaimStore
 	Posts() PostStore
+	Sessions() SessionStore
+	APIKeys() APIKeyStore
+	Revisions() RevisionStore
+	Tags() TagStore
+	Categories() CategoryStore
+	Comments() CommentStore
+	Collections() CollectionStore
+	Items() ItemStore
+	Webhooks() WebhookStore
+	Deliveries() DeliveryStore
 }
 
 type UserStore interface {
 	Delete(name string) error
+	// Store saves the user and increments its Version. An existing user must
+	// still be at the Version it was found at and a new one mustn't have the
+	// name of another, otherwise nothing is saved and ErrConflict is returned.
 	Store(u *v1.User, isNew bool) error
 	Find(name string) (*v1.User, error)
+	// FindMany lists users in the order they were created, or by name in
+	// stores that don't keep that order.
 	FindMany(f *UserFilters) ([]*v1.User, error)
 	Count(f *UserFilters) (int, error)
 }
 
 type ClaimStore interface {
+	Delete(code string) error
 	Find(code string) (*v1.Claim, error)
+	FindAll() ([]*v1.Claim, error)
 	Store(c *v1.Claim, isNew bool) error
+	// Redeem uses up one redemption of the claim as a single change, so two
+	// callers can't both take its last use. It returns ErrNotFound when there
+	// is no claim with the code that can still be redeemed at now.
+	Redeem(code string, now int64) error
+	// Release gives back one redemption of the claim, for when what it was
+	// redeemed for couldn't be created. It returns ErrNotFound when there is
+	// no claim with the code that has been redeemed.
+	Release(code string) error
+}
+
+type SessionStore interface {
+	Delete(id string) error
+	Store(s *v1.Session, isNew bool) error
+	Find(id string) (*v1.Session, error)
+	FindMany(f *SessionFilters) ([]*v1.Session, error)
+}
+
+type APIKeyStore interface {
+	Delete(id string) error
+	Store(k *v1.APIKey, isNew bool) error
+	Find(id string) (*v1.APIKey, error)
+	FindMany(f *APIKeyFilters) ([]*v1.APIKey, error)
+}
+
+// RevisionStore keeps the history of every post. Revisions are never changed
+// once appended.
+type RevisionStore interface {
+	// Append stores r as the next revision of its post and sets its Number.
+	Append(r *v1.Revision) error
+	Find(postName string, number int) (*v1.Revision, error)
+	// FindMany returns all revisions of a post, oldest first.
+	FindMany(postName string) ([]*v1.Revision, error)
+	// Latest returns the newest revision of a post.
+	Latest(postName string) (*v1.Revision, error)
+	DeleteAll(postName string) error
+}
+
+// TagStore keeps the titles and descriptions of tags. Which posts carry a
+// tag is only recorded on the posts.
+type TagStore interface {
+	Delete(slug string) error
+	Store(t *v1.Tag) error
+	Find(slug string) (*v1.Tag, error)
+	FindAll() ([]*v1.Tag, error)
+}
+
+type CategoryStore interface {
+	Delete(slug string) error
+	Store(c *v1.Category) error
+	Find(slug string) (*v1.Category, error)
+	FindAll() ([]*v1.Category, error)
+}
+
+type CommentStore interface {
+	Store(c *v1.Comment) error
+	Find(id string) (*v1.Comment, error)
+	// FindMany returns the matching comments, oldest first.
+	FindMany(f *CommentFilters) ([]*v1.Comment, error)
+	// SetState moves every comment with one of the ids into the state.
+	SetState(ids []string, state v1.CommentState) error
+	Delete(ids ...string) error
+	DeleteAll(postName string) error
+}
+
+// CollectionStore holds the collections defined through the API. Those from
+// the configuration never reach storage.
+type CollectionStore interface {
+	Delete(name string) error
+	Store(c *v1.Collection) error
+	Find(name string) (*v1.Collection, error)
+	FindAll() ([]*v1.Collection, error)
+}
+
+type ItemStore interface {
+	Delete(collection string, name string) error
+	// DeleteAll removes every item in the collection.
+	DeleteAll(collection string) error
+	Store(i *v1.Item) error
+	Find(collection string, name string) (*v1.Item, error)
+	// FindMany returns the matching items ordered by name.
+	FindMany(f *ItemFilters) ([]*v1.Item, error)
+}
+
+type WebhookStore interface {
+	Delete(id string) error
+	Store(h *v1.Webhook, isNew bool) error
+	Find(id string) (*v1.Webhook, error)
+	FindAll() ([]*v1.Webhook, error)
+}
+
+// DeliveryStore is the log of webhook deliveries.
+type DeliveryStore interface {
+	// Append records the delivery and drops the oldest ones of its webhook
+	// so that no more than keep remain.
+	Append(d *v1.Delivery, keep int) error
+	// FindMany returns the deliveries to a webhook, newest first.
+	FindMany(webhook string) ([]*v1.Delivery, error)
+	DeleteAll(webhook string) error
 }
 
 type PostStore interface {
 	Delete(name string) error
+	// Store saves the post and increments its Version. An existing post must
+	// still be at the Version it was found at and a new one mustn't have the
+	// name of another, otherwise nothing is saved and ErrConflict is returned.
 	Store(p *v1.Post, isNew bool) error
 	Find(name string) (*v1.Post, error)
 	FindMany(f *PostFilters) ([]*v1.Post, error)
+
+	// FindDue returns the scheduled posts whose publish time has come and
+	// the published posts whose unpublish time has.
+	FindDue(now int64) ([]*v1.Post, error)
+	// Transition atomically moves a post to another state, incrementing its
+	// Version, and returns ErrConflict if the post has changed since it was
+	// found at version.
+	Transition(name string, version int64, to v1.PostState) error
 }
 
-type PostFilters struct{}
+type PostSort string
+
+var (
+	SortCreatedDesc PostSort = "-created"
+	SortCreatedAsc  PostSort = "created"
+	SortTitleDesc   PostSort = "-title"
+	SortTitleAsc    PostSort = "title"
+)
+
+type PostFilters struct {
+	Author        string
+	Tags          []string
+	Published     *bool
+	CreatedAfter  int64
+	CreatedBefore int64
+	Sort          PostSort
+	Limit         int
+	Cursor        *PostCursor
+
+	// Categories matches posts filed under any of the categories.
+	Categories []string
+
+	// Text only matches posts containing at least one of its words and
+	// orders them by relevance instead of Sort.
+	Text string
+}
+
+type PostPage struct {
+	Posts []*v1.Post
+	Next  string
+}
 
 type UserFilters struct{}
+
+type SessionFilters struct {
+	User string
+}
+
+type APIKeyFilters struct {
+	User string
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// ReplaceTerm swaps one tag or category slug for another in a post's list,
+// dropping it when to is empty and never listing a slug twice.
+func ReplaceTerm(terms []string, from string, to string) []string {
+	result := make([]string, 0, len(terms))
+	seen := make(map[string]bool)
+	for _, t := range terms {
+		if t == from {
+			t = to
+		}
+
+		if t == "" || seen[t] {
+			continue
+		}
+
+		seen[t] = true
+		result = append(result, t)
+	}
+
+	return result
+}
+
+// Subcategories returns the slug along with the slugs of every category
+// below it.
+func Subcategories(categories []*v1.Category, slug string) []string {
+	children := make(map[string][]string)
+	for _, c := range categories {
+		children[c.Parent] = append(children[c.Parent], c.Slug)
+	}
+
+	result := []string{slug}
+	seen := map[string]bool{slug: true}
+	for i := 0; i < len(result); i++ {
+		for _, child := range children[result[i]] {
+			if !seen[child] {
+				seen[child] = true
+				result = append(result, child)
+			}
+		}
+	}
+
+	return result
+}
+
+// CountTerms counts the posts carrying each tag and filed under each
+// category. A post counts towards the ancestors of its categories as well.
+func CountTerms(posts []*v1.Post, categories []*v1.Category) (tags map[string]int, cats map[string]int) {
+	parents := make(map[string]string)
+	for _, c := range categories {
+		parents[c.Slug] = c.Parent
+	}
+
+	tags = make(map[string]int)
+	cats = make(map[string]int)
+	for _, p := range posts {
+		seen := make(map[string]bool)
+		for _, t := range p.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags[t]++
+			}
+		}
+
+		seen = make(map[string]bool)
+		for _, c := range p.Categories {
+			for ; c != "" && !seen[c]; c = parents[c] {
+				seen[c] = true
+				cats[c]++
+			}
+		}
+	}
+
+	return tags, cats
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// SortWebhooks orders webhooks by when they were created.
+func SortWebhooks(hooks []*v1.Webhook) {
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].Created < hooks[j].Created
+	})
+}
+
+// TrimDeliveries drops the oldest deliveries to the webhook until no more
+// than keep remain. The log must be ordered oldest first.
+func TrimDeliveries(log []*v1.Delivery, webhook string, keep int) []*v1.Delivery {
+	count := 0
+	for _, d := range log {
+		if d.Webhook == webhook {
+			count++
+		}
+	}
+
+	kept := make([]*v1.Delivery, 0, len(log))
+	for _, d := range log {
+		if d.Webhook == webhook && count > keep {
+			count--
+			continue
+		}
+
+		kept = append(kept, d)
+	}
+
+	return kept
+}
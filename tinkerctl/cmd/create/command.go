@@ -5,12 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"net/http"
 
 	"github.com/danielkrainas/gobag/cmd"
 
-	"github.com/danielkrainas/tinkersnest/api/client"
 	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/tinkerctl/local"
 	"github.com/danielkrainas/tinkersnest/tinkerctl/resource"
 )
 
@@ -32,7 +31,10 @@ func run(ctx context.Context, args []string) error {
 
 	const ENDPOINT = "http://localhost:9240"
 
-	c := client.New(ENDPOINT, http.DefaultClient)
+	c, err := local.NewClient(ENDPOINT)
+	if err != nil {
+		return err
+	}
 
 	switch res.Type {
 	case resource.Post:
@@ -65,6 +67,18 @@ func run(ctx context.Context, args []string) error {
 
 		fmt.Printf("user %q was created!\n", res.Name)
 
+	case resource.Item:
+		item, err := itemFromSpec(res.Name, res.Spec)
+		if err != nil {
+			return err
+		}
+
+		if item, err = c.Collections().CreateItem(item); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s item %q was created!\n", item.Collection, res.Name)
+
 	default:
 		return fmt.Errorf("resource type %q unsupported", res.Type)
 	}
@@ -108,6 +122,14 @@ func userFromSpec(name string, spec map[string]interface{}) (*v1.User, error) {
 		Password: m["password"].(string),
 	}
 
+	if roles, ok := m["roles"].([]interface{}); ok {
+		for _, r := range roles {
+			if role, ok := r.(string); ok {
+				u.Roles = append(u.Roles, v1.Role(role))
+			}
+		}
+	}
+
 	return u, nil
 }
 
@@ -160,6 +182,56 @@ func postFromSpec(name string, spec map[string]interface{}) (*v1.Post, error) {
 	return p, nil
 }
 
+func itemFromSpec(name string, spec map[string]interface{}) (*v1.Item, error) {
+	m, ok := spec["item"].(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("missing 'item' data in spec")
+	}
+
+	i := &v1.Item{
+		Name:    name,
+		Content: make([]*v1.Content, 0),
+	}
+
+	if i.Collection, ok = m["collection"].(string); !ok {
+		return nil, errors.New("missing 'collection' in item spec")
+	}
+
+	if title, ok := m["title"].(string); ok {
+		i.Title = title
+	}
+
+	if publish, ok := m["publish"].(bool); ok {
+		i.Publish = publish
+	}
+
+	if fields, ok := m["fields"].(map[interface{}]interface{}); ok {
+		i.Fields = make(map[string]interface{}, len(fields))
+		for k, v := range fields {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid item field name %v", k)
+			}
+
+			i.Fields[key] = v
+		}
+	}
+
+	contents, _ := m["content"].([]interface{})
+	for _, c := range contents {
+		if cm, ok := c.(map[interface{}]interface{}); ok {
+			c, err := getContent(cm)
+			if err != nil {
+				return nil, err
+			}
+
+			i.Content = append(i.Content, c)
+		}
+	}
+
+	return i, nil
+}
+
 func getContent(spec map[interface{}]interface{}) (*v1.Content, error) {
 	c := &v1.Content{}
 	if t, ok := spec["type"].(string); !ok {
@@ -177,9 +249,11 @@ func getContent(spec map[interface{}]interface{}) (*v1.Content, error) {
 		}
 
 		c.Data = data
+	} else if blob, ok := spec["blob"].(string); ok {
+		c.Blob = blob
 	}
 
-	if c.Data == nil {
+	if c.Data == nil && c.Blob == "" {
 		return nil, errors.New("content does not have any data associated")
 	}
 
@@ -4,11 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"strings"
 
 	"github.com/danielkrainas/gobag/cmd"
 
-	"github.com/danielkrainas/tinkersnest/api/client"
+	"github.com/danielkrainas/tinkersnest/tinkerctl/local"
 )
 
 func init() {
@@ -24,7 +24,10 @@ func run(ctx context.Context, args []string) error {
 
 	const ENDPOINT = "http://localhost:9240"
 
-	c := client.New(ENDPOINT, http.DefaultClient)
+	c, err := local.NewClient(ENDPOINT)
+	if err != nil {
+		return err
+	}
 
 	name := args[1]
 	switch args[0] {
@@ -40,6 +43,17 @@ func run(ctx context.Context, args []string) error {
 			return err
 		}
 
+	case "item":
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			return errors.New("items are named <collection>/<name>")
+		}
+
+		err := c.Collections().DeleteItem(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+
 	default:
 		return fmt.Errorf("resource type %q unsupported", args[0])
 	}
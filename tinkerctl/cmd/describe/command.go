@@ -4,12 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
 
 	"github.com/danielkrainas/gobag/cmd"
 
-	"github.com/danielkrainas/tinkersnest/api/client"
 	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/tinkerctl/local"
 )
 
 func init() {
@@ -25,7 +24,10 @@ func run(ctx context.Context, args []string) error {
 
 	const ENDPOINT = "http://localhost:9240"
 
-	c := client.New(ENDPOINT, http.DefaultClient)
+	c, err := local.NewClient(ENDPOINT)
+	if err != nil {
+		return err
+	}
 
 	name := args[1]
 	switch args[0] {
@@ -4,11 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"time"
 
 	"github.com/danielkrainas/gobag/cmd"
 
-	"github.com/danielkrainas/tinkersnest/api/client"
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/tinkerctl/local"
 )
 
 func init() {
@@ -22,7 +23,10 @@ func run(ctx context.Context, args []string) error {
 
 	const ENDPOINT = "http://localhost:9240"
 
-	c := client.New(ENDPOINT, http.DefaultClient)
+	c, err := local.NewClient(ENDPOINT)
+	if err != nil {
+		return err
+	}
 
 	switch args[0] {
 	case "users":
@@ -44,9 +48,65 @@ func run(ctx context.Context, args []string) error {
 			return err
 		}
 
-		fmt.Printf("%10s | %-20s \n", "PUBLISHED", "NAME")
+		fmt.Printf("%10s | %-10s | %-20s | %-20s \n", "PUBLISHED", "STATE", "SCHEDULED", "NAME")
 		for _, post := range posts {
-			fmt.Printf("%10s | %-20s \n", yesNoBool(post.Publish), post.Name)
+			fmt.Printf("%10s | %-10s | %-20s | %-20s \n", yesNoBool(post.Publish), post.State, scheduledTime(post), post.Name)
+		}
+
+		fmt.Println("")
+
+	case "tags":
+		tags, err := c.Taxonomy().SearchTags()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-20s | %-20s | %6s\n", "SLUG", "TITLE", "POSTS")
+		for _, tag := range tags {
+			fmt.Printf("%-20s | %-20s | %6d\n", tag.Slug, tag.Title, tag.Count)
+		}
+
+		fmt.Println("")
+
+	case "categories":
+		categories, err := c.Taxonomy().SearchCategories()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-20s | %-20s | %-20s | %6s\n", "SLUG", "TITLE", "PARENT", "POSTS")
+		for _, category := range categories {
+			fmt.Printf("%-20s | %-20s | %-20s | %6d\n", category.Slug, category.Title, category.Parent, category.Count)
+		}
+
+		fmt.Println("")
+
+	case "collections":
+		collections, err := c.Collections().SearchCollections()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-20s | %-20s | %6s | %10s\n", "NAME", "TITLE", "FIELDS", "CONFIGURED")
+		for _, collection := range collections {
+			fmt.Printf("%-20s | %-20s | %6d | %10s\n", collection.Name, collection.Title, len(collection.Fields), yesNoBool(collection.Configured))
+		}
+
+		fmt.Println("")
+
+	case "items":
+		if len(args) < 2 || args[1] == "" {
+			return errors.New("you must specify a collection")
+		}
+
+		items, err := c.Collections().SearchItems(args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%10s | %-20s | %-30s\n", "PUBLISHED", "NAME", "TITLE")
+		for _, item := range items {
+			fmt.Printf("%10s | %-20s | %-30s\n", yesNoBool(item.Publish), item.Name, item.Title)
 		}
 
 		fmt.Println("")
@@ -60,7 +120,7 @@ func run(ctx context.Context, args []string) error {
 
 var (
 	Info = &cmd.Info{
-		Use:   "get <resource_type>",
+		Use:   "get <resource_type> [collection]",
 		Short: "list a type of resources on the server",
 		Long:  "list a type of resources on the server",
 		Run:   cmd.ExecutorFunc(run),
@@ -74,3 +134,19 @@ func yesNoBool(b bool) string {
 
 	return "no"
 }
+
+// scheduledTime shows the next scheduled change to the post, if any.
+func scheduledTime(p *v1.Post) string {
+	switch {
+	case p.State == v1.PostScheduled && p.PublishAt > 0:
+		return formatTime(p.PublishAt)
+	case p.State == v1.PostPublished && p.UnpublishAt > 0:
+		return "until " + formatTime(p.UnpublishAt)
+	}
+
+	return "-"
+}
+
+func formatTime(unix int64) string {
+	return time.Unix(unix, 0).Format("2006-01-02 15:04 MST")
+}
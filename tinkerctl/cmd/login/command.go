@@ -55,16 +55,18 @@ func run(ctx context.Context, args []string) error {
 	username = strings.TrimSpace(username)
 	passwordStr := strings.TrimSpace(string(password))
 
-	token, err := c.Auth().Login(username, passwordStr)
+	tokens, err := c.Auth().Login(username, passwordStr)
 	if err != nil {
 		return err
 	}
 
-	config.Set(&local.HostConfig{
+	hc := &local.HostConfig{
 		Host:     endpoint,
 		Username: username,
-		Token:    token,
-	})
+	}
+
+	hc.SetTokens(tokens)
+	config.Set(hc)
 
 	if err = local.SaveAuthConfig(config); err != nil {
 		return err
@@ -16,9 +16,11 @@ const (
 )
 
 type HostConfig struct {
-	Host     string           `json:"-"`
-	Username string           `json:"name"`
-	Token    client.AuthToken `json:"token"`
+	Host         string           `json:"-"`
+	Username     string           `json:"name"`
+	Token        client.AuthToken `json:"token"`
+	RefreshToken string           `json:"refresh_token,omitempty"`
+	Expires      int64            `json:"expires,omitempty"`
 }
 
 type AuthConfig map[string]*HostConfig
@@ -75,7 +77,13 @@ func SaveAuthConfig(config AuthConfig) error {
 		return err
 	}
 
-	if err = ioutil.WriteFile(authConfigPath, buf, 0644); err != nil {
+	// the file holds tokens, and WriteFile keeps the mode of an existing file,
+	// so one left readable by others is locked down before it's written
+	if err = os.Chmod(authConfigPath, 0600); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err = ioutil.WriteFile(authConfigPath, buf, 0600); err != nil {
 		return err
 	}
 
@@ -0,0 +1,63 @@
+package local
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/danielkrainas/tinkersnest/api/client"
+	"github.com/danielkrainas/tinkersnest/api/v1"
+)
+
+// APIKeyEnv is the environment variable an API key can be given in, for
+// pipelines that can't run `tinkerctl login`. It takes precedence over a
+// stored login.
+const APIKeyEnv = "TINKERCTL_API_KEY"
+
+// refreshMargin is how long before the access token expires that it is
+// refreshed, so it doesn't run out in the middle of a command.
+const refreshMargin = time.Minute
+
+// NewClient returns a client for the host that is authenticated with the API
+// key from the environment or the stored login, if there is one. An access
+// token that has expired or is about to is refreshed first and the new tokens
+// are saved.
+func NewClient(endpoint string) (*client.Client, error) {
+	c := client.New(endpoint, http.DefaultClient)
+	if key := os.Getenv(APIKeyEnv); key != "" {
+		c.AuthToken = client.AuthToken(key)
+		return c, nil
+	}
+
+	config, err := LoadAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	hc := config.Get(endpoint)
+	if hc == nil {
+		return c, nil
+	}
+
+	if hc.RefreshToken != "" && time.Now().Add(refreshMargin).Unix() >= hc.Expires {
+		tokens, err := c.Auth().Refresh(hc.RefreshToken)
+		if err != nil {
+			return nil, fmt.Errorf("error refreshing session, run `tinkerctl login %s` again: %v", endpoint, err)
+		}
+
+		hc.SetTokens(tokens)
+		if err := SaveAuthConfig(config); err != nil {
+			return nil, err
+		}
+	}
+
+	c.AuthToken = hc.Token
+	return c, nil
+}
+
+func (hc *HostConfig) SetTokens(tokens *v1.AuthTokens) {
+	hc.Token = client.AuthToken(tokens.AccessToken)
+	hc.RefreshToken = tokens.RefreshToken
+	hc.Expires = time.Now().Unix() + tokens.ExpiresIn
+}
@@ -16,6 +16,8 @@ type ResourceType string
 var (
 	Post ResourceType = "Post"
 	User ResourceType = "User"
+	// Item is an entry in a content collection such as pages.
+	Item ResourceType = "Item"
 )
 
 func Load(resourcePath string) (*Resource, error) {
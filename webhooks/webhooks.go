@@ -0,0 +1,270 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+	"github.com/satori/go.uuid"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/configuration"
+	"github.com/danielkrainas/tinkersnest/events"
+	"github.com/danielkrainas/tinkersnest/queries"
+	"github.com/danielkrainas/tinkersnest/storage"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	DefaultRetries = 5
+	DefaultBackoff = time.Second
+
+	// LogSize is how many deliveries the log of each webhook keeps.
+	LogSize = 100
+
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the request
+	// body, keyed with the webhook's secret and prefixed with "sha256=".
+	SignatureHeader = "X-Tinkersnest-Signature"
+	EventHeader     = "X-Tinkersnest-Event"
+	// DeliveryHeader stays the same across retries, so receivers can tell
+	// when they have seen a delivery before.
+	DeliveryHeader = "X-Tinkersnest-Delivery"
+
+	SECRET_SIZE = 32
+
+	eventBuffer = 256
+)
+
+// Dispatcher sends the events published on the bus to the webhooks that
+// subscribe to them. Failed deliveries are retried with exponential backoff
+// and every attempt is recorded in the webhook's delivery log.
+type Dispatcher struct {
+	bus      *events.Bus
+	client   *http.Client
+	retries  int
+	backoff  time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func New(config *configuration.Config, bus *events.Bus) *Dispatcher {
+	timeout := config.Webhooks.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	retries := config.Webhooks.Retries
+	if retries <= 0 {
+		retries = DefaultRetries
+	}
+
+	backoff := config.Webhooks.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	return &Dispatcher{
+		bus:     bus,
+		client:  &http.Client{Timeout: timeout},
+		retries: retries,
+		backoff: backoff,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start delivers events in the background until Stop is called or the
+// context is done. Deliveries still waiting to be retried are abandoned.
+// Events that were published while the dispatcher was too far behind are
+// still delivered if the bus kept them.
+func (d *Dispatcher) Start(ctx context.Context) {
+	sub := d.bus.Subscribe(eventBuffer)
+	acontext.GetLogger(ctx).Infof("webhook dispatcher running with %d retries", d.retries)
+	go d.loop(ctx, sub)
+}
+
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+}
+
+func (d *Dispatcher) loop(ctx context.Context, sub *events.Subscription) {
+	defer func() {
+		sub.Close()
+	}()
+
+	// no event has this id, so falling behind before the first one replays
+	// everything the bus kept
+	last := "start"
+	for {
+		select {
+		case e, ok := <-sub.C:
+			var missed []*v1.Event
+			if !ok {
+				// the bus dropped us for falling behind
+				acontext.GetLogger(ctx).Warn("webhook dispatcher fell behind, catching up")
+				sub, missed = d.bus.SubscribeSince(last, eventBuffer)
+			} else {
+				missed = []*v1.Event{e}
+			}
+
+			for _, e := range missed {
+				if err := d.dispatch(ctx, e); err != nil {
+					acontext.GetLogger(ctx).Errorf("error dispatching %s event: %v", e.Type, err)
+				}
+
+				last = e.ID
+			}
+
+		case <-d.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, e *v1.Event) error {
+	hooksRaw, err := cqrs.DispatchQuery(ctx, &queries.SearchWebhooks{})
+	if err != nil {
+		return err
+	}
+
+	hooks, ok := hooksRaw.([]*v1.Webhook)
+	if !ok {
+		return fmt.Errorf("couldn't convert raw value (%#+v) to webhooks", hooksRaw)
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hooks {
+		if h.Wants(e.Type) {
+			go d.deliver(ctx, h, e, body)
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, h *v1.Webhook, e *v1.Event, body []byte) {
+	id := uuid.NewV4().String()
+	log := acontext.GetLoggerWithFields(ctx, map[interface{}]interface{}{
+		"webhook.id":  h.ID,
+		"delivery.id": id,
+	})
+
+	for attempt := 1; ; attempt++ {
+		delivery := d.send(h, e, id, attempt, body)
+		err := cqrs.DispatchCommand(ctx, &commands.AppendDelivery{
+			Delivery: delivery,
+			Keep:     LogSize,
+		})
+
+		if err != nil {
+			log.Errorf("error recording delivery: %v", err)
+		}
+
+		if delivery.Succeeded() {
+			log.Infof("%s event delivered to %s", e.Type, h.URL)
+			return
+		} else if attempt > d.retries {
+			log.Errorf("giving up on delivering %s event to %s after %d attempts", e.Type, h.URL, attempt)
+			return
+		}
+
+		wait := d.backoff << uint(attempt-1)
+		log.Warnf("delivering %s event to %s failed, retrying in %v", e.Type, h.URL, wait)
+		select {
+		case <-time.After(wait):
+		case <-d.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		// pick up changes to the webhook and stop if it was deleted
+		hookRaw, err := cqrs.DispatchQuery(ctx, &queries.FindWebhook{ID: h.ID})
+		if err == storage.ErrNotFound {
+			log.Infof("webhook deleted, abandoning %s event", e.Type)
+			return
+		} else if err != nil {
+			log.Errorf("error finding webhook: %v", err)
+		} else if current, ok := hookRaw.(*v1.Webhook); ok {
+			h = current
+		}
+	}
+}
+
+func (d *Dispatcher) send(h *v1.Webhook, e *v1.Event, id string, attempt int, body []byte) *v1.Delivery {
+	delivery := &v1.Delivery{
+		ID:      id,
+		Webhook: h.ID,
+		Event:   e.ID,
+		Type:    e.Type,
+		Attempt: attempt,
+		Created: time.Now().Unix(),
+	}
+
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("User-Agent", "tinkersnest-webhooks")
+		req.Header.Set(EventHeader, string(e.Type))
+		req.Header.Set(DeliveryHeader, id)
+		req.Header.Set(SignatureHeader, Sign(h.Secret, body))
+
+		var resp *http.Response
+		if resp, err = d.client.Do(req); err == nil {
+			// drain some of the body so the connection can be reused
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+			delivery.Status = resp.StatusCode
+		}
+	}
+
+	delivery.Duration = int64(time.Since(start) / time.Millisecond)
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	return delivery
+}
+
+// Sign returns the value of the SignatureHeader for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature was made from the body with the
+// secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func GenerateSecret() (string, error) {
+	secret := make([]byte, SECRET_SIZE)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
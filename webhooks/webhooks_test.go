@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielkrainas/gobag/context"
+	"github.com/danielkrainas/gobag/decouple/cqrs"
+
+	"github.com/danielkrainas/tinkersnest/api/v1"
+	"github.com/danielkrainas/tinkersnest/commands"
+	"github.com/danielkrainas/tinkersnest/configuration"
+	"github.com/danielkrainas/tinkersnest/events"
+	"github.com/danielkrainas/tinkersnest/queries"
+)
+
+const testSecret = "secret"
+
+type received struct {
+	body   []byte
+	header http.Header
+}
+
+// receiver is a webhook endpoint that answers with the given statuses in
+// turn, then with 200 OK.
+type receiver struct {
+	m        sync.Mutex
+	statuses []int
+	requests []*received
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	rc.m.Lock()
+	defer rc.m.Unlock()
+	rc.requests = append(rc.requests, &received{body: body, header: r.Header})
+	if len(rc.statuses) > 0 {
+		w.WriteHeader(rc.statuses[0])
+		rc.statuses = rc.statuses[1:]
+	}
+}
+
+// startDispatcher runs a dispatcher for the webhook and returns the bus to
+// publish on and the delivery log as it is appended to.
+func startDispatcher(t *testing.T, hook *v1.Webhook, retries int) (*events.Bus, <-chan *v1.Delivery, func()) {
+	deliveries := make(chan *v1.Delivery, 10)
+	cmds := cqrs.CommandRouter{}
+	cmds.Register(&commands.AppendDelivery{}, cqrs.CommandFunc(func(ctx context.Context, cmd cqrs.Command) error {
+		deliveries <- cmd.(*commands.AppendDelivery).Delivery
+		return nil
+	}))
+
+	ctx := cqrs.WithCommandDispatch(acontext.Background(), &cqrs.CommandDispatcher{
+		Handlers: []cqrs.CommandHandler{cmds},
+	})
+
+	ctx = cqrs.WithQueryDispatch(ctx, &cqrs.QueryDispatcher{
+		Executors: []cqrs.QueryExecutor{hookExecutor{hook}},
+	})
+
+	config := &configuration.Config{}
+	config.Webhooks.Retries = retries
+	config.Webhooks.Backoff = time.Millisecond
+
+	bus := events.NewBus(0)
+	d := New(config, bus)
+	d.Start(ctx)
+	return bus, deliveries, d.Stop
+}
+
+type hookExecutor struct {
+	hook *v1.Webhook
+}
+
+func (e hookExecutor) Execute(ctx context.Context, q cqrs.Query) (interface{}, error) {
+	switch q.(type) {
+	case *queries.SearchWebhooks:
+		return []*v1.Webhook{e.hook}, nil
+	case *queries.FindWebhook:
+		return e.hook, nil
+	}
+
+	return nil, cqrs.ErrNoExecutor
+}
+
+func nextDelivery(t *testing.T, deliveries <-chan *v1.Delivery) *v1.Delivery {
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+		return nil
+	}
+}
+
+func TestDeliveryRetriedAfterServerError(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusInternalServerError}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	hook := &v1.Webhook{ID: "hook", URL: srv.URL, Secret: testSecret}
+	bus, deliveries, stop := startDispatcher(t, hook, 2)
+	defer stop()
+
+	e := &v1.Event{
+		ID:       "1",
+		Type:     v1.EventPostPublished,
+		Resource: "hello-world",
+		Created:  1000,
+		Data:     map[string]string{"title": "Hello World"},
+	}
+
+	bus.Publish(e)
+	first := nextDelivery(t, deliveries)
+	second := nextDelivery(t, deliveries)
+	if first.Attempt != 1 || first.Status != http.StatusInternalServerError || first.Succeeded() {
+		t.Errorf("first delivery = %+v, want a failed first attempt", first)
+	}
+
+	if second.Attempt != 2 || second.Status != http.StatusOK || !second.Succeeded() {
+		t.Errorf("second delivery = %+v, want a successful second attempt", second)
+	}
+
+	if first.ID != second.ID || first.Webhook != "hook" || first.Event != "1" || first.Type != v1.EventPostPublished {
+		t.Errorf("deliveries %+v and %+v aren't both of the event to the webhook", first, second)
+	}
+
+	rc.m.Lock()
+	defer rc.m.Unlock()
+	if len(rc.requests) != 2 {
+		t.Fatalf("webhook received %d requests, want 2", len(rc.requests))
+	}
+
+	expected, _ := json.Marshal(e)
+	for i, r := range rc.requests {
+		if string(r.body) != string(expected) {
+			t.Errorf("request %d body = %s, want %s", i, r.body, expected)
+		}
+
+		if sig := r.header.Get(SignatureHeader); sig != Sign(testSecret, expected) || !Verify(testSecret, r.body, sig) {
+			t.Errorf("request %d has signature %q", i, sig)
+		} else if Verify("other", r.body, sig) {
+			t.Errorf("request %d signature verifies with the wrong secret", i)
+		}
+
+		if got := r.header.Get(EventHeader); got != string(v1.EventPostPublished) {
+			t.Errorf("request %d %s = %q", i, EventHeader, got)
+		} else if got := r.header.Get(DeliveryHeader); got != first.ID {
+			t.Errorf("request %d %s = %q, want %q", i, DeliveryHeader, got, first.ID)
+		}
+	}
+}
+
+func TestDeliveryGivesUp(t *testing.T) {
+	rc := &receiver{statuses: []int{502, 503, 504, 500}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	hook := &v1.Webhook{ID: "hook", URL: srv.URL, Secret: testSecret}
+	bus, deliveries, stop := startDispatcher(t, hook, 2)
+	defer stop()
+
+	bus.Publish(&v1.Event{ID: "1", Type: v1.EventUserCreated, Resource: "bob"})
+	for attempt := 1; attempt <= 3; attempt++ {
+		if d := nextDelivery(t, deliveries); d.Attempt != attempt || d.Succeeded() {
+			t.Errorf("delivery = %+v, want failed attempt %d", d, attempt)
+		}
+	}
+
+	select {
+	case d := <-deliveries:
+		t.Errorf("delivery attempted again after the last retry: %+v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUnwantedEventNotDelivered(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	hook := &v1.Webhook{ID: "hook", URL: srv.URL, Secret: testSecret, Events: []v1.EventType{v1.EventPostDeleted}}
+	bus, deliveries, stop := startDispatcher(t, hook, 2)
+	defer stop()
+
+	bus.Publish(&v1.Event{ID: "1", Type: v1.EventPostCreated, Resource: "a"})
+	bus.Publish(&v1.Event{ID: "2", Type: v1.EventPostDeleted, Resource: "a"})
+	if d := nextDelivery(t, deliveries); d.Event != "2" {
+		t.Errorf("delivered event %q, want only %q", d.Event, "2")
+	}
+}